@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestPostOrderSerializesPerAccount(t *testing.T) {
+	const ordersPerAccount = 5
+	accounts := []string{"acc1", "acc2"}
+
+	var (
+		mu       sync.Mutex
+		inFlight = make(map[string]int)
+		overlap  = make(map[string]bool)
+		crossed  = make(chan struct{})
+		once     sync.Once
+	)
+
+	c := newTestClient(nil)
+	c.ordersClient = &fakeOrdersClient{
+		postOrder: func(_ context.Context, req *investapi.PostOrderRequest) (*investapi.PostOrderResponse, error) {
+			mu.Lock()
+			inFlight[req.AccountId]++
+			if inFlight[req.AccountId] > 1 {
+				overlap[req.AccountId] = true
+			}
+			if inFlight[accounts[0]] > 0 && inFlight[accounts[1]] > 0 {
+				once.Do(func() { close(crossed) })
+			}
+			mu.Unlock()
+
+			// Hold the call until both accounts have an order in flight, which
+			// only happens when different accounts run in parallel
+			select {
+			case <-crossed:
+			case <-time.After(time.Second):
+			}
+
+			mu.Lock()
+			inFlight[req.AccountId]--
+			mu.Unlock()
+			return &investapi.PostOrderResponse{OrderId: req.OrderId}, nil
+		},
+	}
+
+	var wg sync.WaitGroup
+	for _, account := range accounts {
+		for i := 0; i < ordersPerAccount; i++ {
+			wg.Add(1)
+			go func(account string) {
+				defer wg.Done()
+				if _, err := c.PostOrder(context.Background(), &investapi.PostOrderRequest{AccountId: account, Quantity: 1}); err != nil {
+					t.Errorf("PostOrder(%s): %v", account, err)
+				}
+			}(account)
+		}
+	}
+	wg.Wait()
+
+	for _, account := range accounts {
+		if overlap[account] {
+			t.Errorf("orders of %s overlapped", account)
+		}
+	}
+	select {
+	case <-crossed:
+	default:
+		t.Error("orders of different accounts never ran in parallel")
+	}
+}
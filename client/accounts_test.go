@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/buurzx/tinkoff-go/config"
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestGetAccountsCache(t *testing.T) {
+	tests := []struct {
+		name      string
+		ttl       time.Duration
+		age       time.Duration
+		wantCalls int
+	}{
+		{name: "cache disabled", ttl: 0, wantCalls: 2},
+		{name: "hit within TTL", ttl: time.Minute, age: 30 * time.Second, wantCalls: 1},
+		{name: "refetch after expiry", ttl: time.Minute, age: 2 * time.Minute, wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(&config.Config{AccountsCacheTTL: tt.ttl})
+			users := &fakeUsersClient{accounts: []*investapi.Account{{Id: "acc"}}}
+			c.usersClient = users
+			ctx := context.Background()
+
+			if _, err := c.GetAccounts(ctx); err != nil {
+				t.Fatalf("GetAccounts: %v", err)
+			}
+			c.accountsFetchedAt = c.accountsFetchedAt.Add(-tt.age)
+
+			accounts, err := c.GetAccounts(ctx)
+			if err != nil {
+				t.Fatalf("GetAccounts: %v", err)
+			}
+			if len(accounts) != 1 || accounts[0].Id != "acc" {
+				t.Fatalf("accounts = %v, want acc", accounts)
+			}
+			if users.accountCalls != tt.wantCalls {
+				t.Fatalf("GetAccounts calls = %d, want %d", users.accountCalls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRefreshAccountsBypassesCache(t *testing.T) {
+	c := newTestClient(&config.Config{AccountsCacheTTL: time.Minute})
+	users := &fakeUsersClient{accounts: []*investapi.Account{{Id: "old"}}}
+	c.usersClient = users
+	ctx := context.Background()
+
+	if _, err := c.GetAccounts(ctx); err != nil {
+		t.Fatalf("GetAccounts: %v", err)
+	}
+	users.accounts = []*investapi.Account{{Id: "new"}}
+
+	if _, err := c.RefreshAccounts(ctx); err != nil {
+		t.Fatalf("RefreshAccounts: %v", err)
+	}
+	accounts, err := c.GetAccounts(ctx)
+	if err != nil {
+		t.Fatalf("GetAccounts: %v", err)
+	}
+	if accounts[0].Id != "new" || users.accountCalls != 2 {
+		t.Fatalf("accounts = %v after %d calls, want the refreshed list after 2", accounts, users.accountCalls)
+	}
+}
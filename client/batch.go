@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// batchWorkers bounds the number of concurrent requests issued by batch helpers
+const batchWorkers = 4
+
+// TickerQuery identifies an instrument by ticker and class code
+type TickerQuery struct {
+	Ticker    string
+	ClassCode string
+}
+
+// Key returns the "ClassCode.Ticker" key used in batch lookup results
+func (q TickerQuery) Key() string {
+	return q.ClassCode + "." + q.Ticker
+}
+
+// fanOut calls fetch for every key, at most batchWorkers at a time, and
+// collects the results and errors by key. A failed key does not stop the
+// remaining ones.
+func fanOut[K comparable, V any](keys []K, fetch func(K) (V, error)) (map[K]V, map[K]error) {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[K]V, len(keys))
+		errs    = make(map[K]error)
+		queue   = make(chan K)
+	)
+
+	workers := batchWorkers
+	if len(keys) < workers {
+		workers = len(keys)
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range queue {
+				result, err := fetch(key)
+
+				mu.Lock()
+				if err != nil {
+					errs[key] = err
+				} else {
+					results[key] = result
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, key := range keys {
+		queue <- key
+	}
+	close(queue)
+	wg.Wait()
+
+	return results, errs
+}
+
+// GetInstrumentsByTickers looks up several instruments concurrently.
+// Results are keyed by "ClassCode.Ticker"; failed lookups are reported in the
+// returned error slice, in the order of tickers, and do not stop the remaining
+// ones. Lookups that have not started when ctx is cancelled fail with the
+// context error.
+func (c *RealClient) GetInstrumentsByTickers(ctx context.Context, tickers []TickerQuery) (map[string]*investapi.Instrument, []error) {
+	found, failed := fanOut(tickers, func(query TickerQuery) (*investapi.Instrument, error) {
+		return c.lookupTicker(ctx, query)
+	})
+
+	instruments := make(map[string]*investapi.Instrument, len(found))
+	for query, instrument := range found {
+		instruments[query.Key()] = instrument
+	}
+
+	var errs []error
+	for _, query := range tickers {
+		if err, ok := failed[query]; ok {
+			errs = append(errs, err)
+			delete(failed, query)
+		}
+	}
+
+	return instruments, errs
+}
+
+func (c *RealClient) lookupTicker(ctx context.Context, query TickerQuery) (*investapi.Instrument, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get instrument by ticker %s: %w", query.Key(), err)
+	}
+	return c.GetInstrumentByTicker(ctx, query.Ticker, query.ClassCode)
+}
+
+// GetOrderStates fetches the states of several orders of the account
+// concurrently, at most batchWorkers at a time. Results and errors are keyed
+// by order ID; a failed order does not stop the remaining ones. Orders that
+// have not started when ctx is cancelled fail with the context error.
+func (c *RealClient) GetOrderStates(ctx context.Context, accountID string, orderIDs []string) (map[string]*investapi.OrderState, map[string]error) {
+	return fanOut(orderIDs, func(orderID string) (*investapi.OrderState, error) {
+		return c.lookupOrderState(ctx, accountID, orderID)
+	})
+}
+
+func (c *RealClient) lookupOrderState(ctx context.Context, accountID, orderID string) (*investapi.OrderState, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get state of order %s: %w", orderID, err)
+	}
+	return c.GetOrderState(ctx, accountID, orderID)
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestGetInstrumentsByTickers(t *testing.T) {
+	c := newTestClient(nil)
+	instruments := &fakeInstrumentsClient{instruments: map[string]*investapi.Instrument{
+		"SBER": {Figi: "BBG004730N88", Ticker: "SBER", ClassCode: "TQBR"},
+		"GAZP": {Figi: "BBG004730RP0", Ticker: "GAZP", ClassCode: "TQBR"},
+	}}
+	c.instrumentsClient = instruments
+
+	queries := []TickerQuery{
+		{Ticker: "SBER", ClassCode: "TQBR"},
+		{Ticker: "GAZP", ClassCode: "TQBR"},
+		{Ticker: "NOPE", ClassCode: "TQBR"},
+	}
+	found, errs := c.GetInstrumentsByTickers(context.Background(), queries)
+
+	tests := []struct {
+		key      string
+		wantFigi string
+	}{
+		{key: "TQBR.SBER", wantFigi: "BBG004730N88"},
+		{key: "TQBR.GAZP", wantFigi: "BBG004730RP0"},
+		{key: "TQBR.NOPE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			instrument, ok := found[tt.key]
+			if tt.wantFigi == "" {
+				if ok {
+					t.Fatalf("found %v for an unknown ticker", instrument)
+				}
+				return
+			}
+			if !ok || instrument.Figi != tt.wantFigi {
+				t.Fatalf("instrument = %v, want FIGI %s", instrument, tt.wantFigi)
+			}
+		})
+	}
+
+	if len(errs) != 1 {
+		t.Fatalf("errors = %v, want one for NOPE", errs)
+	}
+	for _, req := range instruments.recorded() {
+		lookup := req.(*investapi.InstrumentRequest)
+		if lookup.IdType != investapi.InstrumentIdType_INSTRUMENT_ID_TYPE_TICKER || lookup.GetClassCode() != "TQBR" {
+			t.Fatalf("lookup = %v, want a TQBR ticker lookup", lookup)
+		}
+	}
+	if got := len(instruments.recorded()); got != len(queries) {
+		t.Fatalf("lookups = %d, want %d", got, len(queries))
+	}
+}
+
+func TestGetOrderStates(t *testing.T) {
+	orders := &fakeOrdersClient{activeOrders: []*investapi.OrderState{
+		{OrderId: "1", LotsRequested: 1},
+		{OrderId: "2", LotsRequested: 2},
+		{OrderId: "3", LotsRequested: 3},
+	}}
+
+	tests := []struct {
+		name       string
+		orderIDs   []string
+		cancel     bool
+		wantStates []string
+		wantErrs   []string
+	}{
+		{name: "three orders", orderIDs: []string{"1", "2", "3"}, wantStates: []string{"1", "2", "3"}},
+		{name: "unknown order", orderIDs: []string{"1", "4"}, wantStates: []string{"1"}, wantErrs: []string{"4"}},
+		{name: "cancelled context", orderIDs: []string{"1", "2", "3"}, cancel: true, wantErrs: []string{"1", "2", "3"}},
+		{name: "no orders"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			c.ordersClient = orders
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+
+			states, errs := c.GetOrderStates(ctx, "acc", tt.orderIDs)
+
+			if len(states) != len(tt.wantStates) || len(errs) != len(tt.wantErrs) {
+				t.Fatalf("states = %v, errors = %v, want %d states and %d errors",
+					states, errs, len(tt.wantStates), len(tt.wantErrs))
+			}
+			for _, id := range tt.wantStates {
+				if state := states[id]; state.GetOrderId() != id {
+					t.Fatalf("state of %s = %v", id, state)
+				}
+			}
+			for _, id := range tt.wantErrs {
+				if errs[id] == nil {
+					t.Fatalf("missing error for order %s", id)
+				}
+				if tt.cancel && !errors.Is(errs[id], context.Canceled) {
+					t.Fatalf("error for order %s = %v, want context.Canceled", id, errs[id])
+				}
+			}
+		})
+	}
+}
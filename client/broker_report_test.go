@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestBrokerReportRequests(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		call   func(c *RealClient) error
+		verify func(t *testing.T, req *investapi.BrokerReportRequest)
+	}{
+		{
+			name: "generate",
+			call: func(c *RealClient) error {
+				taskID, err := c.GenerateBrokerReport(context.Background(), "acc", from, to)
+				if err == nil && taskID != "task" {
+					t.Errorf("task ID = %q, want task", taskID)
+				}
+				return err
+			},
+			verify: func(t *testing.T, req *investapi.BrokerReportRequest) {
+				generate := req.GetGenerateBrokerReportRequest()
+				if generate == nil {
+					t.Fatalf("payload = %T, want a generate request", req.Payload)
+				}
+				if generate.AccountId != "acc" || !generate.From.AsTime().Equal(from) || !generate.To.AsTime().Equal(to) {
+					t.Fatalf("generate request = %v, want acc from %s to %s", generate, from, to)
+				}
+			},
+		},
+		{
+			name: "fetch",
+			call: func(c *RealClient) error {
+				_, err := c.GetBrokerReport(context.Background(), "task", 2)
+				return err
+			},
+			verify: func(t *testing.T, req *investapi.BrokerReportRequest) {
+				get := req.GetGetBrokerReportRequest()
+				if get == nil {
+					t.Fatalf("payload = %T, want a get request", req.Payload)
+				}
+				if get.TaskId != "task" || get.GetPage() != 2 {
+					t.Fatalf("get request = %v, want page 2 of task", get)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			operations := &fakeOperationsClient{
+				brokerReport: &investapi.BrokerReportResponse{
+					Payload: &investapi.BrokerReportResponse_GenerateBrokerReportResponse{
+						GenerateBrokerReportResponse: &investapi.GenerateBrokerReportResponse{TaskId: "task"},
+					},
+				},
+			}
+			c.operationsClient = operations
+
+			if err := tt.call(c); err != nil {
+				t.Fatalf("call failed: %v", err)
+			}
+
+			requests := operations.recorded()
+			if len(requests) != 1 {
+				t.Fatalf("requests = %d, want 1", len(requests))
+			}
+			tt.verify(t, requests[0].(*investapi.BrokerReportRequest))
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/buurzx/tinkoff-go/config"
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestCallRecorderRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+
+	recorded := &usersServer{accounts: func(context.Context) (*investapi.GetAccountsResponse, error) {
+		return &investapi.GetAccountsResponse{Accounts: []*investapi.Account{{Id: "recorded", Name: "IIS"}}}, nil
+	}}
+	c := newServerClient(t, &config.Config{RecordMode: config.RecordCalls, RecordDir: dir}, func(srv *grpc.Server) {
+		investapi.RegisterUsersServiceServer(srv, recorded)
+	})
+	if _, err := c.GetAccounts(context.Background()); err != nil {
+		t.Fatalf("GetAccounts while recording: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		dir     string
+		wantID  string
+		wantErr bool
+	}{
+		{name: "replays the recorded response", dir: dir, wantID: "recorded"},
+		{name: "missing recording", dir: t.TempDir(), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			live := &usersServer{accounts: func(context.Context) (*investapi.GetAccountsResponse, error) {
+				return nil, status.Error(codes.Unavailable, "server must not be called")
+			}}
+			c := newServerClient(t, &config.Config{RecordMode: config.ReplayCalls, RecordDir: tt.dir}, func(srv *grpc.Server) {
+				investapi.RegisterUsersServiceServer(srv, live)
+			})
+
+			accounts, err := c.GetAccounts(context.Background())
+			if live.callCount() != 0 {
+				t.Fatalf("server calls = %d, want 0 when replaying", live.callCount())
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("GetAccounts succeeded without a recording")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetAccounts: %v", err)
+			}
+			if len(accounts) != 1 || accounts[0].Id != tt.wantID || accounts[0].Name != "IIS" {
+				t.Fatalf("accounts = %v, want the recorded account", accounts)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// candleIntervals maps candle durations to API intervals.
+// Months have no fixed length and are only available as CANDLE_INTERVAL_MONTH.
+var candleIntervals = map[time.Duration]investapi.CandleInterval{
+	5 * time.Second:    investapi.CandleInterval_CANDLE_INTERVAL_5_SEC,
+	10 * time.Second:   investapi.CandleInterval_CANDLE_INTERVAL_10_SEC,
+	30 * time.Second:   investapi.CandleInterval_CANDLE_INTERVAL_30_SEC,
+	time.Minute:        investapi.CandleInterval_CANDLE_INTERVAL_1_MIN,
+	2 * time.Minute:    investapi.CandleInterval_CANDLE_INTERVAL_2_MIN,
+	3 * time.Minute:    investapi.CandleInterval_CANDLE_INTERVAL_3_MIN,
+	5 * time.Minute:    investapi.CandleInterval_CANDLE_INTERVAL_5_MIN,
+	10 * time.Minute:   investapi.CandleInterval_CANDLE_INTERVAL_10_MIN,
+	15 * time.Minute:   investapi.CandleInterval_CANDLE_INTERVAL_15_MIN,
+	30 * time.Minute:   investapi.CandleInterval_CANDLE_INTERVAL_30_MIN,
+	time.Hour:          investapi.CandleInterval_CANDLE_INTERVAL_HOUR,
+	2 * time.Hour:      investapi.CandleInterval_CANDLE_INTERVAL_2_HOUR,
+	4 * time.Hour:      investapi.CandleInterval_CANDLE_INTERVAL_4_HOUR,
+	24 * time.Hour:     investapi.CandleInterval_CANDLE_INTERVAL_DAY,
+	7 * 24 * time.Hour: investapi.CandleInterval_CANDLE_INTERVAL_WEEK,
+}
+
+// CandleIntervalFromDuration returns the candle interval of the given length,
+// e.g. 5*time.Minute for CANDLE_INTERVAL_5_MIN
+func CandleIntervalFromDuration(d time.Duration) (investapi.CandleInterval, error) {
+	interval, ok := candleIntervals[d]
+	if !ok {
+		return investapi.CandleInterval_CANDLE_INTERVAL_UNSPECIFIED, fmt.Errorf("unsupported candle interval %s", d)
+	}
+	return interval, nil
+}
+
+// validateCandleInterval rejects unspecified and unknown intervals before they reach the API
+func validateCandleInterval(interval investapi.CandleInterval) error {
+	if interval == investapi.CandleInterval_CANDLE_INTERVAL_UNSPECIFIED {
+		return fmt.Errorf("candle interval is required")
+	}
+	if _, ok := investapi.CandleInterval_name[int32(interval)]; !ok {
+		return fmt.Errorf("unknown candle interval %d", interval)
+	}
+	return nil
+}
+
+// candleMaxRanges is the longest period GetCandles accepts per interval
+var candleMaxRanges = map[investapi.CandleInterval]time.Duration{
+	investapi.CandleInterval_CANDLE_INTERVAL_5_SEC:  200 * time.Minute,
+	investapi.CandleInterval_CANDLE_INTERVAL_10_SEC: 200 * time.Minute,
+	investapi.CandleInterval_CANDLE_INTERVAL_30_SEC: 20 * time.Hour,
+	investapi.CandleInterval_CANDLE_INTERVAL_1_MIN:  24 * time.Hour,
+	investapi.CandleInterval_CANDLE_INTERVAL_2_MIN:  24 * time.Hour,
+	investapi.CandleInterval_CANDLE_INTERVAL_3_MIN:  24 * time.Hour,
+	investapi.CandleInterval_CANDLE_INTERVAL_5_MIN:  24 * time.Hour,
+	investapi.CandleInterval_CANDLE_INTERVAL_10_MIN: 24 * time.Hour,
+	investapi.CandleInterval_CANDLE_INTERVAL_15_MIN: 24 * time.Hour,
+	investapi.CandleInterval_CANDLE_INTERVAL_30_MIN: 2 * 24 * time.Hour,
+	investapi.CandleInterval_CANDLE_INTERVAL_HOUR:   7 * 24 * time.Hour,
+	investapi.CandleInterval_CANDLE_INTERVAL_2_HOUR: 30 * 24 * time.Hour,
+	investapi.CandleInterval_CANDLE_INTERVAL_4_HOUR: 30 * 24 * time.Hour,
+	investapi.CandleInterval_CANDLE_INTERVAL_DAY:    365 * 24 * time.Hour,
+	investapi.CandleInterval_CANDLE_INTERVAL_WEEK:   2 * 365 * 24 * time.Hour,
+	investapi.CandleInterval_CANDLE_INTERVAL_MONTH:  10 * 365 * 24 * time.Hour,
+}
+
+// GetCandlesAll returns the candles between from and to, splitting the range
+// into requests GetCandles accepts. Candles are sorted by time without
+// duplicates. When ctx is cancelled or a request fails, the candles of the
+// windows fetched so far are returned with the error; they cover the range
+// from from without gaps up to the failed window.
+func (c *RealClient) GetCandlesAll(ctx context.Context, figi string, from, to time.Time, interval investapi.CandleInterval) ([]*investapi.HistoricCandle, error) {
+	if err := validateCandleInterval(interval); err != nil {
+		return nil, fmt.Errorf("failed to get candles for %s: %w", figi, err)
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("invalid range: %s is before %s", to.Format(time.RFC3339), from.Format(time.RFC3339))
+	}
+
+	maxRange, ok := candleMaxRanges[interval]
+	if !ok {
+		return nil, fmt.Errorf("failed to get candles for %s: no request range known for %s", figi, interval)
+	}
+
+	var candles []*investapi.HistoricCandle
+	for start := from; start.Before(to); start = start.Add(maxRange) {
+		if err := ctx.Err(); err != nil {
+			return candles, err
+		}
+
+		end := start.Add(maxRange)
+		if end.After(to) {
+			end = to
+		}
+
+		resp, err := c.GetCandles(ctx, figi, start, end, interval)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return candles, ctxErr
+			}
+			return candles, err
+		}
+
+		for _, candle := range resp.Candles {
+			if n := len(candles); n > 0 && !candle.GetTime().AsTime().After(candles[n-1].GetTime().AsTime()) {
+				continue
+			}
+			candles = append(candles, candle)
+		}
+	}
+
+	return candles, nil
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestCandleIntervalFromDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration time.Duration
+		want     investapi.CandleInterval
+		wantErr  bool
+	}{
+		{name: "5 seconds", duration: 5 * time.Second, want: investapi.CandleInterval_CANDLE_INTERVAL_5_SEC},
+		{name: "1 minute", duration: time.Minute, want: investapi.CandleInterval_CANDLE_INTERVAL_1_MIN},
+		{name: "15 minutes", duration: 15 * time.Minute, want: investapi.CandleInterval_CANDLE_INTERVAL_15_MIN},
+		{name: "4 hours", duration: 4 * time.Hour, want: investapi.CandleInterval_CANDLE_INTERVAL_4_HOUR},
+		{name: "day", duration: 24 * time.Hour, want: investapi.CandleInterval_CANDLE_INTERVAL_DAY},
+		{name: "week", duration: 7 * 24 * time.Hour, want: investapi.CandleInterval_CANDLE_INTERVAL_WEEK},
+		{name: "7 minutes", duration: 7 * time.Minute, wantErr: true},
+		{name: "zero", duration: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CandleIntervalFromDuration(tt.duration)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("CandleIntervalFromDuration(%s) = %v, want %v", tt.duration, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetCandlesAll(t *testing.T) {
+	from := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	to := from.Add(3 * 24 * time.Hour)
+	day := func(n int) time.Time { return from.Add(time.Duration(n) * 24 * time.Hour) }
+	errBoom := errors.New("boom")
+
+	tests := []struct {
+		name      string
+		cancelAt  int
+		failAt    int
+		wantTimes []time.Time
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "whole range", wantTimes: []time.Time{day(0), day(1), day(2), day(3)}, wantCalls: 3},
+		{name: "cancelled after the first window", cancelAt: 1, wantTimes: []time.Time{day(0), day(1)}, wantCalls: 1, wantErr: context.Canceled},
+		{name: "second window fails", failAt: 2, wantTimes: []time.Time{day(0), day(1)}, wantCalls: 2, wantErr: errBoom},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			calls := 0
+			marketData := &fakeMarketDataClient{}
+			// Every window returns candles at its start and end, so adjacent
+			// windows overlap by one candle
+			marketData.candles = func(_ context.Context, req *investapi.GetCandlesRequest) (*investapi.GetCandlesResponse, error) {
+				calls++
+				if calls == tt.failAt {
+					return nil, errBoom
+				}
+				if calls == tt.cancelAt {
+					cancel()
+				}
+				return &investapi.GetCandlesResponse{Candles: []*investapi.HistoricCandle{
+					{Time: req.From, IsComplete: true},
+					{Time: req.To, IsComplete: true},
+				}}, nil
+			}
+			c := newTestClient(nil)
+			c.marketDataClient = marketData
+
+			got, err := c.GetCandlesAll(ctx, "BBG1", from, to, investapi.CandleInterval_CANDLE_INTERVAL_1_MIN)
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Fatalf("GetCandles calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if len(got) != len(tt.wantTimes) {
+				t.Fatalf("candles = %d, want %d", len(got), len(tt.wantTimes))
+			}
+			for i, want := range tt.wantTimes {
+				if !got[i].Time.AsTime().Equal(want) {
+					t.Fatalf("candle %d at %s, want %s", i, got[i].Time.AsTime(), want)
+				}
+			}
+		})
+	}
+}
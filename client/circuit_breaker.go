@@ -0,0 +1,171 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// defaultBreakerCooldown is used when the breaker is enabled without a cooldown
+const defaultBreakerCooldown = 30 * time.Second
+
+// BreakerState is the state of the order circuit breaker
+type BreakerState int
+
+const (
+	// BreakerClosed lets all orders through
+	BreakerClosed BreakerState = iota
+	// BreakerOpen rejects orders locally until the cooldown expires
+	BreakerOpen
+	// BreakerHalfOpen lets a single trial order through to test recovery
+	BreakerHalfOpen
+)
+
+// String returns a human readable breaker state
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// circuitBreaker stops order submission after repeated consecutive failures
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	trial    bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// allow returns ErrCircuitOpen when the call must be rejected locally
+func (b *circuitBreaker) allow() error {
+	if b.threshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = BreakerHalfOpen
+		b.trial = true
+		return nil
+	case BreakerHalfOpen:
+		if b.trial {
+			return ErrCircuitOpen
+		}
+		b.trial = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// breakerFailure reports whether err counts towards opening the breaker.
+// Every error returned by the API counts, including order rejections such as
+// InvalidArgument or FailedPrecondition with the API's business error codes
+// (e.g. insufficient margin, halted instrument): a bot that keeps sending
+// orders the API refuses is what the breaker stops. Only the caller's own
+// cancellations and expired deadlines are ignored, as they say nothing about
+// the orders.
+func breakerFailure(ctx context.Context, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	switch status.Code(err) {
+	case codes.Canceled:
+		return false
+	case codes.DeadlineExceeded:
+		return ctx.Err() == nil
+	default:
+		return true
+	}
+}
+
+// record updates the breaker with the result of an allowed call made with ctx
+func (b *circuitBreaker) record(ctx context.Context, err error) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.trial = false
+
+	if err == nil {
+		b.state = BreakerClosed
+		b.failures = 0
+		return
+	}
+
+	if !breakerFailure(ctx, err) {
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+func (b *circuitBreaker) currentState() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+func (b *circuitBreaker) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = BreakerClosed
+	b.failures = 0
+	b.trial = false
+}
+
+// OrderBreakerState returns the state of the PostOrder circuit breaker
+func (c *RealClient) OrderBreakerState() BreakerState {
+	return c.orderBreaker.currentState()
+}
+
+// ResetOrderBreaker closes the PostOrder circuit breaker and clears its failure count
+func (c *RealClient) ResetOrderBreaker() {
+	c.orderBreaker.reset()
+}
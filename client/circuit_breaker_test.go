@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/buurzx/tinkoff-go/config"
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestBreakerFailure(t *testing.T) {
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{"unavailable", context.Background(), status.Error(codes.Unavailable, "down"), true},
+		{"internal", context.Background(), status.Error(codes.Internal, "boom"), true},
+		{"rate limited", context.Background(), status.Error(codes.ResourceExhausted, "slow down"), true},
+		{"bad token", context.Background(), status.Error(codes.Unauthenticated, "token"), true},
+		{"server deadline", context.Background(), status.Error(codes.DeadlineExceeded, "slow"), true},
+		{"caller deadline", expired, status.Error(codes.DeadlineExceeded, "slow"), false},
+		{"caller cancel", context.Background(), status.Error(codes.Canceled, "canceled"), false},
+		{"wrapped context cancel", context.Background(), fmt.Errorf("post: %w", context.Canceled), false},
+		{"invalid order", context.Background(), status.Error(codes.InvalidArgument, "30001"), true},
+		{"insufficient margin", context.Background(), status.Error(codes.FailedPrecondition, "30042"), true},
+		{"halted instrument", context.Background(), status.Error(codes.FailedPrecondition, "30079"), true},
+		{"unknown instrument", context.Background(), status.Error(codes.NotFound, "50002"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := breakerFailure(tt.ctx, tt.err); got != tt.want {
+				t.Fatalf("breakerFailure(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerTripAndRecovery(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	unavailable := status.Error(codes.Unavailable, "down")
+
+	steps := []struct {
+		name      string
+		advance   time.Duration
+		result    error
+		wantAllow error
+		wantState BreakerState
+	}{
+		{name: "first failure", result: unavailable, wantState: BreakerClosed},
+		{name: "cancellation is ignored", result: status.Error(codes.Canceled, "canceled"), wantState: BreakerClosed},
+		{name: "order rejection trips", result: status.Error(codes.InvalidArgument, "30001"), wantState: BreakerOpen},
+		{name: "rejected while open", wantAllow: ErrCircuitOpen, wantState: BreakerOpen},
+		{name: "trial after cooldown fails", advance: time.Minute, result: unavailable, wantState: BreakerOpen},
+		{name: "trial after second cooldown succeeds", advance: time.Minute, wantState: BreakerClosed},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+
+		err := b.allow()
+		if !errors.Is(err, step.wantAllow) {
+			t.Fatalf("%s: allow() = %v, want %v", step.name, err, step.wantAllow)
+		}
+		if err == nil {
+			b.record(ctx, step.result)
+		}
+
+		if got := b.currentState(); got != step.wantState {
+			t.Fatalf("%s: state = %s, want %s", step.name, got, step.wantState)
+		}
+	}
+}
+
+func TestPostOrderBreaker(t *testing.T) {
+	c := newTestClient(&config.Config{OrderBreakerThreshold: 3, OrderBreakerCooldown: time.Minute})
+	orders := &fakeOrdersClient{
+		postOrder: func(context.Context, *investapi.PostOrderRequest) (*investapi.PostOrderResponse, error) {
+			return nil, status.Error(codes.Unavailable, "down")
+		},
+	}
+	c.ordersClient = orders
+
+	for i := 0; i < 3; i++ {
+		if _, err := c.PostOrder(context.Background(), &investapi.PostOrderRequest{AccountId: "acc"}); err == nil {
+			t.Fatalf("order %d: expected an error", i+1)
+		}
+	}
+
+	_, err := c.PostOrder(context.Background(), &investapi.PostOrderRequest{AccountId: "acc"})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+	if got := len(orders.posted()); got != 3 {
+		t.Fatalf("orders sent = %d, want 3", got)
+	}
+
+	c.ResetOrderBreaker()
+	if got := c.OrderBreakerState(); got != BreakerClosed {
+		t.Fatalf("state after reset = %s, want closed", got)
+	}
+}
+
+func TestPostOrderBreakerOnRejections(t *testing.T) {
+	const threshold = 3
+	c := newTestClient(&config.Config{OrderBreakerThreshold: threshold, OrderBreakerCooldown: time.Minute})
+	orders := &fakeOrdersClient{
+		postOrder: func(context.Context, *investapi.PostOrderRequest) (*investapi.PostOrderResponse, error) {
+			return nil, status.Error(codes.FailedPrecondition, "30079")
+		},
+	}
+	c.ordersClient = orders
+
+	tests := []struct {
+		name      string
+		wantState BreakerState
+		wantErr   error
+	}{
+		{name: "first rejection", wantState: BreakerClosed},
+		{name: "second rejection", wantState: BreakerClosed},
+		{name: "third rejection opens", wantState: BreakerOpen},
+		{name: "blocked", wantState: BreakerOpen, wantErr: ErrCircuitOpen},
+	}
+
+	for _, tt := range tests {
+		_, err := c.PostOrder(context.Background(), &investapi.PostOrderRequest{AccountId: "acc", InstrumentId: "BBG1", Quantity: 1})
+		if tt.wantErr != nil && !errors.Is(err, tt.wantErr) || tt.wantErr == nil && status.Code(err) != codes.FailedPrecondition {
+			t.Fatalf("%s: err = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if got := c.OrderBreakerState(); got != tt.wantState {
+			t.Fatalf("%s: state = %s, want %s", tt.name, got, tt.wantState)
+		}
+	}
+	if got := len(orders.posted()); got != threshold {
+		t.Fatalf("orders sent = %d, want %d", got, threshold)
+	}
+}
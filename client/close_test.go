@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func newClosableClient(t *testing.T) *RealClient {
+	t.Helper()
+
+	conn, err := grpc.NewClient("passthrough:///test", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	c := newTestClient(nil)
+	c.conn = conn
+	return c
+}
+
+func TestCloseIsIdempotent(t *testing.T) {
+	tests := []struct {
+		name       string
+		goroutines int
+	}{
+		{name: "twice in a row", goroutines: 1},
+		{name: "concurrently", goroutines: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newClosableClient(t)
+
+			var wg sync.WaitGroup
+			for i := 0; i < tt.goroutines; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for j := 0; j < 2; j++ {
+						if err := c.Close(); err != nil {
+							t.Errorf("Close: %v", err)
+						}
+					}
+				}()
+			}
+			wg.Wait()
+
+			if c.IsConnected() {
+				t.Fatal("client still connected after Close")
+			}
+			if c.ctx.Err() == nil {
+				t.Fatal("client context not cancelled by Close")
+			}
+		})
+	}
+}
+
+func TestMethodsAfterCloseReturnErrNotConnected(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		call func(c *RealClient) error
+	}{
+		{name: "GetAccounts", call: func(c *RealClient) error { _, err := c.GetAccounts(ctx); return err }},
+		{name: "GetInstrumentByFIGI", call: func(c *RealClient) error { _, err := c.GetInstrumentByFIGI(ctx, "BBG1"); return err }},
+		{name: "GetPortfolio", call: func(c *RealClient) error { _, err := c.GetPortfolio(ctx, "acc"); return err }},
+		{name: "GetOrders", call: func(c *RealClient) error { _, err := c.GetOrders(ctx, "acc"); return err }},
+		{name: "PostOrder", call: func(c *RealClient) error {
+			_, err := c.PostOrder(ctx, &investapi.PostOrderRequest{AccountId: "acc", InstrumentId: "BBG1", Quantity: 1})
+			return err
+		}},
+		{name: "NewMarketDataSession", call: func(c *RealClient) error { _, err := c.NewMarketDataSession(); return err }},
+		{name: "StartOrderStream", call: func(c *RealClient) error { _, err := c.StartOrderStream([]string{"acc"}); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newClosableClient(t)
+			if err := c.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			if err := tt.call(c); !errors.Is(err, ErrNotConnected) {
+				t.Fatalf("err = %v, want ErrNotConnected", err)
+			}
+		})
+	}
+}
+
+func TestCloseDuringOrderStateStream(t *testing.T) {
+	tests := []struct {
+		name  string
+		drain bool
+	}{
+		{name: "consumer stopped reading", drain: false},
+		{name: "consumer reading", drain: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newClosableClient(t)
+			c.config.StreamBufferSize = 1
+			stream := newFakeStream[investapi.OrderStateStreamRequest, investapi.OrderStateStreamResponse](c.ctx)
+			c.ordersStreamClient = &fakeOrdersStreamClient{orderStates: stream}
+
+			// Keep the stream busy until the client is closed
+			done := make(chan struct{})
+			defer close(done)
+			go func() {
+				for {
+					select {
+					case stream.recv <- &investapi.OrderStateStreamResponse{
+						Payload: &investapi.OrderStateStreamResponse_OrderState_{
+							OrderState: &investapi.OrderStateStreamResponse_OrderState{OrderId: "order"},
+						},
+					}:
+					case <-done:
+						return
+					}
+				}
+			}()
+
+			states, errs := c.OrderStateChannel(context.Background(), []string{"acc"})
+			if _, ok := <-states; !ok {
+				t.Fatal("order state channel closed before Close")
+			}
+
+			closed := make(chan error, 1)
+			go func() { closed <- c.Close() }()
+
+			if !tt.drain {
+				// The producer is blocked on the full channel when Close runs
+				select {
+				case err := <-closed:
+					if err != nil {
+						t.Fatalf("Close: %v", err)
+					}
+				case <-time.After(time.Second):
+					t.Fatal("Close did not return")
+				}
+			}
+
+			timeout := time.After(time.Second)
+			for states != nil {
+				select {
+				case _, ok := <-states:
+					if !ok {
+						states = nil
+					}
+				case <-timeout:
+					t.Fatal("order state channel was not closed after Close")
+				}
+			}
+			if tt.drain {
+				if err := <-closed; err != nil {
+					t.Fatalf("Close: %v", err)
+				}
+			}
+
+			if err, ok := <-errs; ok {
+				t.Fatalf("err = %v after Close, want the error channel closed", err)
+			}
+		})
+	}
+}
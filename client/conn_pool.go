@@ -0,0 +1,50 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+)
+
+// connPool spreads calls over several connections to the same endpoint.
+// Unary calls are distributed round-robin; a stream stays on the connection
+// it was opened on for its whole lifetime.
+type connPool struct {
+	conns []*grpc.ClientConn
+	next  atomic.Uint64
+}
+
+var _ grpc.ClientConnInterface = (*connPool)(nil)
+
+func newConnPool(conns []*grpc.ClientConn) *connPool {
+	return &connPool{conns: conns}
+}
+
+// pick returns the next connection in round-robin order
+func (p *connPool) pick() *grpc.ClientConn {
+	n := p.next.Add(1) - 1
+	return p.conns[n%uint64(len(p.conns))]
+}
+
+// Invoke performs a unary call on the next connection
+func (p *connPool) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	return p.pick().Invoke(ctx, method, args, reply, opts...)
+}
+
+// NewStream opens a stream on the next connection
+func (p *connPool) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return p.pick().NewStream(ctx, desc, method, opts...)
+}
+
+// Close closes all connections of the pool
+func (p *connPool) Close() error {
+	var errs []error
+	for _, conn := range p.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// newPoolConn serves users over an in-memory listener and dials it
+func newPoolConn(t *testing.T, users *usersServer) *grpc.ClientConn {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	investapi.RegisterUsersServiceServer(srv, users)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return conn
+}
+
+func TestConnPool(t *testing.T) {
+	tests := []struct {
+		name      string
+		size      int
+		calls     int
+		wantCalls []int
+	}{
+		{name: "single connection", size: 1, calls: 3, wantCalls: []int{3}},
+		{name: "even spread", size: 2, calls: 4, wantCalls: []int{2, 2}},
+		{name: "round robin remainder", size: 3, calls: 4, wantCalls: []int{2, 1, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			servers := make([]*usersServer, tt.size)
+			conns := make([]*grpc.ClientConn, tt.size)
+			for i := range servers {
+				servers[i] = &usersServer{}
+				conns[i] = newPoolConn(t, servers[i])
+			}
+			pool := newConnPool(conns)
+
+			users := investapi.NewUsersServiceClient(pool)
+			for i := 0; i < tt.calls; i++ {
+				if _, err := users.GetAccounts(context.Background(), &investapi.GetAccountsRequest{}); err != nil {
+					t.Fatalf("GetAccounts #%d: %v", i+1, err)
+				}
+			}
+			for i, server := range servers {
+				if got := server.callCount(); got != tt.wantCalls[i] {
+					t.Fatalf("connection %d calls = %d, want %d", i, got, tt.wantCalls[i])
+				}
+			}
+
+			if err := pool.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+			for i, conn := range conns {
+				if conn.GetState() != connectivity.Shutdown {
+					t.Fatalf("connection %d state = %s after Close, want SHUTDOWN", i, conn.GetState())
+				}
+			}
+		})
+	}
+}
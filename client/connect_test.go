@@ -0,0 +1,31 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/buurzx/tinkoff-go/config"
+)
+
+func TestBlockingConnectTimeout(t *testing.T) {
+	cfg, err := config.New("token", false)
+	if err != nil {
+		t.Fatalf("config.New: %v", err)
+	}
+	// A blackhole address that never answers
+	cfg.ServerURL = "10.255.255.1:443"
+	cfg.BlockingConnect = true
+	cfg.DialTimeout = 200 * time.Millisecond
+
+	start := time.Now()
+	c, err := NewRealWithConfig(cfg)
+	elapsed := time.Since(start)
+
+	if err == nil {
+		c.Close()
+		t.Fatal("NewRealWithConfig connected to an unroutable address")
+	}
+	if elapsed > 2*time.Second {
+		t.Fatalf("NewRealWithConfig returned after %s, want about %s", elapsed, cfg.DialTimeout)
+	}
+}
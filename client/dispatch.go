@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// defaultStreamBufferSize is used when config.StreamBufferSize is not set
+const defaultStreamBufferSize = 1024
+
+// StreamStats reports the state of the handler buffers used by the
+// Stream*Func helpers
+type StreamStats struct {
+	// Dropped is the total number of messages discarded because a handler
+	// could not keep up
+	Dropped uint64
+	// QueueDepth is the number of messages currently waiting for handlers
+	QueueDepth int64
+}
+
+// streamCounters are shared by all dispatchers of a client
+type streamCounters struct {
+	dropped atomic.Uint64
+	queued  atomic.Int64
+}
+
+// dispatcher runs a handler behind a bounded queue so a slow handler does
+// not block the stream receive loop. When the queue is full the oldest
+// message is dropped.
+type dispatcher[T any] struct {
+	queue    chan T
+	counters *streamCounters
+	stopped  atomic.Bool
+	done     chan struct{}
+}
+
+func newDispatcher[T any](size int, counters *streamCounters, handler func(T)) *dispatcher[T] {
+	if size <= 0 {
+		size = defaultStreamBufferSize
+	}
+
+	d := &dispatcher[T]{
+		queue:    make(chan T, size),
+		counters: counters,
+		done:     make(chan struct{}),
+	}
+
+	go func() {
+		defer close(d.done)
+		for msg := range d.queue {
+			d.counters.queued.Add(-1)
+			if d.stopped.Load() {
+				d.counters.dropped.Add(1)
+				continue
+			}
+			handler(msg)
+		}
+	}()
+
+	return d
+}
+
+// push enqueues msg, dropping the oldest queued message when the queue is full.
+// The depth is counted before the message is queued, so the handler side
+// never takes it below zero.
+func (d *dispatcher[T]) push(msg T) {
+	d.counters.queued.Add(1)
+	for {
+		select {
+		case d.queue <- msg:
+			return
+		default:
+		}
+
+		select {
+		case <-d.queue:
+			d.counters.queued.Add(-1)
+			d.counters.dropped.Add(1)
+		default:
+		}
+	}
+}
+
+// close stops accepting messages and waits until the queued ones are handled
+// or ctx is done. Once ctx is done the remaining messages are discarded and
+// counted as dropped, and close returns without waiting for a handler call
+// already in progress.
+func (d *dispatcher[T]) close(ctx context.Context) {
+	close(d.queue)
+
+	select {
+	case <-d.done:
+	case <-ctx.Done():
+		d.stopped.Store(true)
+	}
+}
+
+// StreamStats returns dropped message and queue depth counters of the
+// Stream*Func helpers
+func (c *RealClient) StreamStats() StreamStats {
+	return StreamStats{
+		Dropped:    c.streamCounters.dropped.Load(),
+		QueueDepth: c.streamCounters.queued.Load(),
+	}
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDispatcherSlowHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		size        int
+		pushed      int
+		wantHandled []int
+		wantDropped uint64
+	}{
+		{name: "fits in the queue", size: 4, pushed: 3, wantHandled: []int{0, 1, 2}},
+		{name: "oldest dropped", size: 2, pushed: 5, wantHandled: []int{0, 3, 4}, wantDropped: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var counters streamCounters
+			started := make(chan struct{})
+			release := make(chan struct{})
+			handled := make(chan int, tt.pushed)
+
+			d := newDispatcher(tt.size, &counters, func(msg int) {
+				if msg == 0 {
+					close(started)
+					<-release
+				}
+				handled <- msg
+			})
+
+			// Message 0 blocks the handler so the rest pile up in the queue
+			d.push(0)
+			<-started
+			for i := 1; i < tt.pushed; i++ {
+				d.push(i)
+				if depth := counters.queued.Load(); depth < 0 {
+					t.Fatalf("queue depth = %d after push %d", depth, i)
+				}
+			}
+			close(release)
+
+			for _, want := range tt.wantHandled {
+				if got := <-handled; got != want {
+					t.Fatalf("handled %d, want %d", got, want)
+				}
+			}
+			waitFor(t, "empty queue", func() bool { return counters.queued.Load() == 0 })
+			if got := counters.dropped.Load(); got != tt.wantDropped {
+				t.Fatalf("dropped = %d, want %d", got, tt.wantDropped)
+			}
+			d.close(context.Background())
+		})
+	}
+}
+
+func TestDispatcherCloseHandlesQueued(t *testing.T) {
+	var counters streamCounters
+	var handled []int
+
+	d := newDispatcher(4, &counters, func(msg int) { handled = append(handled, msg) })
+	for i := 0; i < 3; i++ {
+		d.push(i)
+	}
+	d.close(context.Background())
+
+	if len(handled) != 3 {
+		t.Fatalf("handled %v before close returned, want 3 messages", handled)
+	}
+	if got := counters.dropped.Load(); got != 0 {
+		t.Fatalf("dropped = %d, want 0", got)
+	}
+}
+
+func TestDispatcherCloseDoesNotWaitForHandler(t *testing.T) {
+	var counters streamCounters
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+
+	d := newDispatcher(4, &counters, func(msg int) {
+		if msg == 0 {
+			close(started)
+		}
+		<-release
+	})
+	for i := 0; i < 3; i++ {
+		d.push(i)
+	}
+	<-started
+
+	// close returns although the handler is still blocked on message 0
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	d.close(ctx)
+
+	if got := counters.queued.Load(); got != 2 {
+		t.Fatalf("queue depth = %d, want 2 queued behind the handler", got)
+	}
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"log"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// DryRunMessage is the Message of the synthetic responses returned by order
+// methods when config.DryRun is set
+const DryRunMessage = "would submit"
+
+// dryRunPostOrder logs req and returns the response PostOrder would return
+// without sending anything
+func dryRunPostOrder(req *investapi.PostOrderRequest) *investapi.PostOrderResponse {
+	log.Printf("🧪 Dry run: post order %s %s %d lots of %s (%s)",
+		req.OrderId, req.Direction, req.Quantity, req.InstrumentId, req.OrderType)
+
+	return &investapi.PostOrderResponse{
+		OrderId:        req.OrderId,
+		OrderRequestId: req.OrderId,
+		LotsRequested:  req.Quantity,
+		Direction:      req.Direction,
+		OrderType:      req.OrderType,
+		InstrumentUid:  req.InstrumentId,
+		Message:        DryRunMessage,
+	}
+}
+
+// dryRunReplaceOrder logs req and returns the response ReplaceOrder would return
+// without sending anything
+func dryRunReplaceOrder(req *investapi.ReplaceOrderRequest) *investapi.PostOrderResponse {
+	log.Printf("🧪 Dry run: replace order %s with %d lots (key %s)",
+		req.OrderId, req.Quantity, req.IdempotencyKey)
+
+	return &investapi.PostOrderResponse{
+		OrderId:        req.OrderId,
+		OrderRequestId: req.IdempotencyKey,
+		LotsRequested:  req.Quantity,
+		Message:        DryRunMessage,
+	}
+}
+
+// dryRunCancelOrder logs the cancellation and returns a synthetic response
+func dryRunCancelOrder(accountID, orderID string) *investapi.CancelOrderResponse {
+	log.Printf("🧪 Dry run: cancel order %s on account %s", orderID, accountID)
+
+	return &investapi.CancelOrderResponse{
+		Time: timestamppb.Now(),
+	}
+}
+
+// dryRunPostStopOrder logs req and returns the response PostStopOrder would
+// return without sending anything
+func dryRunPostStopOrder(req *investapi.PostStopOrderRequest) *investapi.PostStopOrderResponse {
+	log.Printf("🧪 Dry run: post stop order %s %s %d lots of %s (%s)",
+		req.OrderId, req.Direction, req.Quantity, req.InstrumentId, req.StopOrderType)
+
+	return &investapi.PostStopOrderResponse{
+		StopOrderId:    req.OrderId,
+		OrderRequestId: req.OrderId,
+	}
+}
+
+// dryRunCancelStopOrder logs the cancellation and returns a synthetic response
+func dryRunCancelStopOrder(accountID, stopOrderID string) *investapi.CancelStopOrderResponse {
+	log.Printf("🧪 Dry run: cancel stop order %s on account %s", stopOrderID, accountID)
+
+	return &investapi.CancelStopOrderResponse{
+		Time: timestamppb.Now(),
+	}
+}
@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/buurzx/tinkoff-go/config"
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestDryRunWithoutConnection(t *testing.T) {
+	ctx := context.Background()
+	price := 101.5
+
+	tests := []struct {
+		name string
+		call func(c *RealClient) error
+	}{
+		{
+			name: "PostOrder",
+			call: func(c *RealClient) error {
+				resp, err := c.PostOrder(ctx, &investapi.PostOrderRequest{AccountId: "acc", InstrumentId: "BBG1", Quantity: 1})
+				if err == nil && resp.Message != DryRunMessage {
+					t.Errorf("Message = %q, want %q", resp.Message, DryRunMessage)
+				}
+				return err
+			},
+		},
+		{
+			name: "CancelOrder",
+			call: func(c *RealClient) error {
+				_, err := c.CancelOrder(ctx, "acc", "order")
+				return err
+			},
+		},
+		{
+			name: "ReplaceOrder",
+			call: func(c *RealClient) error {
+				resp, err := c.ReplaceOrder(ctx, "acc", "order", "key", 2, &price)
+				if err == nil && resp.LotsRequested != 2 {
+					t.Errorf("LotsRequested = %d, want 2", resp.LotsRequested)
+				}
+				return err
+			},
+		},
+		{
+			name: "PostStopOrder",
+			call: func(c *RealClient) error {
+				_, err := c.PostStopOrder(ctx, &investapi.PostStopOrderRequest{AccountId: "acc", InstrumentId: "BBG1", Quantity: 1})
+				return err
+			},
+		},
+		{
+			name: "CancelStopOrder",
+			call: func(c *RealClient) error {
+				_, err := c.CancelStopOrder(ctx, "acc", "stop")
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(&config.Config{DryRun: true})
+			c.connected = false
+			orders := &fakeOrdersClient{}
+			c.ordersClient = orders
+
+			if err := tt.call(c); err != nil {
+				t.Fatalf("dry run failed offline: %v", err)
+			}
+			if got := len(orders.posted()); got != 0 {
+				t.Fatalf("dry run sent %d orders", got)
+			}
+		})
+	}
+}
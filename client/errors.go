@@ -0,0 +1,88 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotConnected is returned by client methods called before the client is
+// connected or after it is closed
+var ErrNotConnected = errors.New("tinkoff: client not connected")
+
+// ErrCircuitOpen is returned by PostOrder while the order circuit breaker is open
+var ErrCircuitOpen = errors.New("tinkoff: order circuit breaker is open")
+
+// ErrMarginConfirmationRequired is returned by PostOrder when the order may open
+// an uncovered position and ConfirmMarginTrade is not set. Retry the order with
+// the flag set to place it anyway.
+var ErrMarginConfirmationRequired = errors.New("tinkoff: order requires margin trade confirmation")
+
+// ErrNoPosition is returned by ClosePosition when the account holds no
+// position in the instrument
+var ErrNoPosition = errors.New("tinkoff: no open position")
+
+// ErrSubscriptionLimit is returned by MarketDataSession.Subscribe when the
+// session would exceed config.MaxStreamSubscriptions. Open another session
+// for the remaining instruments.
+var ErrSubscriptionLimit = errors.New("tinkoff: market data stream subscription limit reached")
+
+// TrackingError wraps an API error with the tracking ID returned by Tinkoff.
+// The tracking ID should be included when contacting Tinkoff support.
+type TrackingError struct {
+	TrackingID string
+	Err        error
+}
+
+// Error returns the wrapped error message with the tracking ID appended
+func (e *TrackingError) Error() string {
+	return fmt.Sprintf("%v (tracking id: %s)", e.Err, e.TrackingID)
+}
+
+// Unwrap returns the underlying error, so gRPC status helpers keep working
+func (e *TrackingError) Unwrap() error {
+	return e.Err
+}
+
+// SubscriptionError reports instruments the server refused to subscribe
+type SubscriptionError struct {
+	Type       SubscriptionType
+	TrackingID string
+	Failed     []InstrumentSubscriptionStatus
+}
+
+// Error lists the failed instruments with their statuses
+func (e *SubscriptionError) Error() string {
+	parts := make([]string, len(e.Failed))
+	for i, status := range e.Failed {
+		id := status.Figi
+		if id == "" {
+			id = status.InstrumentUID
+		}
+		parts[i] = fmt.Sprintf("%s: %s", id, status.Status)
+	}
+	return fmt.Sprintf("%s subscription failed for %d instruments: %s (tracking id: %s)",
+		e.Type, len(e.Failed), strings.Join(parts, ", "), e.TrackingID)
+}
+
+// EnvironmentMismatchError reports that the token does not seem to belong to
+// the environment selected by config.IsDemo. It is a setup warning rather than
+// a transport failure: sandbox and production tokens are not interchangeable.
+type EnvironmentMismatchError struct {
+	IsDemo bool
+	Err    error
+}
+
+// Error describes the expected environment and the underlying failure
+func (e *EnvironmentMismatchError) Error() string {
+	expected, other := "production", "sandbox"
+	if e.IsDemo {
+		expected, other = "sandbox", "production"
+	}
+	return fmt.Sprintf("token was rejected by the %s API, it may be a %s token: %v", expected, other, e.Err)
+}
+
+// Unwrap returns the underlying API error
+func (e *EnvironmentMismatchError) Unwrap() error {
+	return e.Err
+}
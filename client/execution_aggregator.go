@@ -0,0 +1,185 @@
+package client
+
+import (
+	"math/big"
+	"sync"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// Execution is the aggregate of all fills of one order
+type Execution struct {
+	OrderID       string
+	AccountID     string
+	Figi          string
+	InstrumentUID string
+	Direction     investapi.OrderDirection
+	// Quantity is the total filled quantity in instrument units
+	Quantity int64
+	// AveragePrice is the quantity-weighted average fill price
+	AveragePrice *investapi.Quotation
+	// Status is the terminal order status, or UNSPECIFIED when emitted by Flush
+	Status investapi.OrderExecutionReportStatus
+}
+
+// executionAcc accumulates fills of one order
+type executionAcc struct {
+	execution Execution
+	notional  *big.Int
+	tradeIDs  map[string]struct{}
+}
+
+// maxCompletedOrders is how many completed order IDs an aggregator remembers
+// to drop fills delivered after the terminal order state
+const maxCompletedOrders = 4096
+
+// ExecutionAggregator combines trade fills into one execution per order.
+// Feed it fills from the trades stream with AddTrades and order updates from the
+// order state stream with UpdateOrderState; the aggregate is emitted once the
+// order reaches a terminal state, or by Flush. Fills and updates arriving for
+// one of the last maxCompletedOrders completed orders are ignored, so a late
+// or redelivered trade does not emit a second execution. It is safe for
+// concurrent use.
+type ExecutionAggregator struct {
+	mu     sync.Mutex
+	orders map[string]*executionAcc
+	emit   func(Execution)
+
+	// completed holds recently completed order IDs, oldest first in completedOrder
+	completed      map[string]struct{}
+	completedOrder []string
+}
+
+// NewExecutionAggregator creates an aggregator calling emit for every completed order
+func NewExecutionAggregator(emit func(Execution)) *ExecutionAggregator {
+	return &ExecutionAggregator{
+		orders:    make(map[string]*executionAcc),
+		emit:      emit,
+		completed: make(map[string]struct{}),
+	}
+}
+
+// AddTrades records the fills of an order. Fills already seen are ignored,
+// so the same trade may arrive from both streams.
+func (a *ExecutionAggregator) AddTrades(trades *investapi.OrderTrades) {
+	if trades == nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.isCompleted(trades.OrderId) {
+		return
+	}
+
+	acc := a.order(trades.OrderId)
+	acc.execution.AccountID = trades.AccountId
+	acc.execution.Figi = trades.Figi
+	acc.execution.InstrumentUID = trades.InstrumentUid
+	acc.execution.Direction = trades.Direction
+	acc.add(trades.Trades)
+}
+
+// UpdateOrderState records fills carried by an order state update and emits the
+// aggregate when the order is filled, rejected or cancelled
+func (a *ExecutionAggregator) UpdateOrderState(state *investapi.OrderStateStreamResponse_OrderState) {
+	if state == nil {
+		return
+	}
+
+	a.mu.Lock()
+	if a.isCompleted(state.OrderId) {
+		a.mu.Unlock()
+		return
+	}
+
+	acc := a.order(state.OrderId)
+	acc.execution.AccountID = state.AccountId
+	acc.execution.InstrumentUID = state.InstrumentUid
+	acc.execution.Direction = state.Direction
+	acc.add(state.Trades)
+
+	if !ExecutionStatusFromProto(state.ExecutionReportStatus).IsTerminal() {
+		a.mu.Unlock()
+		return
+	}
+
+	delete(a.orders, state.OrderId)
+	a.markCompleted(state.OrderId)
+	acc.execution.Status = state.ExecutionReportStatus
+	a.mu.Unlock()
+
+	if acc.execution.Quantity > 0 {
+		a.emit(acc.finish())
+	}
+}
+
+// Flush emits the aggregates of all orders that have fills but no terminal state yet
+func (a *ExecutionAggregator) Flush() {
+	a.mu.Lock()
+	pending := a.orders
+	a.orders = make(map[string]*executionAcc)
+	a.mu.Unlock()
+
+	for _, acc := range pending {
+		if acc.execution.Quantity > 0 {
+			a.emit(acc.finish())
+		}
+	}
+}
+
+// isCompleted reports whether the order was recently emitted with a terminal
+// state. Callers must hold a.mu.
+func (a *ExecutionAggregator) isCompleted(orderID string) bool {
+	_, ok := a.completed[orderID]
+	return ok
+}
+
+// markCompleted remembers a completed order, forgetting the oldest one past
+// maxCompletedOrders. Callers must hold a.mu.
+func (a *ExecutionAggregator) markCompleted(orderID string) {
+	a.completed[orderID] = struct{}{}
+	a.completedOrder = append(a.completedOrder, orderID)
+
+	if len(a.completedOrder) > maxCompletedOrders {
+		delete(a.completed, a.completedOrder[0])
+		a.completedOrder = a.completedOrder[1:]
+	}
+}
+
+// order returns the accumulator of an order, creating it if needed. Callers must hold a.mu.
+func (a *ExecutionAggregator) order(orderID string) *executionAcc {
+	acc, ok := a.orders[orderID]
+	if !ok {
+		acc = &executionAcc{
+			execution: Execution{OrderID: orderID},
+			notional:  new(big.Int),
+			tradeIDs:  make(map[string]struct{}),
+		}
+		a.orders[orderID] = acc
+	}
+	return acc
+}
+
+func (acc *executionAcc) add(trades []*investapi.OrderTrade) {
+	for _, trade := range trades {
+		if trade.TradeId != "" {
+			if _, seen := acc.tradeIDs[trade.TradeId]; seen {
+				continue
+			}
+			acc.tradeIDs[trade.TradeId] = struct{}{}
+		}
+
+		acc.notional.Add(acc.notional, new(big.Int).Mul(big.NewInt(quotationToNanos(trade.Price)), big.NewInt(trade.Quantity)))
+		acc.execution.Quantity += trade.Quantity
+	}
+}
+
+func (acc *executionAcc) finish() Execution {
+	execution := acc.execution
+	if execution.Quantity > 0 {
+		execution.AveragePrice = nanosToQuotation(roundedQuo(acc.notional, big.NewInt(execution.Quantity)).Int64())
+	}
+	return execution
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"fmt"
+	"testing"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func fill(id string, price float64, qty int64) *investapi.OrderTrade {
+	return &investapi.OrderTrade{TradeId: id, Price: NewQuotationRounded(price), Quantity: qty}
+}
+
+func orderTrades(orderID string, trades ...*investapi.OrderTrade) *investapi.OrderTrades {
+	return &investapi.OrderTrades{OrderId: orderID, Figi: "BBG1", Trades: trades}
+}
+
+func orderState(orderID string, status investapi.OrderExecutionReportStatus) *investapi.OrderStateStreamResponse_OrderState {
+	return &investapi.OrderStateStreamResponse_OrderState{OrderId: orderID, ExecutionReportStatus: status}
+}
+
+func TestExecutionAggregator(t *testing.T) {
+	const (
+		filled  = investapi.OrderExecutionReportStatus_EXECUTION_REPORT_STATUS_FILL
+		partial = investapi.OrderExecutionReportStatus_EXECUTION_REPORT_STATUS_PARTIALLYFILL
+	)
+
+	type event struct {
+		trades *investapi.OrderTrades
+		state  *investapi.OrderStateStreamResponse_OrderState
+		flush  bool
+	}
+
+	tests := []struct {
+		name   string
+		events []event
+		want   []Execution
+	}{
+		{
+			name: "two partial fills averaged",
+			events: []event{
+				{trades: orderTrades("o1", fill("t1", 100, 10))},
+				{trades: orderTrades("o1", fill("t2", 101, 30))},
+				{state: orderState("o1", filled)},
+			},
+			want: []Execution{{OrderID: "o1", Quantity: 40, AveragePrice: NewQuotationRounded(100.75), Status: filled}},
+		},
+		{
+			name: "redelivered trade counted once",
+			events: []event{
+				{trades: orderTrades("o1", fill("t1", 100, 10))},
+				{trades: orderTrades("o1", fill("t1", 100, 10))},
+				{state: orderState("o1", filled)},
+			},
+			want: []Execution{{OrderID: "o1", Quantity: 10, AveragePrice: NewQuotationRounded(100), Status: filled}},
+		},
+		{
+			name: "late trade after terminal state dropped",
+			events: []event{
+				{trades: orderTrades("o1", fill("t1", 100, 10))},
+				{state: orderState("o1", filled)},
+				{trades: orderTrades("o1", fill("t1", 100, 10))},
+				{trades: orderTrades("o1", fill("t2", 100, 5))},
+				{state: orderState("o1", filled)},
+				{flush: true},
+			},
+			want: []Execution{{OrderID: "o1", Quantity: 10, AveragePrice: NewQuotationRounded(100), Status: filled}},
+		},
+		{
+			name: "flush emits open orders",
+			events: []event{
+				{trades: orderTrades("o1", fill("t1", 100, 10))},
+				{state: orderState("o1", partial)},
+				{flush: true},
+			},
+			want: []Execution{{OrderID: "o1", Quantity: 10, AveragePrice: NewQuotationRounded(100)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []Execution
+			a := NewExecutionAggregator(func(e Execution) { got = append(got, e) })
+
+			for _, ev := range tt.events {
+				switch {
+				case ev.trades != nil:
+					a.AddTrades(ev.trades)
+				case ev.state != nil:
+					a.UpdateOrderState(ev.state)
+				case ev.flush:
+					a.Flush()
+				}
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("executions = %+v, want %+v", got, tt.want)
+			}
+			for i, want := range tt.want {
+				if got[i].OrderID != want.OrderID || got[i].Quantity != want.Quantity || got[i].Status != want.Status ||
+					!QuotationEqual(got[i].AveragePrice, want.AveragePrice) {
+					t.Fatalf("execution %d = %+v, want %+v", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestExecutionAggregatorForgetsOldestCompleted(t *testing.T) {
+	var emitted int
+	a := NewExecutionAggregator(func(Execution) { emitted++ })
+
+	filled := investapi.OrderExecutionReportStatus_EXECUTION_REPORT_STATUS_FILL
+	a.AddTrades(orderTrades("first", fill("t0", 1, 1)))
+	a.UpdateOrderState(orderState("first", filled))
+	for i := 0; i < maxCompletedOrders; i++ {
+		a.UpdateOrderState(orderState(fmt.Sprintf("o%d", i), filled))
+	}
+
+	if got := len(a.completed); got != maxCompletedOrders {
+		t.Fatalf("remembered orders = %d, want %d", got, maxCompletedOrders)
+	}
+	if a.isCompleted("first") {
+		t.Fatal("oldest completed order should be forgotten")
+	}
+	if emitted != 1 {
+		t.Fatalf("emitted = %d, want 1", emitted)
+	}
+}
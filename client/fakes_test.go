@@ -0,0 +1,651 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/buurzx/tinkoff-go/config"
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// newTestClient returns a connected client without a network connection.
+// Tests set the service clients they need to fakes.
+func newTestClient(cfg *config.Config) *RealClient {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &RealClient{
+		config:       cfg,
+		metadata:     metadata.Pairs("authorization", "Bearer test"),
+		ctx:          ctx,
+		cancel:       cancel,
+		connected:    true,
+		orderBreaker: newCircuitBreaker(cfg.OrderBreakerThreshold, cfg.OrderBreakerCooldown),
+	}
+}
+
+// fakeStream is a client stream fed from a channel. Sent messages are
+// recorded; Recv returns io.EOF once the channel is closed, or the error set
+// with fail.
+type fakeStream[Req, Resp any] struct {
+	grpc.ClientStream
+
+	ctx  context.Context
+	recv chan *Resp
+
+	mu     sync.Mutex
+	sent   []*Req
+	closed bool
+	err    error
+}
+
+func newFakeStream[Req, Resp any](ctx context.Context) *fakeStream[Req, Resp] {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &fakeStream[Req, Resp]{ctx: ctx, recv: make(chan *Resp, 64)}
+}
+
+func (s *fakeStream[Req, Resp]) Send(req *Req) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return io.EOF
+	}
+	s.sent = append(s.sent, req)
+	return nil
+}
+
+func (s *fakeStream[Req, Resp]) Recv() (*Resp, error) {
+	select {
+	case resp, ok := <-s.recv:
+		if ok {
+			return resp, nil
+		}
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	return nil, io.EOF
+}
+
+func (s *fakeStream[Req, Resp]) CloseSend() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	return nil
+}
+
+func (s *fakeStream[Req, Resp]) Context() context.Context { return s.ctx }
+
+// fail ends the stream with err after the queued messages
+func (s *fakeStream[Req, Resp]) fail(err error) {
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+	close(s.recv)
+}
+
+func (s *fakeStream[Req, Resp]) requests() []*Req {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*Req(nil), s.sent...)
+}
+
+type fakeMarketDataStream = fakeStream[investapi.MarketDataRequest, investapi.MarketDataResponse]
+
+// fakeMarketDataStreamClient hands out a new fakeMarketDataStream per call.
+// The first openFailures calls fail with codes.Unavailable.
+type fakeMarketDataStreamClient struct {
+	investapi.MarketDataStreamServiceClient
+
+	mu           sync.Mutex
+	streams      []*fakeMarketDataStream
+	openFailures int
+	opens        int
+}
+
+func (f *fakeMarketDataStreamClient) MarketDataStream(ctx context.Context, _ ...grpc.CallOption) (investapi.MarketDataStreamService_MarketDataStreamClient, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.opens++
+	if f.opens <= f.openFailures {
+		return nil, status.Error(codes.Unavailable, "stream unavailable")
+	}
+
+	stream := newFakeMarketDataStream(ctx)
+	f.streams = append(f.streams, stream)
+	return stream, nil
+}
+
+func (f *fakeMarketDataStreamClient) stream(i int) *fakeMarketDataStream {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.streams[i]
+}
+
+func (f *fakeMarketDataStreamClient) openCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.opens
+}
+
+func newFakeMarketDataStream(ctx context.Context) *fakeMarketDataStream {
+	return newFakeStream[investapi.MarketDataRequest, investapi.MarketDataResponse](ctx)
+}
+
+// newTestSession returns a session on a fake stream of a test client
+func newTestSession(cfg *config.Config) (*MarketDataSession, *fakeMarketDataStreamClient) {
+	c := newTestClient(cfg)
+	streams := &fakeMarketDataStreamClient{}
+	c.marketDataStreamClient = streams
+
+	session, err := c.NewMarketDataSession()
+	if err != nil {
+		panic(err)
+	}
+	return session, streams
+}
+
+// subscribedInstruments counts the instruments in subscribe requests
+func subscribedInstruments(requests []*investapi.MarketDataRequest) int {
+	n := 0
+	for _, req := range requests {
+		switch {
+		case req.GetSubscribeCandlesRequest() != nil:
+			n += len(req.GetSubscribeCandlesRequest().Instruments)
+		case req.GetSubscribeOrderBookRequest() != nil:
+			n += len(req.GetSubscribeOrderBookRequest().Instruments)
+		case req.GetSubscribeTradesRequest() != nil:
+			n += len(req.GetSubscribeTradesRequest().Instruments)
+		case req.GetSubscribeLastPriceRequest() != nil:
+			n += len(req.GetSubscribeLastPriceRequest().Instruments)
+		}
+	}
+	return n
+}
+
+// fakeOrdersClient answers PostOrder with postOrder, which defaults to
+// accepting every order, ReplaceOrder by accepting the new order,
+// GetOrderPrice with orderPrice and GetOrders and GetOrderState with
+// activeOrders
+type fakeOrdersClient struct {
+	investapi.OrdersServiceClient
+
+	postOrder    func(ctx context.Context, req *investapi.PostOrderRequest) (*investapi.PostOrderResponse, error)
+	orderPrice   *investapi.GetOrderPriceResponse
+	activeOrders []*investapi.OrderState
+
+	requestLog
+
+	mu    sync.Mutex
+	posts []*investapi.PostOrderRequest
+}
+
+func (f *fakeOrdersClient) PostOrder(ctx context.Context, req *investapi.PostOrderRequest, _ ...grpc.CallOption) (*investapi.PostOrderResponse, error) {
+	f.mu.Lock()
+	f.posts = append(f.posts, req)
+	f.mu.Unlock()
+
+	if f.postOrder != nil {
+		return f.postOrder(ctx, req)
+	}
+	return &investapi.PostOrderResponse{OrderId: req.OrderId}, nil
+}
+
+func (f *fakeOrdersClient) ReplaceOrder(_ context.Context, req *investapi.ReplaceOrderRequest, _ ...grpc.CallOption) (*investapi.PostOrderResponse, error) {
+	f.record(req)
+	return &investapi.PostOrderResponse{OrderId: "order-" + req.IdempotencyKey, OrderRequestId: req.IdempotencyKey}, nil
+}
+
+func (f *fakeOrdersClient) GetOrderPrice(_ context.Context, req *investapi.GetOrderPriceRequest, _ ...grpc.CallOption) (*investapi.GetOrderPriceResponse, error) {
+	f.record(req)
+	return f.orderPrice, nil
+}
+
+func (f *fakeOrdersClient) GetOrders(_ context.Context, req *investapi.GetOrdersRequest, _ ...grpc.CallOption) (*investapi.GetOrdersResponse, error) {
+	f.record(req)
+	return &investapi.GetOrdersResponse{Orders: f.activeOrders}, nil
+}
+
+func (f *fakeOrdersClient) GetOrderState(_ context.Context, req *investapi.GetOrderStateRequest, _ ...grpc.CallOption) (*investapi.OrderState, error) {
+	f.record(req)
+	for _, order := range f.activeOrders {
+		if order.OrderId == req.OrderId {
+			return order, nil
+		}
+	}
+	return nil, status.Error(codes.NotFound, "order not found")
+}
+
+func (f *fakeOrdersClient) GetMaxLots(_ context.Context, req *investapi.GetMaxLotsRequest, _ ...grpc.CallOption) (*investapi.GetMaxLotsResponse, error) {
+	f.record(req)
+	return &investapi.GetMaxLotsResponse{Currency: "rub"}, nil
+}
+
+func (f *fakeOrdersClient) posted() []*investapi.PostOrderRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]*investapi.PostOrderRequest(nil), f.posts...)
+}
+
+// waitFor polls cond until it holds, failing the test after a second
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// fakeUsersClient answers GetInfo, GetAccounts and GetUserTariff with canned
+// responses and counts the GetInfo and GetAccounts calls
+type fakeUsersClient struct {
+	investapi.UsersServiceClient
+
+	info         *investapi.GetInfoResponse
+	tariff       *investapi.GetUserTariffResponse
+	accounts     []*investapi.Account
+	err          error
+	infoCalls    int
+	accountCalls int
+}
+
+func (f *fakeUsersClient) GetInfo(context.Context, *investapi.GetInfoRequest, ...grpc.CallOption) (*investapi.GetInfoResponse, error) {
+	f.infoCalls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.info != nil {
+		return f.info, nil
+	}
+	return &investapi.GetInfoResponse{}, nil
+}
+
+func (f *fakeUsersClient) GetAccounts(context.Context, *investapi.GetAccountsRequest, ...grpc.CallOption) (*investapi.GetAccountsResponse, error) {
+	f.accountCalls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &investapi.GetAccountsResponse{Accounts: f.accounts}, nil
+}
+
+func (f *fakeUsersClient) GetUserTariff(context.Context, *investapi.GetUserTariffRequest, ...grpc.CallOption) (*investapi.GetUserTariffResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.tariff, nil
+}
+
+// fakeSandboxClient answers GetSandboxAccounts with canned accounts
+type fakeSandboxClient struct {
+	investapi.SandboxServiceClient
+
+	accounts []*investapi.Account
+}
+
+func (f *fakeSandboxClient) GetSandboxAccounts(context.Context, *investapi.GetAccountsRequest, ...grpc.CallOption) (*investapi.GetAccountsResponse, error) {
+	return &investapi.GetAccountsResponse{Accounts: f.accounts}, nil
+}
+
+// fakeInstrumentsClient answers instrument calls from fixed data keyed by the
+// requested ID and records copies of the requests
+type fakeInstrumentsClient struct {
+	investapi.InstrumentsServiceClient
+
+	instruments   map[string]*investapi.Instrument
+	dividends     []*investapi.Dividend
+	coupons       []*investapi.Coupon
+	found         []*investapi.InstrumentShort
+	brands        []*investapi.Brand
+	futuresMargin *investapi.GetFuturesMarginResponse
+
+	requestLog
+}
+
+func (f *fakeInstrumentsClient) GetInstrumentBy(_ context.Context, req *investapi.InstrumentRequest, _ ...grpc.CallOption) (*investapi.InstrumentResponse, error) {
+	f.record(req)
+	instrument, ok := f.instruments[req.Id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "instrument %s not found", req.Id)
+	}
+	return &investapi.InstrumentResponse{Instrument: instrument}, nil
+}
+
+func (f *fakeInstrumentsClient) GetDividends(_ context.Context, req *investapi.GetDividendsRequest, _ ...grpc.CallOption) (*investapi.GetDividendsResponse, error) {
+	f.record(req)
+	return &investapi.GetDividendsResponse{Dividends: f.dividends}, nil
+}
+
+func (f *fakeInstrumentsClient) FindInstrument(_ context.Context, req *investapi.FindInstrumentRequest, _ ...grpc.CallOption) (*investapi.FindInstrumentResponse, error) {
+	f.record(req)
+	return &investapi.FindInstrumentResponse{Instruments: f.found}, nil
+}
+
+func (f *fakeInstrumentsClient) GetBrandBy(_ context.Context, req *investapi.GetBrandRequest, _ ...grpc.CallOption) (*investapi.Brand, error) {
+	f.record(req)
+	for _, brand := range f.brands {
+		if brand.Uid == req.Id {
+			return brand, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "brand %s not found", req.Id)
+}
+
+// TradingSchedules returns every day of the requested range, with weekends
+// marked as non-trading days
+func (f *fakeInstrumentsClient) TradingSchedules(_ context.Context, req *investapi.TradingSchedulesRequest, _ ...grpc.CallOption) (*investapi.TradingSchedulesResponse, error) {
+	f.record(req)
+
+	schedule := &investapi.TradingSchedule{Exchange: req.GetExchange()}
+	for day := moscowDate(req.From.AsTime()); !day.After(req.To.AsTime()); day = day.AddDate(0, 0, 1) {
+		weekend := day.Weekday() == time.Saturday || day.Weekday() == time.Sunday
+		schedule.Days = append(schedule.Days, &investapi.TradingDay{
+			Date:         timestamppb.New(day),
+			IsTradingDay: !weekend,
+		})
+	}
+	return &investapi.TradingSchedulesResponse{Exchanges: []*investapi.TradingSchedule{schedule}}, nil
+}
+
+func (f *fakeInstrumentsClient) GetBondCoupons(_ context.Context, req *investapi.GetBondCouponsRequest, _ ...grpc.CallOption) (*investapi.GetBondCouponsResponse, error) {
+	f.record(req)
+	return &investapi.GetBondCouponsResponse{Events: f.coupons}, nil
+}
+
+func (f *fakeInstrumentsClient) GetFuturesMargin(_ context.Context, req *investapi.GetFuturesMarginRequest, _ ...grpc.CallOption) (*investapi.GetFuturesMarginResponse, error) {
+	f.record(req)
+	return f.futuresMargin, nil
+}
+
+// fakeStopOrdersClient lists stopOrders as active, accepts every
+// PostStopOrder and fails CancelStopOrder for the IDs in cancelErrs
+type fakeStopOrdersClient struct {
+	investapi.StopOrdersServiceClient
+
+	stopOrders []*investapi.StopOrder
+	cancelErrs map[string]error
+
+	requestLog
+}
+
+func (f *fakeStopOrdersClient) GetStopOrders(_ context.Context, req *investapi.GetStopOrdersRequest, _ ...grpc.CallOption) (*investapi.GetStopOrdersResponse, error) {
+	f.record(req)
+	return &investapi.GetStopOrdersResponse{StopOrders: f.stopOrders}, nil
+}
+
+func (f *fakeStopOrdersClient) PostStopOrder(_ context.Context, req *investapi.PostStopOrderRequest, _ ...grpc.CallOption) (*investapi.PostStopOrderResponse, error) {
+	f.record(req)
+	return &investapi.PostStopOrderResponse{StopOrderId: "stop-" + req.OrderId, OrderRequestId: req.OrderId}, nil
+}
+
+func (f *fakeStopOrdersClient) CancelStopOrder(_ context.Context, req *investapi.CancelStopOrderRequest, _ ...grpc.CallOption) (*investapi.CancelStopOrderResponse, error) {
+	f.record(req)
+	if err := f.cancelErrs[req.StopOrderId]; err != nil {
+		return nil, err
+	}
+	return &investapi.CancelStopOrderResponse{}, nil
+}
+
+// requestLog records copies of the requests received by a fake
+type requestLog struct {
+	mu       sync.Mutex
+	requests []proto.Message
+}
+
+func (l *requestLog) record(req proto.Message) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.requests = append(l.requests, proto.Clone(req))
+}
+
+func (l *requestLog) recorded() []proto.Message {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]proto.Message(nil), l.requests...)
+}
+
+// fakeMarketDataClient answers market data calls from fixed data, GetCandles
+// through candles, and records copies of the requests
+type fakeMarketDataClient struct {
+	investapi.MarketDataServiceClient
+
+	lastPrices  map[string]*investapi.Quotation
+	closePrices *investapi.GetClosePricesResponse
+	candles     func(ctx context.Context, req *investapi.GetCandlesRequest) (*investapi.GetCandlesResponse, error)
+
+	requestLog
+}
+
+func (f *fakeMarketDataClient) GetClosePrices(_ context.Context, req *investapi.GetClosePricesRequest, _ ...grpc.CallOption) (*investapi.GetClosePricesResponse, error) {
+	f.record(req)
+	if f.closePrices != nil {
+		return f.closePrices, nil
+	}
+	return &investapi.GetClosePricesResponse{}, nil
+}
+
+func (f *fakeMarketDataClient) GetCandles(ctx context.Context, req *investapi.GetCandlesRequest, _ ...grpc.CallOption) (*investapi.GetCandlesResponse, error) {
+	f.record(req)
+	if f.candles != nil {
+		return f.candles(ctx, req)
+	}
+	return &investapi.GetCandlesResponse{}, nil
+}
+
+func (f *fakeMarketDataClient) GetLastPrices(_ context.Context, req *investapi.GetLastPricesRequest, _ ...grpc.CallOption) (*investapi.GetLastPricesResponse, error) {
+	f.record(req)
+	resp := &investapi.GetLastPricesResponse{}
+	for _, figi := range req.Figi {
+		if price, ok := f.lastPrices[figi]; ok {
+			resp.LastPrices = append(resp.LastPrices, &investapi.LastPrice{Figi: figi, Price: price})
+		}
+	}
+	return resp, nil
+}
+
+type fakeTradesStream = fakeStream[investapi.TradesStreamRequest, investapi.TradesStreamResponse]
+
+type fakeOrderStateStream = fakeStream[investapi.OrderStateStreamRequest, investapi.OrderStateStreamResponse]
+
+// fakeOrdersStreamClient returns the prepared streams and records the requests
+type fakeOrdersStreamClient struct {
+	investapi.OrdersStreamServiceClient
+
+	trades      *fakeTradesStream
+	orderStates *fakeOrderStateStream
+
+	mu                sync.Mutex
+	tradesRequest     *investapi.TradesStreamRequest
+	orderStateRequest *investapi.OrderStateStreamRequest
+}
+
+func (f *fakeOrdersStreamClient) TradesStream(_ context.Context, req *investapi.TradesStreamRequest, _ ...grpc.CallOption) (grpc.ServerStreamingClient[investapi.TradesStreamResponse], error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.tradesRequest = req
+	return f.trades, nil
+}
+
+func (f *fakeOrdersStreamClient) OrderStateStream(_ context.Context, req *investapi.OrderStateStreamRequest, _ ...grpc.CallOption) (grpc.ServerStreamingClient[investapi.OrderStateStreamResponse], error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.orderStateRequest = req
+	return f.orderStates, nil
+}
+
+type fakePortfolioStream = fakeStream[investapi.PortfolioStreamRequest, investapi.PortfolioStreamResponse]
+
+// fakeOperationsStreamClient hands out the portfolio streams in order and
+// records the requests
+type fakeOperationsStreamClient struct {
+	investapi.OperationsStreamServiceClient
+
+	requestLog
+
+	mu         sync.Mutex
+	portfolios []*fakePortfolioStream
+}
+
+func (f *fakeOperationsStreamClient) PortfolioStream(_ context.Context, req *investapi.PortfolioStreamRequest, _ ...grpc.CallOption) (grpc.ServerStreamingClient[investapi.PortfolioStreamResponse], error) {
+	f.record(req)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.portfolios) == 0 {
+		return nil, status.Error(codes.Unavailable, "no more streams")
+	}
+	stream := f.portfolios[0]
+	f.portfolios = f.portfolios[1:]
+	return stream, nil
+}
+
+// fakeOperationsClient answers operations calls from fixed data and records
+// copies of the requests
+type fakeOperationsClient struct {
+	investapi.OperationsServiceClient
+
+	portfolio *investapi.PortfolioResponse
+	positions *investapi.PositionsResponse
+	// pages are keyed by the request cursor, "" for the first page
+	pages        map[string]*investapi.GetOperationsByCursorResponse
+	brokerReport *investapi.BrokerReportResponse
+
+	requestLog
+}
+
+func (f *fakeOperationsClient) GetPortfolio(_ context.Context, req *investapi.PortfolioRequest, _ ...grpc.CallOption) (*investapi.PortfolioResponse, error) {
+	f.record(req)
+	return f.portfolio, nil
+}
+
+func (f *fakeOperationsClient) GetPositions(_ context.Context, req *investapi.PositionsRequest, _ ...grpc.CallOption) (*investapi.PositionsResponse, error) {
+	f.record(req)
+	return f.positions, nil
+}
+
+func (f *fakeOperationsClient) GetOperationsByCursor(_ context.Context, req *investapi.GetOperationsByCursorRequest, _ ...grpc.CallOption) (*investapi.GetOperationsByCursorResponse, error) {
+	f.record(req)
+	page, ok := f.pages[req.GetCursor()]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown cursor %q", req.GetCursor())
+	}
+	return page, nil
+}
+
+func (f *fakeOperationsClient) GetBrokerReport(_ context.Context, req *investapi.BrokerReportRequest, _ ...grpc.CallOption) (*investapi.BrokerReportResponse, error) {
+	f.record(req)
+	return f.brokerReport, nil
+}
+
+// newServerClient serves the services registered by register over an
+// in-memory listener and returns a client connected to it through the
+// client's own dial options and interceptors
+func newServerClient(t *testing.T, cfg *config.Config, register func(*grpc.Server)) *RealClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	register(srv)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	c := newTestClient(cfg)
+	dialer := grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	})
+	conn, err := grpc.NewClient("passthrough:///bufnet", append(c.dialOptions(insecure.NewCredentials()), dialer)...)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	c.conn = conn
+	c.usersClient = investapi.NewUsersServiceClient(conn)
+	c.instrumentsClient = investapi.NewInstrumentsServiceClient(conn)
+	c.marketDataClient = investapi.NewMarketDataServiceClient(conn)
+	c.ordersClient = investapi.NewOrdersServiceClient(conn)
+	c.operationsClient = investapi.NewOperationsServiceClient(conn)
+	c.stopOrdersClient = investapi.NewStopOrdersServiceClient(conn)
+	c.sandboxClient = investapi.NewSandboxServiceClient(conn)
+
+	return c
+}
+
+// usersServer answers GetAccounts through accounts and records the incoming metadata
+type usersServer struct {
+	investapi.UnimplementedUsersServiceServer
+
+	accounts func(ctx context.Context) (*investapi.GetAccountsResponse, error)
+
+	mu       sync.Mutex
+	calls    int
+	incoming []metadata.MD
+}
+
+func (s *usersServer) GetAccounts(ctx context.Context, _ *investapi.GetAccountsRequest) (*investapi.GetAccountsResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	s.mu.Lock()
+	s.calls++
+	s.incoming = append(s.incoming, md)
+	s.mu.Unlock()
+
+	if s.accounts != nil {
+		return s.accounts(ctx)
+	}
+	return &investapi.GetAccountsResponse{Accounts: []*investapi.Account{{Id: "acc"}}}, nil
+}
+
+func (s *usersServer) callCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls
+}
+
+func (s *usersServer) lastMetadata() metadata.MD {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.incoming) == 0 {
+		return nil
+	}
+	return s.incoming[len(s.incoming)-1]
+}
@@ -0,0 +1,10 @@
+package client
+
+import "github.com/google/uuid"
+
+// NewIdempotencyKey returns a fresh key for PostOrder, PostStopOrder and
+// ReplaceOrder. Generate it once per logical order and send the same key on
+// every retry of that order, so the API places it at most once.
+func NewIdempotencyKey() string {
+	return uuid.New().String()
+}
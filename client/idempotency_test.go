@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestNewIdempotencyKey(t *testing.T) {
+	first, second := NewIdempotencyKey(), NewIdempotencyKey()
+	if _, err := uuid.Parse(first); err != nil {
+		t.Fatalf("key %q is not a UUID: %v", first, err)
+	}
+	if first == second {
+		t.Fatalf("two keys are both %q", first)
+	}
+}
+
+func TestOrderMethodsFillIdempotencyKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		// place sends an order with key and returns the key the API received
+		place func(t *testing.T, c *RealClient, key string) string
+	}{
+		{name: "PostOrder", place: postOrderKey},
+		{name: "PostOrder with key", key: "my-key", place: postOrderKey},
+		{name: "PostStopOrder", place: postStopOrderKey},
+		{name: "PostStopOrder with key", key: "my-key", place: postStopOrderKey},
+		{name: "ReplaceOrder", place: replaceOrderKey},
+		{name: "ReplaceOrder with key", key: "my-key", place: replaceOrderKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			c.ordersClient = &fakeOrdersClient{}
+			c.stopOrdersClient = &fakeStopOrdersClient{}
+
+			got := tt.place(t, c, tt.key)
+			if tt.key != "" {
+				if got != tt.key {
+					t.Fatalf("key = %q, want the provided %q", got, tt.key)
+				}
+				return
+			}
+			if _, err := uuid.Parse(got); err != nil {
+				t.Fatalf("generated key %q is not a UUID: %v", got, err)
+			}
+		})
+	}
+}
+
+func postOrderKey(t *testing.T, c *RealClient, key string) string {
+	t.Helper()
+
+	req := &investapi.PostOrderRequest{AccountId: "acc", InstrumentId: "BBG1", Quantity: 1, OrderId: key}
+	if _, err := c.PostOrder(context.Background(), req); err != nil {
+		t.Fatalf("PostOrder: %v", err)
+	}
+	return c.ordersClient.(*fakeOrdersClient).posted()[0].OrderId
+}
+
+func postStopOrderKey(t *testing.T, c *RealClient, key string) string {
+	t.Helper()
+
+	req := &investapi.PostStopOrderRequest{AccountId: "acc", InstrumentId: "BBG1", Quantity: 1, OrderId: key}
+	if _, err := c.PostStopOrder(context.Background(), req); err != nil {
+		t.Fatalf("PostStopOrder: %v", err)
+	}
+	return c.stopOrdersClient.(*fakeStopOrdersClient).recorded()[0].(*investapi.PostStopOrderRequest).OrderId
+}
+
+func replaceOrderKey(t *testing.T, c *RealClient, key string) string {
+	t.Helper()
+
+	if _, err := c.ReplaceOrder(context.Background(), "acc", "order1", key, 1, nil); err != nil {
+		t.Fatalf("ReplaceOrder: %v", err)
+	}
+	return c.ordersClient.(*fakeOrdersClient).recorded()[0].(*investapi.ReplaceOrderRequest).IdempotencyKey
+}
@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// IncomeKind is the source of an expected payment
+type IncomeKind string
+
+const (
+	// IncomeDividend is a share dividend
+	IncomeDividend IncomeKind = "dividend"
+	// IncomeCoupon is a bond coupon
+	IncomeCoupon IncomeKind = "coupon"
+)
+
+// IncomeEvent is an expected dividend or coupon payment for a held position
+type IncomeEvent struct {
+	Figi string
+	Kind IncomeKind
+	// Date is the payment date for dividends and the coupon date for coupons
+	Date time.Time
+	// Quantity is the number of units held when the forecast was made
+	Quantity float64
+	// PerUnit is the payment per share or bond, Amount is PerUnit times Quantity
+	PerUnit *investapi.MoneyValue
+	Amount  *investapi.MoneyValue
+}
+
+// ExpectedIncome forecasts dividends and coupons of the account's current
+// positions that are due between now and until. Events are sorted by date.
+// The total is the sum of all events when they share one currency and nil when
+// they do not; use IncomeByCurrency for a per-currency breakdown.
+func (c *RealClient) ExpectedIncome(ctx context.Context, accountID string, until time.Time) (*investapi.MoneyValue, []IncomeEvent, error) {
+	portfolio, err := c.GetPortfolio(ctx, accountID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	var events []IncomeEvent
+
+	for _, position := range portfolio.Positions {
+		quantity := quotationToFloat(position.Quantity)
+		if quantity <= 0 {
+			continue
+		}
+
+		switch position.InstrumentType {
+		case "share":
+			resp, err := c.GetDividends(ctx, position.Figi, &now, &until)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, dividend := range resp.Dividends {
+				date := dividend.GetPaymentDate().AsTime()
+				if dividend.DividendNet == nil || date.Before(now) || date.After(until) {
+					continue
+				}
+				events = append(events, newIncomeEvent(position.Figi, IncomeDividend, date, quantity, dividend.DividendNet))
+			}
+
+		case "bond":
+			resp, err := c.GetBondCoupons(ctx, position.Figi, &now, &until)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, coupon := range resp.Events {
+				date := coupon.GetCouponDate().AsTime()
+				if coupon.PayOneBond == nil || date.Before(now) || date.After(until) {
+					continue
+				}
+				events = append(events, newIncomeEvent(position.Figi, IncomeCoupon, date, quantity, coupon.PayOneBond))
+			}
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Date.Before(events[j].Date)
+	})
+
+	totals := IncomeByCurrency(events)
+	switch len(totals) {
+	case 0:
+		return nanosToMoneyValue(0, NormalizeCurrency(portfolio.GetTotalAmountPortfolio().GetCurrency())), events, nil
+	case 1:
+		for _, total := range totals {
+			return total, events, nil
+		}
+	}
+
+	return nil, events, nil
+}
+
+// DividendYield returns the trailing dividend yield of a share in percent:
+// net dividends with a record date in the last year divided by the last
+// price. Shares without dividends in that period yield 0. Dividends must be
+// paid in the currency the share trades in; mixed currencies are an error
+// rather than a sum of unrelated amounts.
+func (c *RealClient) DividendYield(ctx context.Context, figi string) (float64, error) {
+	now := time.Now()
+	yearAgo := now.AddDate(-1, 0, 0)
+
+	dividends, err := c.GetDividends(ctx, figi, &yearAgo, &now)
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		total    int64
+		currency string
+	)
+	for _, dividend := range dividends.Dividends {
+		recordDate := dividend.GetRecordDate().AsTime()
+		if dividend.DividendNet == nil || recordDate.Before(yearAgo) || recordDate.After(now) {
+			continue
+		}
+
+		dividendCurrency := NormalizeCurrency(dividend.DividendNet.Currency)
+		if currency != "" && dividendCurrency != currency {
+			return 0, fmt.Errorf("dividends of %s are paid in both %s and %s", figi, currency, dividendCurrency)
+		}
+		currency = dividendCurrency
+		total += moneyValueToNanos(dividend.DividendNet)
+	}
+	if total == 0 {
+		return 0, nil
+	}
+
+	instrumentCurrency, err := c.instrumentCurrency(ctx, figi)
+	if err != nil {
+		return 0, err
+	}
+	if instrumentCurrency != currency {
+		return 0, fmt.Errorf("dividends of %s are paid in %s but it trades in %s", figi, currency, instrumentCurrency)
+	}
+
+	prices, err := c.GetLastPrices(ctx, []string{figi})
+	if err != nil {
+		return 0, err
+	}
+
+	var price int64
+	for _, lastPrice := range prices.LastPrices {
+		if lastPrice.Figi == figi {
+			price = quotationToNanos(lastPrice.Price)
+		}
+	}
+	if price <= 0 {
+		return 0, fmt.Errorf("no last price for %s", figi)
+	}
+
+	return float64(total) / float64(price) * 100, nil
+}
+
+// instrumentCurrency returns the normalized trading currency of the instrument,
+// fetching full instrument information when the cache has none
+func (c *RealClient) instrumentCurrency(ctx context.Context, figi string) (string, error) {
+	entry, err := c.ResolveInstrument(ctx, figi)
+	if err != nil {
+		return "", err
+	}
+	if entry.Currency != "" {
+		return entry.Currency, nil
+	}
+
+	instrument, err := c.GetInstrumentByFIGI(ctx, figi)
+	if err != nil {
+		return "", err
+	}
+	return NormalizeCurrency(instrument.Currency), nil
+}
+
+// IncomeByCurrency sums event amounts per normalized currency code
+func IncomeByCurrency(events []IncomeEvent) map[string]*investapi.MoneyValue {
+	amounts := make([]*investapi.MoneyValue, len(events))
+	for i, event := range events {
+		amounts[i] = event.Amount
+	}
+	return SumByCurrency(amounts)
+}
+
+func newIncomeEvent(figi string, kind IncomeKind, date time.Time, quantity float64, perUnit *investapi.MoneyValue) IncomeEvent {
+	amount := math.Round(float64(moneyValueToNanos(perUnit)) * quantity)
+	return IncomeEvent{
+		Figi:     figi,
+		Kind:     kind,
+		Date:     date,
+		Quantity: quantity,
+		PerUnit:  perUnit,
+		Amount:   nanosToMoneyValue(int64(amount), NormalizeCurrency(perUnit.Currency)),
+	}
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func dividend(units int64, currency string, recordDate, paymentDate time.Time) *investapi.Dividend {
+	return &investapi.Dividend{
+		DividendNet: &investapi.MoneyValue{Units: units, Currency: currency},
+		RecordDate:  timestamppb.New(recordDate),
+		PaymentDate: timestamppb.New(paymentDate),
+	}
+}
+
+func TestDividendYield(t *testing.T) {
+	now := time.Now()
+	recent := now.AddDate(0, -2, 0)
+	paidLater := now.AddDate(0, 0, 20)
+	old := now.AddDate(-1, -1, 0)
+
+	tests := []struct {
+		name      string
+		currency  string
+		dividends []*investapi.Dividend
+		want      float64
+		wantErr   bool
+	}{
+		{
+			name:      "no dividends",
+			currency:  "rub",
+			dividends: nil,
+			want:      0,
+		},
+		{
+			name:     "trailing dividends",
+			currency: "rub",
+			dividends: []*investapi.Dividend{
+				dividend(10, "rub", recent, recent),
+				dividend(5, "RUB", recent, recent),
+			},
+			want: 7.5,
+		},
+		{
+			name:     "selected by record date",
+			currency: "rub",
+			dividends: []*investapi.Dividend{
+				dividend(10, "rub", recent, paidLater),
+				dividend(20, "rub", old, recent),
+			},
+			want: 5,
+		},
+		{
+			name:     "mixed dividend currencies",
+			currency: "rub",
+			dividends: []*investapi.Dividend{
+				dividend(10, "rub", recent, recent),
+				dividend(1, "usd", recent, recent),
+			},
+			wantErr: true,
+		},
+		{
+			name:      "dividend currency differs from price",
+			currency:  "rub",
+			dividends: []*investapi.Dividend{dividend(1, "usd", recent, recent)},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			c.instrumentsClient = &fakeInstrumentsClient{
+				instruments: map[string]*investapi.Instrument{
+					"BBG1": {Figi: "BBG1", Currency: tt.currency},
+				},
+				dividends: tt.dividends,
+			}
+			c.marketDataClient = &fakeMarketDataClient{
+				lastPrices: map[string]*investapi.Quotation{"BBG1": {Units: 200}},
+			}
+
+			got, err := c.DividendYield(context.Background(), "BBG1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DividendYield error = %v, want error: %v", err, tt.wantErr)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("DividendYield = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpectedIncome(t *testing.T) {
+	now := time.Now()
+	until := now.AddDate(0, 6, 0)
+	coupon := func(units int64, currency string, date time.Time) *investapi.Coupon {
+		return &investapi.Coupon{
+			PayOneBond: &investapi.MoneyValue{Units: units, Currency: currency},
+			CouponDate: timestamppb.New(date),
+		}
+	}
+	positions := []*investapi.PortfolioPosition{
+		{Figi: "SHARE", InstrumentType: "share", Quantity: &investapi.Quotation{Units: 10}},
+		{Figi: "BOND", InstrumentType: "bond", Quantity: &investapi.Quotation{Units: 2}},
+	}
+
+	tests := []struct {
+		name       string
+		positions  []*investapi.PortfolioPosition
+		dividends  []*investapi.Dividend
+		coupons    []*investapi.Coupon
+		wantTotal  *investapi.MoneyValue
+		wantEvents []IncomeKind
+	}{
+		{
+			name:      "dividend and coupon in one currency",
+			positions: positions,
+			dividends: []*investapi.Dividend{
+				dividend(5, "rub", now, now.AddDate(0, 2, 0)),
+				dividend(7, "rub", now, until.AddDate(0, 1, 0)),
+			},
+			coupons:    []*investapi.Coupon{coupon(40, "RUB", now.AddDate(0, 1, 0))},
+			wantTotal:  &investapi.MoneyValue{Units: 130, Currency: "rub"},
+			wantEvents: []IncomeKind{IncomeCoupon, IncomeDividend},
+		},
+		{
+			name:       "mixed currencies have no total",
+			positions:  positions,
+			dividends:  []*investapi.Dividend{dividend(5, "rub", now, now.AddDate(0, 2, 0))},
+			coupons:    []*investapi.Coupon{coupon(1, "usd", now.AddDate(0, 1, 0))},
+			wantEvents: []IncomeKind{IncomeCoupon, IncomeDividend},
+		},
+		{
+			name:      "no positions",
+			wantTotal: &investapi.MoneyValue{Currency: "rub"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			c.operationsClient = &fakeOperationsClient{portfolio: &investapi.PortfolioResponse{
+				Positions:            tt.positions,
+				TotalAmountPortfolio: &investapi.MoneyValue{Currency: "RUB"},
+			}}
+			c.instrumentsClient = &fakeInstrumentsClient{dividends: tt.dividends, coupons: tt.coupons}
+
+			total, events, err := c.ExpectedIncome(context.Background(), "acc", until)
+			if err != nil {
+				t.Fatalf("ExpectedIncome: %v", err)
+			}
+			if !MoneyValueEqual(total, tt.wantTotal) {
+				t.Fatalf("total = %v, want %v", total, tt.wantTotal)
+			}
+			if len(events) != len(tt.wantEvents) {
+				t.Fatalf("events = %d, want %d", len(events), len(tt.wantEvents))
+			}
+			for i, event := range events {
+				if event.Kind != tt.wantEvents[i] {
+					t.Fatalf("event %d = %s, want %s sorted by date", i, event.Kind, tt.wantEvents[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// InstrumentCacheEntry is the short instrument metadata kept by the client's
+// instrument cache
+type InstrumentCacheEntry struct {
+	Figi           string
+	UID            string
+	Ticker         string
+	ClassCode      string
+	Name           string
+	InstrumentType string
+	Lot            int32
+	// Currency is empty when the entry came from FindInstrument
+	Currency string
+}
+
+// instrumentFetch is a GetInstrumentByFIGI call shared by concurrent
+// ResolveInstrument misses of the same FIGI
+type instrumentFetch struct {
+	done  chan struct{}
+	entry *InstrumentCacheEntry
+	err   error
+}
+
+// ResolveInstrument returns cached metadata of the instrument with the given
+// FIGI. The cache is filled by FindInstrument and GetInstrumentByFIGI; on a
+// miss the instrument is fetched once and cached for the client's lifetime.
+// Concurrent misses of the same FIGI wait for a single fetch.
+func (c *RealClient) ResolveInstrument(ctx context.Context, figi string) (*InstrumentCacheEntry, error) {
+	c.instrumentsMu.RLock()
+	entry, ok := c.instruments[figi]
+	c.instrumentsMu.RUnlock()
+	if ok {
+		return entry, nil
+	}
+
+	c.instrumentsMu.Lock()
+	if entry, ok := c.instruments[figi]; ok {
+		c.instrumentsMu.Unlock()
+		return entry, nil
+	}
+	if fetch, ok := c.instrumentFetches[figi]; ok {
+		c.instrumentsMu.Unlock()
+		select {
+		case <-fetch.done:
+			return fetch.entry, fetch.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	fetch := &instrumentFetch{done: make(chan struct{})}
+	if c.instrumentFetches == nil {
+		c.instrumentFetches = make(map[string]*instrumentFetch)
+	}
+	c.instrumentFetches[figi] = fetch
+	c.instrumentsMu.Unlock()
+
+	fetch.entry, fetch.err = c.fetchInstrumentEntry(ctx, figi)
+
+	c.instrumentsMu.Lock()
+	delete(c.instrumentFetches, figi)
+	c.instrumentsMu.Unlock()
+	close(fetch.done)
+
+	return fetch.entry, fetch.err
+}
+
+// fetchInstrumentEntry fetches the instrument, which caches it, and returns
+// the new cache entry
+func (c *RealClient) fetchInstrumentEntry(ctx context.Context, figi string) (*InstrumentCacheEntry, error) {
+	if _, err := c.GetInstrumentByFIGI(ctx, figi); err != nil {
+		return nil, err
+	}
+
+	c.instrumentsMu.RLock()
+	entry, ok := c.instruments[figi]
+	c.instrumentsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("instrument %s not found", figi)
+	}
+
+	return entry, nil
+}
+
+// cacheInstrument stores full instrument information in the cache
+func (c *RealClient) cacheInstrument(instrument *investapi.Instrument) {
+	if instrument == nil || instrument.Figi == "" {
+		return
+	}
+
+	entry := &InstrumentCacheEntry{
+		Figi:           instrument.Figi,
+		UID:            instrument.Uid,
+		Ticker:         instrument.Ticker,
+		ClassCode:      instrument.ClassCode,
+		Name:           instrument.Name,
+		InstrumentType: instrument.InstrumentType,
+		Lot:            instrument.Lot,
+		Currency:       NormalizeCurrency(instrument.Currency),
+	}
+
+	c.instrumentsMu.Lock()
+	defer c.instrumentsMu.Unlock()
+
+	if c.instruments == nil {
+		c.instruments = make(map[string]*InstrumentCacheEntry)
+	}
+	c.instruments[entry.Figi] = entry
+}
+
+// cacheInstrumentShorts stores search results in the cache without replacing
+// entries that already carry full instrument information
+func (c *RealClient) cacheInstrumentShorts(instruments []*investapi.InstrumentShort) {
+	entries := make([]*InstrumentCacheEntry, 0, len(instruments))
+	for _, instrument := range instruments {
+		if instrument.Figi == "" {
+			continue
+		}
+		entries = append(entries, &InstrumentCacheEntry{
+			Figi:           instrument.Figi,
+			UID:            instrument.Uid,
+			Ticker:         instrument.Ticker,
+			ClassCode:      instrument.ClassCode,
+			Name:           instrument.Name,
+			InstrumentType: instrument.InstrumentType,
+			Lot:            instrument.Lot,
+		})
+	}
+
+	c.instrumentsMu.Lock()
+	defer c.instrumentsMu.Unlock()
+
+	if c.instruments == nil {
+		c.instruments = make(map[string]*InstrumentCacheEntry)
+	}
+	for _, entry := range entries {
+		if existing, ok := c.instruments[entry.Figi]; ok && existing.Currency != "" {
+			continue
+		}
+		c.instruments[entry.Figi] = entry
+	}
+}
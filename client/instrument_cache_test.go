@@ -0,0 +1,143 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestResolveInstrumentCache(t *testing.T) {
+	full := &investapi.Instrument{Figi: "BBG1", Ticker: "SBER", Name: "Sberbank", Lot: 10, Currency: "RUB"}
+	short := &investapi.InstrumentShort{Figi: "BBG1", Ticker: "SBER", Name: "Sberbank (search)", Lot: 10}
+
+	tests := []struct {
+		name         string
+		warm         func(c *RealClient) error
+		wantName     string
+		wantCurrency string
+		wantLookups  int
+	}{
+		{
+			name:         "miss fetches once",
+			warm:         func(*RealClient) error { return nil },
+			wantName:     "Sberbank",
+			wantCurrency: "rub",
+			wantLookups:  1,
+		},
+		{
+			name: "hit after search",
+			warm: func(c *RealClient) error {
+				_, err := c.FindInstrument(context.Background(), "SBER", nil, false)
+				return err
+			},
+			wantName: "Sberbank (search)",
+		},
+		{
+			name: "search keeps the full entry",
+			warm: func(c *RealClient) error {
+				if _, err := c.GetInstrumentByFIGI(context.Background(), "BBG1"); err != nil {
+					return err
+				}
+				_, err := c.FindInstrument(context.Background(), "SBER", nil, false)
+				return err
+			},
+			wantName:     "Sberbank",
+			wantCurrency: "rub",
+			wantLookups:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			instruments := &fakeInstrumentsClient{
+				instruments: map[string]*investapi.Instrument{"BBG1": full},
+				found:       []*investapi.InstrumentShort{short},
+			}
+			c.instrumentsClient = instruments
+
+			if err := tt.warm(c); err != nil {
+				t.Fatalf("warm cache: %v", err)
+			}
+			for i := 0; i < 2; i++ {
+				entry, err := c.ResolveInstrument(context.Background(), "BBG1")
+				if err != nil {
+					t.Fatalf("ResolveInstrument: %v", err)
+				}
+				if entry.Name != tt.wantName || entry.Currency != tt.wantCurrency || entry.Lot != 10 {
+					t.Fatalf("entry = %+v, want name %q and currency %q", entry, tt.wantName, tt.wantCurrency)
+				}
+			}
+
+			lookups := 0
+			for _, req := range instruments.recorded() {
+				if _, ok := req.(*investapi.InstrumentRequest); ok {
+					lookups++
+				}
+			}
+			if lookups != tt.wantLookups {
+				t.Fatalf("instrument lookups = %d, want %d", lookups, tt.wantLookups)
+			}
+		})
+	}
+}
+
+// blockingInstrumentsClient holds GetInstrumentBy calls until release is closed
+type blockingInstrumentsClient struct {
+	*fakeInstrumentsClient
+	release chan struct{}
+}
+
+func (f *blockingInstrumentsClient) GetInstrumentBy(ctx context.Context, req *investapi.InstrumentRequest, opts ...grpc.CallOption) (*investapi.InstrumentResponse, error) {
+	<-f.release
+	return f.fakeInstrumentsClient.GetInstrumentBy(ctx, req, opts...)
+}
+
+func TestResolveInstrumentConcurrentMisses(t *testing.T) {
+	tests := []struct {
+		name        string
+		figi        string
+		wantLookups int
+		wantErr     bool
+	}{
+		{name: "found", figi: "BBG1", wantLookups: 1},
+		{name: "not found", figi: "BBG2", wantLookups: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			instruments := &fakeInstrumentsClient{instruments: map[string]*investapi.Instrument{
+				"BBG1": {Figi: "BBG1", Ticker: "SBER", Lot: 10, Currency: "rub"},
+			}}
+			blocking := &blockingInstrumentsClient{fakeInstrumentsClient: instruments, release: make(chan struct{})}
+			c.instrumentsClient = blocking
+
+			const callers = 8
+			errs := make(chan error, callers)
+			for i := 0; i < callers; i++ {
+				go func() {
+					_, err := c.ResolveInstrument(context.Background(), tt.figi)
+					errs <- err
+				}()
+			}
+
+			// Let every caller reach the fetch or wait for it before it returns
+			time.Sleep(50 * time.Millisecond)
+			close(blocking.release)
+
+			for i := 0; i < callers; i++ {
+				if err := <-errs; (err != nil) != tt.wantErr {
+					t.Fatalf("ResolveInstrument error = %v, want error: %v", err, tt.wantErr)
+				}
+			}
+			if got := len(instruments.recorded()); got != tt.wantLookups {
+				t.Fatalf("instrument lookups = %d, want %d", got, tt.wantLookups)
+			}
+		})
+	}
+}
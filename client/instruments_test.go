@@ -0,0 +1,179 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestGetInstrumentByIDType(t *testing.T) {
+	instrument := &investapi.Instrument{Figi: "BBG004730N88", Uid: "e6123145-9665-43e0-8413-cd61b8aa9b13", Ticker: "SBER"}
+
+	tests := []struct {
+		name       string
+		get        func(c *RealClient) (*investapi.Instrument, error)
+		wantID     string
+		wantIDType investapi.InstrumentIdType
+	}{
+		{
+			name: "by FIGI",
+			get: func(c *RealClient) (*investapi.Instrument, error) {
+				return c.GetInstrumentByFIGI(context.Background(), instrument.Figi)
+			},
+			wantID:     instrument.Figi,
+			wantIDType: investapi.InstrumentIdType_INSTRUMENT_ID_TYPE_FIGI,
+		},
+		{
+			name: "by UID",
+			get: func(c *RealClient) (*investapi.Instrument, error) {
+				return c.GetInstrumentByUID(context.Background(), instrument.Uid)
+			},
+			wantID:     instrument.Uid,
+			wantIDType: investapi.InstrumentIdType_INSTRUMENT_ID_TYPE_UID,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			instruments := &fakeInstrumentsClient{instruments: map[string]*investapi.Instrument{
+				instrument.Figi: instrument,
+				instrument.Uid:  instrument,
+			}}
+			c.instrumentsClient = instruments
+
+			got, err := tt.get(c)
+			if err != nil {
+				t.Fatalf("get instrument: %v", err)
+			}
+			if got.Ticker != "SBER" {
+				t.Fatalf("ticker = %q, want SBER", got.Ticker)
+			}
+
+			req := instruments.recorded()[0].(*investapi.InstrumentRequest)
+			if req.Id != tt.wantID || req.IdType != tt.wantIDType {
+				t.Fatalf("request = %s %v, want %s %v", req.Id, req.IdType, tt.wantID, tt.wantIDType)
+			}
+		})
+	}
+}
+
+func TestGetFuturesMargin(t *testing.T) {
+	tests := []struct {
+		name         string
+		instrumentID string
+	}{
+		{name: "FIGI", instrumentID: "FUTSI0624000"},
+		{name: "UID", instrumentID: "5bcff194-f10d-4314-b9ee-56b7fdb344fd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			instruments := &fakeInstrumentsClient{futuresMargin: &investapi.GetFuturesMarginResponse{
+				InitialMarginOnBuy: &investapi.MoneyValue{Currency: "rub", Units: 9000},
+				MinPriceIncrement:  &investapi.Quotation{Units: 1},
+			}}
+			c.instrumentsClient = instruments
+
+			resp, err := c.GetFuturesMargin(context.Background(), tt.instrumentID)
+			if err != nil {
+				t.Fatalf("GetFuturesMargin: %v", err)
+			}
+			if resp.GetInitialMarginOnBuy().GetUnits() != 9000 {
+				t.Fatalf("initial margin = %v, want 9000 rub", resp.GetInitialMarginOnBuy())
+			}
+
+			req := instruments.recorded()[0].(*investapi.GetFuturesMarginRequest)
+			if req.InstrumentId != tt.instrumentID || req.Figi != "" {
+				t.Fatalf("request = %v, want instrument ID %s and no FIGI", req, tt.instrumentID)
+			}
+		})
+	}
+}
+
+func TestGetBrandBy(t *testing.T) {
+	tests := []struct {
+		name     string
+		brandID  string
+		wantName string
+		wantErr  bool
+	}{
+		{name: "known brand", brandID: "brand-1", wantName: "Sberbank"},
+		{name: "unknown brand", brandID: "missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			instruments := &fakeInstrumentsClient{brands: []*investapi.Brand{{Uid: "brand-1", Name: "Sberbank"}}}
+			c.instrumentsClient = instruments
+
+			brand, err := c.GetBrandBy(context.Background(), tt.brandID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && brand.Name != tt.wantName {
+				t.Fatalf("brand = %q, want %q", brand.Name, tt.wantName)
+			}
+			if req := instruments.recorded()[0].(*investapi.GetBrandRequest); req.Id != tt.brandID {
+				t.Fatalf("requested brand %q, want %q", req.Id, tt.brandID)
+			}
+		})
+	}
+}
+
+func TestFindInstrumentWithOptions(t *testing.T) {
+	found := []*investapi.InstrumentShort{{Figi: "BBG1"}, {Figi: "BBG2"}, {Figi: "BBG3"}}
+	share := investapi.InstrumentType_INSTRUMENT_TYPE_SHARE
+
+	tests := []struct {
+		name     string
+		opts     FindInstrumentOptions
+		want     []string
+		wantErr  bool
+		wantKind investapi.InstrumentType
+	}{
+		{name: "no limit", want: []string{"BBG1", "BBG2", "BBG3"}},
+		{name: "limit truncates", opts: FindInstrumentOptions{Limit: 2}, want: []string{"BBG1", "BBG2"}},
+		{name: "limit above results", opts: FindInstrumentOptions{Limit: 10}, want: []string{"BBG1", "BBG2", "BBG3"}},
+		{
+			name:     "instrument type",
+			opts:     FindInstrumentOptions{InstrumentType: &share, APITradeAvailableOnly: true, Limit: 1},
+			want:     []string{"BBG1"},
+			wantKind: share,
+		},
+		{name: "negative limit", opts: FindInstrumentOptions{Limit: -1}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			instruments := &fakeInstrumentsClient{found: found}
+			c.instrumentsClient = instruments
+
+			got, err := c.FindInstrumentWithOptions(context.Background(), "sber", tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("instruments = %v, want %v", got, tt.want)
+			}
+			for i, figi := range tt.want {
+				if got[i].Figi != figi {
+					t.Fatalf("instrument %d = %s, want %s", i, got[i].Figi, figi)
+				}
+			}
+
+			req := instruments.recorded()[0].(*investapi.FindInstrumentRequest)
+			if req.Query != "sber" || req.GetInstrumentKind() != tt.wantKind || req.GetApiTradeAvailableFlag() != tt.opts.APITradeAvailableOnly {
+				t.Fatalf("request = %v, want query sber with the options", req)
+			}
+		})
+	}
+}
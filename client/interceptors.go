@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/grpclog"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// trackingIDHeader is the metadata key Tinkoff uses to identify a request
+const trackingIDHeader = "x-tracking-id"
+
+// trackingInterceptor captures the tracking ID of every unary call.
+// Successful calls update LastTrackingID, failed calls get it attached to the error.
+// The call outcome and rate limit headers are recorded for Status.
+func (c *RealClient) trackingInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	var header, trailer metadata.MD
+	opts = append(opts, grpc.Header(&header), grpc.Trailer(&trailer))
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	c.callStats.record(method, err, header)
+
+	trackingID := firstMetadataValue(trailer, trackingIDHeader)
+	if trackingID == "" {
+		trackingID = firstMetadataValue(header, trackingIDHeader)
+	}
+
+	if err != nil {
+		if trackingID != "" {
+			return &TrackingError{TrackingID: trackingID, Err: err}
+		}
+		return err
+	}
+
+	if trackingID != "" {
+		c.lastTrackingID.Store(trackingID)
+	}
+
+	return nil
+}
+
+// errorMessageTrailer carries the human readable description of an API error
+const errorMessageTrailer = "message"
+
+// marginConfirmationErrorCode is the API error code of orders that may open
+// an uncovered position and were sent without confirm_margin_trade
+const marginConfirmationErrorCode = "30125"
+
+// uncoveredPositionMarker is part of the API error description of orders that
+// may open an uncovered position. It is only used for errors without a
+// numeric API error code.
+const uncoveredPositionMarker = "непокрыт"
+
+// apiErrorCode returns the numeric API error code, e.g. "30042", that the API
+// sends as the status message of its errors, or "" when the message is not
+// such a code
+func apiErrorCode(err error) string {
+	var grpcErr interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &grpcErr) {
+		return ""
+	}
+
+	code := strings.TrimSpace(grpcErr.GRPCStatus().Message())
+	if code == "" || strings.IndexFunc(code, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return ""
+	}
+	return code
+}
+
+// isMarginConfirmationError reports whether an order was rejected because it
+// needs margin trade confirmation. The API error code decides; errors without
+// one fall back to looking for the uncovered position wording in the error
+// description, which breaks if the API rewords it.
+func isMarginConfirmationError(err error, trailer metadata.MD) bool {
+	if status.Code(err) != codes.FailedPrecondition && status.Code(err) != codes.InvalidArgument {
+		return false
+	}
+
+	if code := apiErrorCode(err); code != "" {
+		return code == marginConfirmationErrorCode
+	}
+
+	description := firstMetadataValue(trailer, errorMessageTrailer)
+	if description == "" {
+		description = status.Convert(err).Message()
+	}
+
+	return strings.Contains(strings.ToLower(description), uncoveredPositionMarker)
+}
+
+// firstMetadataValue returns the first value stored under key or an empty string
+func firstMetadataValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// timeoutInterceptor applies config.MethodTimeouts to calls whose context has
+// no deadline. It runs inside the retry interceptor, so every attempt gets the
+// full timeout.
+func (c *RealClient) timeoutInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if _, ok := ctx.Deadline(); !ok {
+		if timeout, ok := c.config.MethodTimeouts[method]; ok && timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+	}
+
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// debugInterceptor logs the method name, latency and request labels of every unary call
+func debugInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	start := time.Now()
+	err := invoker(ctx, method, req, reply, cc, opts...)
+
+	labels := formatRequestLabels(ctx)
+	if labels != "" {
+		labels = " [" + labels + "]"
+	}
+
+	if err != nil {
+		log.Printf("🐛 %s%s failed in %s: %v", method, labels, time.Since(start), err)
+	} else {
+		log.Printf("🐛 %s%s completed in %s", method, labels, time.Since(start))
+	}
+
+	return err
+}
+
+var grpcDebugOnce sync.Once
+
+// enableGRPCDebugLogging switches the process-wide gRPC logger to verbose output
+func enableGRPCDebugLogging() {
+	grpcDebugOnce.Do(func() {
+		grpclog.SetLoggerV2(grpclog.NewLoggerV2WithVerbosity(os.Stderr, os.Stderr, os.Stderr, 2))
+	})
+}
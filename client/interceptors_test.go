@@ -0,0 +1,193 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/buurzx/tinkoff-go/config"
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestTrackingID(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		wantErr bool
+	}{
+		{name: "success", err: nil},
+		{name: "error", err: status.Error(codes.NotFound, "account not found"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &usersServer{accounts: func(ctx context.Context) (*investapi.GetAccountsResponse, error) {
+				if err := grpc.SetTrailer(ctx, metadata.Pairs(trackingIDHeader, "track-"+tt.name)); err != nil {
+					return nil, err
+				}
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return &investapi.GetAccountsResponse{}, nil
+			}}
+			c := newServerClient(t, nil, func(srv *grpc.Server) {
+				investapi.RegisterUsersServiceServer(srv, users)
+			})
+
+			_, err := c.RefreshAccounts(context.Background())
+
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("RefreshAccounts: %v", err)
+				}
+				if got := c.LastTrackingID(); got != "track-success" {
+					t.Fatalf("LastTrackingID = %q, want track-success", got)
+				}
+				return
+			}
+
+			var tracking *TrackingError
+			if !errors.As(err, &tracking) {
+				t.Fatalf("err = %v, want a TrackingError", err)
+			}
+			if tracking.TrackingID != "track-error" {
+				t.Fatalf("TrackingID = %q, want track-error", tracking.TrackingID)
+			}
+			if status.Code(err) != codes.NotFound {
+				t.Fatalf("status code = %s, want NotFound through the wrapper", status.Code(err))
+			}
+			if got := c.LastTrackingID(); got != "" {
+				t.Fatalf("LastTrackingID = %q after a failed call, want empty", got)
+			}
+		})
+	}
+}
+
+func TestIsMarginConfirmationError(t *testing.T) {
+	const description = "Недостаточно средств, заявка может открыть непокрытую позицию"
+
+	tests := []struct {
+		name    string
+		err     error
+		trailer metadata.MD
+		want    bool
+	}{
+		{
+			name:    "margin confirmation code",
+			err:     status.Error(codes.FailedPrecondition, marginConfirmationErrorCode),
+			trailer: metadata.Pairs(errorMessageTrailer, "Reworded description"),
+			want:    true,
+		},
+		{
+			name:    "other code with the wording in the trailer",
+			err:     status.Error(codes.FailedPrecondition, "30042"),
+			trailer: metadata.Pairs(errorMessageTrailer, description),
+		},
+		{
+			name: "no code, description in status message",
+			err:  status.Error(codes.InvalidArgument, description),
+			want: true,
+		},
+		{
+			name: "no code, other description",
+			err:  status.Error(codes.FailedPrecondition, "Недостаточно активов для сделки"),
+		},
+		{
+			name: "other status code",
+			err:  status.Error(codes.Unavailable, marginConfirmationErrorCode),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isMarginConfirmationError(tt.err, tt.trailer); got != tt.want {
+				t.Fatalf("isMarginConfirmationError = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIErrorCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "numeric code", err: status.Error(codes.FailedPrecondition, "30042"), want: "30042"},
+		{name: "wrapped", err: fmt.Errorf("post: %w", status.Error(codes.InvalidArgument, "30001")), want: "30001"},
+		{name: "description", err: status.Error(codes.InvalidArgument, "bad request")},
+		{name: "empty", err: status.Error(codes.Internal, "")},
+		{name: "not a status", err: errors.New("30042 failed")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := apiErrorCode(tt.err); got != tt.want {
+				t.Fatalf("apiErrorCode = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeoutInterceptor(t *testing.T) {
+	getAccounts := investapi.UsersService_GetAccounts_FullMethodName
+
+	tests := []struct {
+		name          string
+		timeouts      map[string]time.Duration
+		callerTimeout time.Duration
+		wantDeadline  time.Duration
+	}{
+		{name: "method timeout", timeouts: map[string]time.Duration{getAccounts: 5 * time.Second}, wantDeadline: 5 * time.Second},
+		{
+			name:          "caller deadline wins",
+			timeouts:      map[string]time.Duration{getAccounts: 5 * time.Second},
+			callerTimeout: time.Minute,
+			wantDeadline:  time.Minute,
+		},
+		{name: "other method", timeouts: map[string]time.Duration{investapi.OrdersService_PostOrder_FullMethodName: time.Second}},
+		{name: "no timeouts"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var remaining time.Duration
+			users := &usersServer{accounts: func(ctx context.Context) (*investapi.GetAccountsResponse, error) {
+				if deadline, ok := ctx.Deadline(); ok {
+					remaining = time.Until(deadline)
+				}
+				return &investapi.GetAccountsResponse{}, nil
+			}}
+			c := newServerClient(t, &config.Config{MethodTimeouts: tt.timeouts}, func(srv *grpc.Server) {
+				investapi.RegisterUsersServiceServer(srv, users)
+			})
+
+			ctx := context.Background()
+			if tt.callerTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.callerTimeout)
+				defer cancel()
+			}
+			if _, err := c.RefreshAccounts(ctx); err != nil {
+				t.Fatalf("RefreshAccounts: %v", err)
+			}
+
+			if tt.wantDeadline == 0 {
+				if remaining != 0 {
+					t.Fatalf("server deadline in %s, want none", remaining)
+				}
+				return
+			}
+			if remaining <= tt.wantDeadline-time.Second || remaining > tt.wantDeadline {
+				t.Fatalf("server deadline in %s, want about %s", remaining, tt.wantDeadline)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// requestLabelsKey is the context key of request labels
+type requestLabelsKey struct{}
+
+// WithRequestLabel returns a context carrying the label key=value. Labels tag
+// the calls made with the context, e.g. by strategy or tenant, and are added
+// to the debug log records of those calls. Labels are not sent to the API.
+func WithRequestLabel(ctx context.Context, key, value string) context.Context {
+	parent := RequestLabels(ctx)
+
+	labels := make(map[string]string, len(parent)+1)
+	for k, v := range parent {
+		labels[k] = v
+	}
+	labels[key] = value
+
+	return context.WithValue(ctx, requestLabelsKey{}, labels)
+}
+
+// RequestLabels returns the labels stored in ctx by WithRequestLabel.
+// The returned map must not be modified.
+func RequestLabels(ctx context.Context) map[string]string {
+	labels, _ := ctx.Value(requestLabelsKey{}).(map[string]string)
+	return labels
+}
+
+// formatRequestLabels renders the labels of ctx as sorted "key=value" pairs
+func formatRequestLabels(ctx context.Context) string {
+	labels := RequestLabels(ctx)
+	if len(labels) == 0 {
+		return ""
+	}
+
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, " ")
+}
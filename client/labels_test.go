@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRequestLabels(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels [][2]string
+		want   string
+	}{
+		{name: "no labels", want: ""},
+		{name: "one label", labels: [][2]string{{"strategy", "grid"}}, want: "strategy=grid"},
+		{name: "sorted by key", labels: [][2]string{{"tenant", "a"}, {"strategy", "grid"}}, want: "strategy=grid tenant=a"},
+		{name: "later value wins", labels: [][2]string{{"strategy", "grid"}, {"strategy", "dca"}}, want: "strategy=dca"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			for _, label := range tt.labels {
+				ctx = WithRequestLabel(ctx, label[0], label[1])
+			}
+
+			if got := formatRequestLabels(ctx); got != tt.want {
+				t.Fatalf("formatRequestLabels = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithRequestLabelKeepsParent(t *testing.T) {
+	parent := WithRequestLabel(context.Background(), "strategy", "grid")
+	_ = WithRequestLabel(parent, "tenant", "a")
+
+	if labels := RequestLabels(parent); len(labels) != 1 {
+		t.Fatalf("parent labels = %v, want only strategy", labels)
+	}
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/buurzx/tinkoff-go/internal"
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// lastPriceEntry is a cached last price with its exchange time
+type lastPriceEntry struct {
+	price *investapi.Quotation
+	at    time.Time
+}
+
+// StartLastPriceCache subscribes to last prices of the instruments and keeps
+// the latest price of each in memory until ctx is cancelled or the client is
+// closed. Read the cache with LastPrice. It returns once the subscription is sent.
+// The stream runs on a context derived from ctx, so cancelling ctx ends it.
+func (c *RealClient) StartLastPriceCache(ctx context.Context, instrumentIDs []string) error {
+	session, err := c.startMarketDataSession(ctx, []MarketDataSubscription{{
+		Type:          SubscriptionLastPrices,
+		InstrumentIDs: instrumentIDs,
+	}})
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		defer session.Close()
+
+		retry := c.streamRetryConfig()
+		attempt := 0
+
+		for {
+			resp, err := session.Recv()
+			if err == nil {
+				attempt = 0
+				if payload, ok := resp.Payload.(*investapi.MarketDataResponse_LastPrice); ok {
+					c.storeLastPrice(payload.LastPrice)
+				}
+				continue
+			}
+
+			if ctx.Err() != nil || c.ctx.Err() != nil {
+				return
+			}
+			// A stream ended by the server is reopened like a failed one, the
+			// connection may well still be ready
+			if !IsStreamRecoverable(err) && !errors.Is(err, io.EOF) {
+				log.Printf("❌ Last price cache stopped: %v", err)
+				return
+			}
+
+			if err := c.reconnectLastPriceSession(ctx, session, retry, &attempt); err != nil {
+				log.Printf("❌ Last price cache stopped: %v", err)
+				return
+			}
+		}
+	}()
+
+	log.Printf("💾 Last price cache started for %d instruments", len(instrumentIDs))
+	return nil
+}
+
+// reconnectLastPriceSession reopens the session stream with the stream retry
+// backoff until it succeeds, the attempts run out or ctx is done
+func (c *RealClient) reconnectLastPriceSession(ctx context.Context, session *MarketDataSession, retry *internal.RetryConfig, attempt *int) error {
+	for {
+		if *attempt >= retry.MaxRetries {
+			return fmt.Errorf("last price stream not recovered after %d attempts", *attempt)
+		}
+		if err := sleepContext(ctx, retry.CalculateBackoff(*attempt)); err != nil {
+			return err
+		}
+		*attempt++
+
+		err := session.Reconnect()
+		if err == nil {
+			return nil
+		}
+		log.Printf("⚠️ Last price stream reconnect failed: %v", err)
+	}
+}
+
+// LastPrice returns the latest streamed price of an instrument by FIGI or
+// instrument UID. ok is false until the first price for it arrives. The
+// price is a copy the caller may keep or modify.
+func (c *RealClient) LastPrice(instrumentID string) (price *investapi.Quotation, at time.Time, ok bool) {
+	c.lastPricesMu.RLock()
+	defer c.lastPricesMu.RUnlock()
+
+	entry, ok := c.lastPrices[instrumentID]
+	if !ok {
+		return nil, time.Time{}, false
+	}
+	return proto.Clone(entry.price).(*investapi.Quotation), entry.at, true
+}
+
+func (c *RealClient) storeLastPrice(lastPrice *investapi.LastPrice) {
+	entry := lastPriceEntry{
+		price: lastPrice.Price,
+		at:    lastPrice.Time.AsTime(),
+	}
+
+	c.lastPricesMu.Lock()
+	defer c.lastPricesMu.Unlock()
+
+	if c.lastPrices == nil {
+		c.lastPrices = make(map[string]lastPriceEntry)
+	}
+	if lastPrice.Figi != "" {
+		c.lastPrices[lastPrice.Figi] = entry
+	}
+	if lastPrice.InstrumentUid != "" {
+		c.lastPrices[lastPrice.InstrumentUid] = entry
+	}
+}
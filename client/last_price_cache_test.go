@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/buurzx/tinkoff-go/config"
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func lastPriceResponse(figi string, price float64) *investapi.MarketDataResponse {
+	return &investapi.MarketDataResponse{
+		Payload: &investapi.MarketDataResponse_LastPrice{
+			LastPrice: &investapi.LastPrice{Figi: figi, Price: NewQuotationRounded(price), Time: timestamppb.Now()},
+		},
+	}
+}
+
+func TestLastPriceCache(t *testing.T) {
+	tests := []struct {
+		name string
+		// end terminates the first stream after its price
+		end error
+	}{
+		{name: "server ends the stream", end: io.EOF},
+		{name: "stream fails", end: status.Error(codes.Unavailable, "connection reset")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(&config.Config{
+				StreamRetry: config.StreamRetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+			})
+			defer c.cancel()
+			streams := &fakeMarketDataStreamClient{}
+			c.marketDataStreamClient = streams
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			if _, _, ok := c.LastPrice("BBG1"); ok {
+				t.Fatal("LastPrice before any data should not be ok")
+			}
+			if err := c.StartLastPriceCache(ctx, []string{"BBG1"}); err != nil {
+				t.Fatalf("StartLastPriceCache: %v", err)
+			}
+
+			first := streams.stream(0)
+			first.recv <- lastPriceResponse("BBG1", 100)
+			waitForPrice(t, c, "BBG1", 100)
+			first.fail(tt.end)
+
+			second := waitForStream(t, streams, 1)
+			waitFor(t, "subscriptions replayed", func() bool {
+				return subscribedInstruments(second.requests()) == 1
+			})
+			second.recv <- lastPriceResponse("BBG1", 101.5)
+			waitForPrice(t, c, "BBG1", 101.5)
+		})
+	}
+}
+
+func waitForPrice(t *testing.T, c *RealClient, figi string, want float64) {
+	t.Helper()
+
+	waitFor(t, "last price of "+figi, func() bool {
+		price, _, ok := c.LastPrice(figi)
+		return ok && QuotationEqual(price, NewQuotationRounded(want))
+	})
+}
+
+func waitForStream(t *testing.T, streams *fakeMarketDataStreamClient, i int) *fakeMarketDataStream {
+	t.Helper()
+
+	waitFor(t, "stream opened", func() bool {
+		streams.mu.Lock()
+		defer streams.mu.Unlock()
+		return len(streams.streams) > i
+	})
+	return streams.stream(i)
+}
+
+func TestLastPriceCacheStopsWithContext(t *testing.T) {
+	c := newTestClient(nil)
+	defer c.cancel()
+	streams := &fakeMarketDataStreamClient{}
+	c.marketDataStreamClient = streams
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := c.StartLastPriceCache(ctx, []string{"BBG1"}); err != nil {
+		t.Fatalf("StartLastPriceCache: %v", err)
+	}
+	stream := streams.stream(0)
+	stream.recv <- lastPriceResponse("BBG1", 100)
+	waitForPrice(t, c, "BBG1", 100)
+
+	cancel()
+	waitFor(t, "stream cancelled", func() bool { return stream.Context().Err() != nil })
+	// Give the cache goroutine time to observe the cancellation and return
+	time.Sleep(20 * time.Millisecond)
+
+	stream.recv <- lastPriceResponse("BBG1", 200)
+	time.Sleep(50 * time.Millisecond)
+
+	if price, _, _ := c.LastPrice("BBG1"); !QuotationEqual(price, NewQuotationRounded(100)) {
+		t.Fatalf("LastPrice after cancel = %v, want 100", price)
+	}
+	if got := len(stream.recv); got != 1 {
+		t.Fatalf("unread messages = %d, want the price sent after cancel left unread", got)
+	}
+	if got := streams.openCount(); got != 1 {
+		t.Fatalf("stream opens = %d, want no reconnect after cancel", got)
+	}
+}
+
+func TestLastPriceReturnsCopy(t *testing.T) {
+	c := newTestClient(nil)
+	c.storeLastPrice(&investapi.LastPrice{Figi: "BBG1", Price: NewQuotationRounded(100), Time: timestamppb.Now()})
+
+	price, _, _ := c.LastPrice("BBG1")
+	price.Units = 1
+
+	if got, _, _ := c.LastPrice("BBG1"); !QuotationEqual(got, NewQuotationRounded(100)) {
+		t.Fatalf("cached price = %v after the caller changed its copy, want 100", got)
+	}
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// LocalOrderBook keeps the latest state of a streamed order book and answers
+// queries about it. Bids are kept sorted from the highest price and asks from
+// the lowest, limited to the configured depth. It is safe for concurrent use.
+type LocalOrderBook struct {
+	depth int
+
+	mu        sync.RWMutex
+	figi      string
+	uid       string
+	bids      []*investapi.Order
+	asks      []*investapi.Order
+	updatedAt time.Time
+}
+
+var _ OrderBookSides = (*LocalOrderBook)(nil)
+
+// NewLocalOrderBook creates an empty book keeping depth levels per side;
+// zero keeps every level received
+func NewLocalOrderBook(depth int) *LocalOrderBook {
+	return &LocalOrderBook{depth: depth}
+}
+
+// Apply replaces the book with an order book message. Messages older than
+// the current state are ignored, and messages for another instrument are
+// rejected once the book holds data.
+func (b *LocalOrderBook) Apply(book *investapi.OrderBook) error {
+	if book == nil {
+		return fmt.Errorf("order book is required")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.figi != "" && book.Figi != "" && book.Figi != b.figi {
+		return fmt.Errorf("order book for %s cannot be applied to %s", book.Figi, b.figi)
+	}
+
+	at := book.GetTime().AsTime()
+	if book.Time != nil && !b.updatedAt.IsZero() && at.Before(b.updatedAt) {
+		return nil
+	}
+
+	b.figi = book.Figi
+	b.uid = book.InstrumentUid
+	b.bids = b.sortedLevels(book.Bids, true)
+	b.asks = b.sortedLevels(book.Asks, false)
+	if book.Time != nil {
+		b.updatedAt = at
+	}
+
+	return nil
+}
+
+// sortedLevels copies the non-empty levels ordered from the best price and
+// trimmed to the book depth
+func (b *LocalOrderBook) sortedLevels(levels []*investapi.Order, descending bool) []*investapi.Order {
+	sorted := make([]*investapi.Order, 0, len(levels))
+	for _, level := range levels {
+		if level.GetPrice() == nil || level.Quantity <= 0 {
+			continue
+		}
+		sorted = append(sorted, proto.Clone(level).(*investapi.Order))
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		pi, pj := quotationToNanos(sorted[i].Price), quotationToNanos(sorted[j].Price)
+		if descending {
+			return pi > pj
+		}
+		return pi < pj
+	})
+
+	if b.depth > 0 && len(sorted) > b.depth {
+		sorted = sorted[:b.depth]
+	}
+	return sorted
+}
+
+// BestBid returns the highest bid price; ok is false when there are no bids
+func (b *LocalOrderBook) BestBid() (price *investapi.Quotation, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if len(b.bids) == 0 {
+		return nil, false
+	}
+	return proto.Clone(b.bids[0].Price).(*investapi.Quotation), true
+}
+
+// BestAsk returns the lowest ask price; ok is false when there are no asks
+func (b *LocalOrderBook) BestAsk() (price *investapi.Quotation, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if len(b.asks) == 0 {
+		return nil, false
+	}
+	return proto.Clone(b.asks[0].Price).(*investapi.Quotation), true
+}
+
+// Spread returns the difference between the best ask and the best bid;
+// ok is false when either side is empty
+func (b *LocalOrderBook) Spread() (spread *investapi.Quotation, ok bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if len(b.bids) == 0 || len(b.asks) == 0 {
+		return nil, false
+	}
+	return nanosToQuotation(quotationToNanos(b.asks[0].Price) - quotationToNanos(b.bids[0].Price)), true
+}
+
+// GetBids returns a deep copy of the bid levels, best first
+func (b *LocalOrderBook) GetBids() []*investapi.Order {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return cloneOrders(b.bids)
+}
+
+// GetAsks returns a deep copy of the ask levels, best first
+func (b *LocalOrderBook) GetAsks() []*investapi.Order {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return cloneOrders(b.asks)
+}
+
+// cloneOrders copies levels so callers cannot modify the book
+func cloneOrders(levels []*investapi.Order) []*investapi.Order {
+	orders := make([]*investapi.Order, len(levels))
+	for i, level := range levels {
+		orders[i] = proto.Clone(level).(*investapi.Order)
+	}
+	return orders
+}
+
+// Snapshot returns the current book as an order book message that is not
+// affected by later updates
+func (b *LocalOrderBook) Snapshot() *investapi.OrderBook {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	book := &investapi.OrderBook{
+		Figi:          b.figi,
+		InstrumentUid: b.uid,
+		Depth:         int32(len(b.bids)),
+		Bids:          cloneOrders(b.bids),
+		Asks:          cloneOrders(b.asks),
+	}
+	if len(b.asks) > len(b.bids) {
+		book.Depth = int32(len(b.asks))
+	}
+	if !b.updatedAt.IsZero() {
+		book.Time = timestamppb.New(b.updatedAt)
+	}
+
+	return book
+}
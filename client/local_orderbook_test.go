@@ -0,0 +1,76 @@
+package client
+
+import (
+	"testing"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func level(units int64, quantity int64) *investapi.Order {
+	return &investapi.Order{Price: &investapi.Quotation{Units: units}, Quantity: quantity}
+}
+
+func TestLocalOrderBookCopiesAreIndependent(t *testing.T) {
+	tests := []struct {
+		name   string
+		mutate func(b *LocalOrderBook)
+	}{
+		{
+			name: "GetBids",
+			mutate: func(b *LocalOrderBook) {
+				bids := b.GetBids()
+				bids[0].Quantity = 0
+				bids[0].Price.Units = 1
+			},
+		},
+		{
+			name: "GetAsks",
+			mutate: func(b *LocalOrderBook) {
+				asks := b.GetAsks()
+				asks[0].Quantity = 0
+				asks[0].Price.Units = 1
+			},
+		},
+		{
+			name: "BestBid and BestAsk",
+			mutate: func(b *LocalOrderBook) {
+				bid, _ := b.BestBid()
+				bid.Units = 1
+				ask, _ := b.BestAsk()
+				ask.Units = 1
+			},
+		},
+		{
+			name: "Snapshot",
+			mutate: func(b *LocalOrderBook) {
+				book := b.Snapshot()
+				book.Bids[0].Price.Units = 1
+				book.Asks[0].Quantity = 0
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewLocalOrderBook(0)
+			err := b.Apply(&investapi.OrderBook{
+				Figi: "BBG1",
+				Bids: []*investapi.Order{level(99, 5), level(98, 7)},
+				Asks: []*investapi.Order{level(101, 3)},
+			})
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+
+			tt.mutate(b)
+
+			bids, asks := b.GetBids(), b.GetAsks()
+			if bids[0].Price.Units != 99 || bids[0].Quantity != 5 {
+				t.Fatalf("best bid = %v, want 99 x 5", bids[0])
+			}
+			if asks[0].Price.Units != 101 || asks[0].Quantity != 3 {
+				t.Fatalf("best ask = %v, want 101 x 3", asks[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,536 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// SubscriptionType identifies the kind of market data a subscription delivers
+type SubscriptionType int
+
+const (
+	SubscriptionCandles SubscriptionType = iota
+	SubscriptionOrderBook
+	SubscriptionTrades
+	SubscriptionLastPrices
+)
+
+// String returns a human readable subscription type name
+func (t SubscriptionType) String() string {
+	switch t {
+	case SubscriptionCandles:
+		return "candles"
+	case SubscriptionOrderBook:
+		return "order book"
+	case SubscriptionTrades:
+		return "trades"
+	case SubscriptionLastPrices:
+		return "last prices"
+	default:
+		return fmt.Sprintf("SubscriptionType(%d)", int(t))
+	}
+}
+
+// Subscription describes a market data subscription for a single instrument
+type Subscription struct {
+	Type         SubscriptionType
+	InstrumentID string
+
+	// Candles only
+	Interval     investapi.SubscriptionInterval
+	WaitingClose bool
+
+	// Order book only. The stream always delivers full snapshots of Depth
+	// levels, there is no incremental mode; see MarketDataSubscription.
+	Depth         int32
+	OrderBookType investapi.OrderBookType
+}
+
+// Values of the waitingClose flag of candle subscriptions
+const (
+	// CandlesOnEveryTrade sends the current candle after every trade in it
+	CandlesOnEveryTrade = false
+	// CandlesOnClose sends each candle once, after its interval has closed
+	CandlesOnClose = true
+)
+
+// MarketDataSubscription describes a subscription of one data type for several instruments
+type MarketDataSubscription struct {
+	Type          SubscriptionType
+	InstrumentIDs []string
+
+	// Candles only. WaitingClose set to CandlesOnClose delivers only
+	// completed candles.
+	Interval     investapi.SubscriptionInterval
+	WaitingClose bool
+
+	// Order book only. Every message is a full snapshot of Depth levels that
+	// replaces the previous one, so a smaller depth is the way to lighten the
+	// stream; LocalOrderBook.Apply relies on this. OrderBookType selects the
+	// exchange, dealer or combined book; unspecified lets the API choose.
+	Depth         int32
+	OrderBookType investapi.OrderBookType
+}
+
+// Subscriptions expands the spec into per-instrument subscriptions
+func (m MarketDataSubscription) Subscriptions() []Subscription {
+	subs := make([]Subscription, len(m.InstrumentIDs))
+	for i, instrumentID := range m.InstrumentIDs {
+		subs[i] = Subscription{
+			Type:          m.Type,
+			InstrumentID:  instrumentID,
+			Interval:      m.Interval,
+			WaitingClose:  m.WaitingClose,
+			Depth:         m.Depth,
+			OrderBookType: m.OrderBookType,
+		}
+	}
+	return subs
+}
+
+// ToRequest builds the subscribe request for the spec.
+// It returns nil when the spec has no instruments.
+func (m MarketDataSubscription) ToRequest() *investapi.MarketDataRequest {
+	requests := buildSubscriptionRequests(investapi.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE, m.Subscriptions(), 0)
+	if len(requests) == 0 {
+		return nil
+	}
+	return requests[0]
+}
+
+// sameStream reports whether s and other select the same data type of the
+// same instrument, whatever their parameters
+func (s Subscription) sameStream(other Subscription) bool {
+	return s.Type == other.Type && s.InstrumentID == other.InstrumentID
+}
+
+// hasParams reports whether any type specific parameter is set
+func (s Subscription) hasParams() bool {
+	return s != Subscription{Type: s.Type, InstrumentID: s.InstrumentID}
+}
+
+// MarketDataSession wraps a market data stream so it can be shared between goroutines.
+// Sends on the stream are serialized and active subscriptions are tracked in a registry,
+// which is replayed when the session reconnects. The registry is keyed by the
+// whole Subscription, so 1m and 5m candles of one instrument are two entries.
+type MarketDataSession struct {
+	client *RealClient
+
+	// ctx is cancelled by Close or when the client is closed; every stream
+	// of the session runs on a child of it
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   func() bool
+
+	// mu guards stream sends and the subscription registry
+	mu           sync.Mutex
+	stream       investapi.MarketDataStreamService_MarketDataStreamClient
+	cancelStream context.CancelFunc
+	active       map[Subscription]struct{}
+}
+
+// NewMarketDataSession starts a market data stream and wraps it in a session
+func (c *RealClient) NewMarketDataSession() (*MarketDataSession, error) {
+	return c.newMarketDataSession(c.ctx)
+}
+
+// newMarketDataSession starts a session whose streams end when ctx is
+// cancelled, the session is closed or the client is closed
+func (c *RealClient) newMarketDataSession(ctx context.Context) (*MarketDataSession, error) {
+	sessionCtx, cancel := context.WithCancel(ctx)
+	session := &MarketDataSession{
+		client: c,
+		ctx:    sessionCtx,
+		cancel: cancel,
+		stop:   context.AfterFunc(c.ctx, cancel),
+		active: make(map[Subscription]struct{}),
+	}
+
+	stream, cancelStream, err := session.openStream()
+	if err != nil {
+		session.stop()
+		cancel()
+		return nil, err
+	}
+	session.stream = stream
+	session.cancelStream = cancelStream
+	c.registerSession(session)
+
+	return session, nil
+}
+
+// openStream starts a market data stream on a child of the session context.
+// The returned func cancels the stream.
+func (s *MarketDataSession) openStream() (investapi.MarketDataStreamService_MarketDataStreamClient, context.CancelFunc, error) {
+	ctx, cancel := context.WithCancel(s.ctx)
+	stream, err := s.client.startMarketDataStream(ctx)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	return stream, cancel, nil
+}
+
+// StartMarketDataSession starts a market data session and subscribes to all specs.
+// The specs become part of the session registry and are replayed on reconnect.
+func (c *RealClient) StartMarketDataSession(specs []MarketDataSubscription) (*MarketDataSession, error) {
+	return c.startMarketDataSession(c.ctx, specs)
+}
+
+// startMarketDataSession is StartMarketDataSession with the session bound to ctx
+func (c *RealClient) startMarketDataSession(ctx context.Context, specs []MarketDataSubscription) (*MarketDataSession, error) {
+	session, err := c.newMarketDataSession(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var subs []Subscription
+	for _, spec := range specs {
+		subs = append(subs, spec.Subscriptions()...)
+	}
+
+	if err := session.Subscribe(subs...); err != nil {
+		session.Close()
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// Subscribe subscribes to the given instruments and records them in the registry.
+// Subscriptions that are already active with the same parameters are skipped,
+// so the stream never delivers the same data twice. Nothing is sent and
+// ErrSubscriptionLimit is returned when the registry would grow past
+// config.MaxStreamSubscriptions.
+func (s *MarketDataSession) Subscribe(subs ...Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := make([]Subscription, 0, len(subs))
+	seen := make(map[Subscription]struct{}, len(subs))
+	for _, sub := range subs {
+		if _, ok := s.active[sub]; ok {
+			continue
+		}
+		if _, ok := seen[sub]; ok {
+			continue
+		}
+		seen[sub] = struct{}{}
+		pending = append(pending, sub)
+	}
+
+	if skipped := len(subs) - len(pending); skipped > 0 {
+		log.Printf("ℹ️ Skipped %d market data subscriptions that are already active", skipped)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if limit := s.client.maxStreamSubscriptions(); len(s.active)+len(pending) > limit {
+		return fmt.Errorf("cannot add %d subscriptions to %d active: %w (max %d)", len(pending), len(s.active), ErrSubscriptionLimit, limit)
+	}
+
+	if err := s.send(investapi.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE, pending); err != nil {
+		return err
+	}
+
+	for _, sub := range pending {
+		s.active[sub] = struct{}{}
+	}
+
+	return nil
+}
+
+// Unsubscribe unsubscribes from the given instruments and removes them from the registry.
+// A subscription with only Type and InstrumentID set removes every active
+// subscription of that type for the instrument, e.g. all candle intervals.
+func (s *MarketDataSession) Unsubscribe(subs ...Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resolved := s.resolve(subs)
+	if err := s.send(investapi.SubscriptionAction_SUBSCRIPTION_ACTION_UNSUBSCRIBE, resolved); err != nil {
+		return err
+	}
+
+	for _, sub := range resolved {
+		delete(s.active, sub)
+	}
+
+	return nil
+}
+
+// resolve expands parameterless subscriptions to the matching active ones,
+// so they are unsubscribed with the parameters used to subscribe. Callers
+// must hold s.mu.
+func (s *MarketDataSession) resolve(subs []Subscription) []Subscription {
+	resolved := make([]Subscription, 0, len(subs))
+	seen := make(map[Subscription]struct{}, len(subs))
+	add := func(sub Subscription) {
+		if _, ok := seen[sub]; !ok {
+			seen[sub] = struct{}{}
+			resolved = append(resolved, sub)
+		}
+	}
+
+	for _, sub := range subs {
+		if _, ok := s.active[sub]; ok || sub.hasParams() {
+			add(sub)
+			continue
+		}
+
+		matched := false
+		for _, active := range s.snapshot() {
+			if active.sameStream(sub) {
+				add(active)
+				matched = true
+			}
+		}
+		if !matched {
+			add(sub)
+		}
+	}
+
+	return resolved
+}
+
+// ActiveSubscriptions returns a snapshot of the subscription registry
+func (s *MarketDataSession) ActiveSubscriptions() []Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.snapshot()
+}
+
+// Recv receives the next message from the underlying stream.
+// Only one goroutine should call Recv at a time.
+func (s *MarketDataSession) Recv() (*investapi.MarketDataResponse, error) {
+	s.mu.Lock()
+	stream := s.stream
+	s.mu.Unlock()
+
+	return stream.Recv()
+}
+
+// Reconnect starts a new market data stream and replays all active
+// subscriptions on it. The replaced stream is cancelled.
+func (s *MarketDataSession) Reconnect() error {
+	stream, cancelStream, err := s.openStream()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, cancelOld := s.stream, s.cancelStream
+	s.stream, s.cancelStream = stream, cancelStream
+	if old != nil {
+		_ = old.CloseSend()
+		cancelOld()
+	}
+
+	subs := s.snapshot()
+	if len(subs) == 0 {
+		return nil
+	}
+
+	if err := s.send(investapi.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE, subs); err != nil {
+		return fmt.Errorf("failed to replay subscriptions: %w", err)
+	}
+
+	log.Printf("🔁 Replayed %d market data subscriptions", len(subs))
+	return nil
+}
+
+// Close closes the send direction of the underlying stream and cancels it,
+// which ends a pending Recv. A closed session is no longer reconnected by the
+// client.
+func (s *MarketDataSession) Close() error {
+	s.client.unregisterSession(s)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.stream.CloseSend()
+	s.stop()
+	s.cancel()
+	return err
+}
+
+// snapshot returns the registry contents in a stable order. Callers must hold s.mu.
+func (s *MarketDataSession) snapshot() []Subscription {
+	subs := make([]Subscription, 0, len(s.active))
+	for sub := range s.active {
+		subs = append(subs, sub)
+	}
+
+	sort.Slice(subs, func(i, j int) bool {
+		a, b := subs[i], subs[j]
+		switch {
+		case a.Type != b.Type:
+			return a.Type < b.Type
+		case a.InstrumentID != b.InstrumentID:
+			return a.InstrumentID < b.InstrumentID
+		case a.Interval != b.Interval:
+			return a.Interval < b.Interval
+		case a.WaitingClose != b.WaitingClose:
+			return !a.WaitingClose
+		case a.Depth != b.Depth:
+			return a.Depth < b.Depth
+		default:
+			return a.OrderBookType < b.OrderBookType
+		}
+	})
+
+	return subs
+}
+
+// send builds and sends subscription requests for subs. Callers must hold s.mu.
+func (s *MarketDataSession) send(action investapi.SubscriptionAction, subs []Subscription) error {
+	return sendSubscriptionRequests(s.stream, buildSubscriptionRequests(action, subs, s.client.subscriptionBatchSize()))
+}
+
+// sendSubscriptionRequests sends requests in order, stopping at the first error
+func sendSubscriptionRequests(stream investapi.MarketDataStreamService_MarketDataStreamClient, requests []*investapi.MarketDataRequest) error {
+	for _, req := range requests {
+		if err := stream.Send(req); err != nil {
+			return fmt.Errorf("failed to send subscription request: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// defaultSubscriptionBatchSize is used when config.SubscriptionBatchSize is not set
+const defaultSubscriptionBatchSize = 100
+
+// subscriptionBatchSize returns the maximum number of instruments per subscription request
+func (c *RealClient) subscriptionBatchSize() int {
+	if c.config.SubscriptionBatchSize > 0 {
+		return c.config.SubscriptionBatchSize
+	}
+	return defaultSubscriptionBatchSize
+}
+
+// defaultMaxStreamSubscriptions is the API limit of subscriptions per market
+// data stream, used when config.MaxStreamSubscriptions is not set
+const defaultMaxStreamSubscriptions = 300
+
+// maxStreamSubscriptions returns the maximum number of subscriptions per session
+func (c *RealClient) maxStreamSubscriptions() int {
+	if c.config.MaxStreamSubscriptions > 0 {
+		return c.config.MaxStreamSubscriptions
+	}
+	return defaultMaxStreamSubscriptions
+}
+
+// buildSubscriptionRequests groups subscriptions into requests per data type,
+// each carrying at most batchSize instruments; zero disables batching.
+// Candle subscriptions are additionally split by the waiting close flag, which
+// applies to the whole request.
+func buildSubscriptionRequests(action investapi.SubscriptionAction, subs []Subscription, batchSize int) []*investapi.MarketDataRequest {
+	var (
+		candles    = map[bool][]*investapi.CandleInstrument{}
+		orderBooks []*investapi.OrderBookInstrument
+		trades     []*investapi.TradeInstrument
+		lastPrices []*investapi.LastPriceInstrument
+		requests   []*investapi.MarketDataRequest
+	)
+
+	for _, sub := range subs {
+		switch sub.Type {
+		case SubscriptionCandles:
+			candles[sub.WaitingClose] = append(candles[sub.WaitingClose], &investapi.CandleInstrument{
+				InstrumentId: sub.InstrumentID,
+				Interval:     sub.Interval,
+			})
+		case SubscriptionOrderBook:
+			orderBooks = append(orderBooks, &investapi.OrderBookInstrument{
+				InstrumentId:  sub.InstrumentID,
+				Depth:         sub.Depth,
+				OrderBookType: sub.OrderBookType,
+			})
+		case SubscriptionTrades:
+			trades = append(trades, &investapi.TradeInstrument{
+				InstrumentId: sub.InstrumentID,
+			})
+		case SubscriptionLastPrices:
+			lastPrices = append(lastPrices, &investapi.LastPriceInstrument{
+				InstrumentId: sub.InstrumentID,
+			})
+		}
+	}
+
+	for _, waitingClose := range []bool{false, true} {
+		for _, batch := range chunk(candles[waitingClose], batchSize) {
+			requests = append(requests, &investapi.MarketDataRequest{
+				Payload: &investapi.MarketDataRequest_SubscribeCandlesRequest{
+					SubscribeCandlesRequest: &investapi.SubscribeCandlesRequest{
+						SubscriptionAction: action,
+						Instruments:        batch,
+						WaitingClose:       waitingClose,
+					},
+				},
+			})
+		}
+	}
+
+	for _, batch := range chunk(orderBooks, batchSize) {
+		requests = append(requests, &investapi.MarketDataRequest{
+			Payload: &investapi.MarketDataRequest_SubscribeOrderBookRequest{
+				SubscribeOrderBookRequest: &investapi.SubscribeOrderBookRequest{
+					SubscriptionAction: action,
+					Instruments:        batch,
+				},
+			},
+		})
+	}
+
+	for _, batch := range chunk(trades, batchSize) {
+		requests = append(requests, &investapi.MarketDataRequest{
+			Payload: &investapi.MarketDataRequest_SubscribeTradesRequest{
+				SubscribeTradesRequest: &investapi.SubscribeTradesRequest{
+					SubscriptionAction: action,
+					Instruments:        batch,
+				},
+			},
+		})
+	}
+
+	for _, batch := range chunk(lastPrices, batchSize) {
+		requests = append(requests, &investapi.MarketDataRequest{
+			Payload: &investapi.MarketDataRequest_SubscribeLastPriceRequest{
+				SubscribeLastPriceRequest: &investapi.SubscribeLastPriceRequest{
+					SubscriptionAction: action,
+					Instruments:        batch,
+				},
+			},
+		})
+	}
+
+	return requests
+}
+
+// chunk splits items into consecutive batches of at most size elements.
+// A size of zero or less returns all items as a single batch.
+func chunk[T any](items []T, size int) [][]T {
+	if len(items) == 0 {
+		return nil
+	}
+	if size <= 0 || len(items) <= size {
+		return [][]T{items}
+	}
+
+	batches := make([][]T, 0, (len(items)+size-1)/size)
+	for size < len(items) {
+		batches = append(batches, items[:size:size])
+		items = items[size:]
+	}
+	return append(batches, items)
+}
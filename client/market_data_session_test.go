@@ -0,0 +1,378 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/buurzx/tinkoff-go/config"
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func candles(figi string, interval investapi.SubscriptionInterval) Subscription {
+	return Subscription{Type: SubscriptionCandles, InstrumentID: figi, Interval: interval}
+}
+
+const (
+	oneMinute   = investapi.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE
+	fiveMinutes = investapi.SubscriptionInterval_SUBSCRIPTION_INTERVAL_FIVE_MINUTES
+)
+
+func TestMarketDataSessionConcurrentSubscribe(t *testing.T) {
+	session, streams := newTestSession(nil)
+
+	const goroutines = 16
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			figi := fmt.Sprintf("FIGI%02d", i)
+			if err := session.Subscribe(candles(figi, oneMinute)); err != nil {
+				t.Errorf("Subscribe(%s): %v", figi, err)
+			}
+			_ = session.ActiveSubscriptions()
+		}(i)
+	}
+	wg.Wait()
+
+	if got := len(session.ActiveSubscriptions()); got != goroutines {
+		t.Fatalf("active subscriptions = %d, want %d", got, goroutines)
+	}
+	if got := subscribedInstruments(streams.stream(0).requests()); got != goroutines {
+		t.Fatalf("subscribed instruments = %d, want %d", got, goroutines)
+	}
+}
+
+func TestMarketDataSessionRegistry(t *testing.T) {
+	tests := []struct {
+		name        string
+		subscribe   []Subscription
+		unsubscribe []Subscription
+		wantActive  []Subscription
+	}{
+		{
+			name:       "two intervals of one instrument",
+			subscribe:  []Subscription{candles("BBG1", oneMinute), candles("BBG1", fiveMinutes)},
+			wantActive: []Subscription{candles("BBG1", oneMinute), candles("BBG1", fiveMinutes)},
+		},
+		{
+			name:        "unsubscribe one interval",
+			subscribe:   []Subscription{candles("BBG1", oneMinute), candles("BBG1", fiveMinutes)},
+			unsubscribe: []Subscription{candles("BBG1", oneMinute)},
+			wantActive:  []Subscription{candles("BBG1", fiveMinutes)},
+		},
+		{
+			name:        "parameterless unsubscribe removes all intervals",
+			subscribe:   []Subscription{candles("BBG1", oneMinute), candles("BBG1", fiveMinutes), candles("BBG2", oneMinute)},
+			unsubscribe: []Subscription{{Type: SubscriptionCandles, InstrumentID: "BBG1"}},
+			wantActive:  []Subscription{candles("BBG2", oneMinute)},
+		},
+		{
+			name: "two order book depths",
+			subscribe: []Subscription{
+				{Type: SubscriptionOrderBook, InstrumentID: "BBG1", Depth: 10},
+				{Type: SubscriptionOrderBook, InstrumentID: "BBG1", Depth: 50},
+			},
+			wantActive: []Subscription{
+				{Type: SubscriptionOrderBook, InstrumentID: "BBG1", Depth: 10},
+				{Type: SubscriptionOrderBook, InstrumentID: "BBG1", Depth: 50},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, _ := newTestSession(nil)
+
+			if err := session.Subscribe(tt.subscribe...); err != nil {
+				t.Fatalf("Subscribe: %v", err)
+			}
+			if len(tt.unsubscribe) > 0 {
+				if err := session.Unsubscribe(tt.unsubscribe...); err != nil {
+					t.Fatalf("Unsubscribe: %v", err)
+				}
+			}
+
+			assertSubscriptions(t, session.ActiveSubscriptions(), tt.wantActive)
+		})
+	}
+}
+
+func TestMarketDataSessionReconnectReplaysEverySubscription(t *testing.T) {
+	session, streams := newTestSession(nil)
+
+	subs := []Subscription{candles("BBG1", oneMinute), candles("BBG1", fiveMinutes)}
+	if err := session.Subscribe(subs...); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if err := session.Reconnect(); err != nil {
+		t.Fatalf("Reconnect: %v", err)
+	}
+
+	replayed := streams.stream(1).requests()
+	if len(replayed) != 1 {
+		t.Fatalf("replayed requests = %d, want 1", len(replayed))
+	}
+	instruments := replayed[0].GetSubscribeCandlesRequest().GetInstruments()
+	if len(instruments) != 2 || instruments[0].Interval != oneMinute || instruments[1].Interval != fiveMinutes {
+		t.Fatalf("replayed candles = %v, want 1m and 5m", instruments)
+	}
+}
+
+func assertSubscriptions(t *testing.T, got, want []Subscription) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("subscriptions = %+v, want %+v", got, want)
+	}
+	wanted := make(map[Subscription]bool, len(want))
+	for _, sub := range want {
+		wanted[sub] = true
+	}
+	for _, sub := range got {
+		if !wanted[sub] {
+			t.Fatalf("unexpected subscription %+v in %+v", sub, got)
+		}
+	}
+}
+
+func TestMarketDataSessionSkipsDuplicates(t *testing.T) {
+	session, streams := newTestSession(nil)
+
+	for i := 0; i < 2; i++ {
+		if err := session.Subscribe(candles("BBG1", oneMinute)); err != nil {
+			t.Fatalf("Subscribe #%d: %v", i+1, err)
+		}
+	}
+	if err := session.Subscribe(candles("BBG1", oneMinute), candles("BBG1", oneMinute)); err != nil {
+		t.Fatalf("Subscribe with duplicates: %v", err)
+	}
+
+	if got := len(streams.stream(0).requests()); got != 1 {
+		t.Fatalf("subscribe requests = %d, want 1", got)
+	}
+}
+
+func TestMarketDataSessionSubscriptionLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int
+		subs    []Subscription
+		wantErr bool
+	}{
+		{
+			name: "default limit reached",
+			subs: figiCandles(defaultMaxStreamSubscriptions, oneMinute),
+		},
+		{
+			name:    "301st subscription",
+			subs:    figiCandles(defaultMaxStreamSubscriptions+1, oneMinute),
+			wantErr: true,
+		},
+		{
+			name:    "second interval counts",
+			limit:   3,
+			subs:    append(figiCandles(2, oneMinute), figiCandles(2, fiveMinutes)...),
+			wantErr: true,
+		},
+		{
+			name:  "configured limit",
+			limit: 4,
+			subs:  append(figiCandles(2, oneMinute), figiCandles(2, fiveMinutes)...),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{MaxStreamSubscriptions: tt.limit}
+			session, streams := newTestSession(cfg)
+
+			var err error
+			for _, sub := range tt.subs {
+				if err = session.Subscribe(sub); err != nil {
+					break
+				}
+			}
+
+			if tt.wantErr != errors.Is(err, ErrSubscriptionLimit) {
+				t.Fatalf("err = %v, want ErrSubscriptionLimit: %v", err, tt.wantErr)
+			}
+
+			want := len(tt.subs)
+			if tt.wantErr {
+				want--
+			}
+			if got := len(session.ActiveSubscriptions()); got != want {
+				t.Fatalf("active subscriptions = %d, want %d", got, want)
+			}
+			if got := subscribedInstruments(streams.stream(0).requests()); got != want {
+				t.Fatalf("subscribed instruments = %d, want %d", got, want)
+			}
+		})
+	}
+}
+
+func figiCandles(n int, interval investapi.SubscriptionInterval) []Subscription {
+	subs := make([]Subscription, n)
+	for i := range subs {
+		subs[i] = candles(fmt.Sprintf("FIGI%03d", i), interval)
+	}
+	return subs
+}
+
+func TestMarketDataSessionBatchesRequests(t *testing.T) {
+	tests := []struct {
+		name        string
+		batchSize   int
+		instruments int
+		wantBatches []int
+	}{
+		{name: "250 instruments by default", instruments: 250, wantBatches: []int{100, 100, 50}},
+		{name: "configured batch size", batchSize: 120, instruments: 250, wantBatches: []int{120, 120, 10}},
+		{name: "single batch", instruments: 100, wantBatches: []int{100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, streams := newTestSession(&config.Config{SubscriptionBatchSize: tt.batchSize})
+			subs := figiCandles(tt.instruments, oneMinute)
+
+			if err := session.Subscribe(subs...); err != nil {
+				t.Fatalf("Subscribe: %v", err)
+			}
+			if err := session.Unsubscribe(subs...); err != nil {
+				t.Fatalf("Unsubscribe: %v", err)
+			}
+
+			requests := streams.stream(0).requests()
+			if len(requests) != 2*len(tt.wantBatches) {
+				t.Fatalf("requests = %d, want %d subscribe and %d unsubscribe",
+					len(requests), len(tt.wantBatches), len(tt.wantBatches))
+			}
+			for i, req := range requests {
+				action := investapi.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE
+				if i >= len(tt.wantBatches) {
+					action = investapi.SubscriptionAction_SUBSCRIPTION_ACTION_UNSUBSCRIBE
+				}
+				candlesReq := req.GetSubscribeCandlesRequest()
+				want := tt.wantBatches[i%len(tt.wantBatches)]
+				if candlesReq.GetSubscriptionAction() != action || len(candlesReq.GetInstruments()) != want {
+					t.Fatalf("request %d = %v with %d instruments, want %v with %d",
+						i, candlesReq.GetSubscriptionAction(), len(candlesReq.GetInstruments()), action, want)
+				}
+			}
+			if got := len(session.ActiveSubscriptions()); got != 0 {
+				t.Fatalf("active subscriptions = %d, want 0", got)
+			}
+		})
+	}
+}
+
+func TestMarketDataSessionOrderBookType(t *testing.T) {
+	tests := []struct {
+		name     string
+		bookType investapi.OrderBookType
+	}{
+		{name: "exchange", bookType: investapi.OrderBookType_ORDERBOOK_TYPE_EXCHANGE},
+		{name: "dealer", bookType: investapi.OrderBookType_ORDERBOOK_TYPE_DEALER},
+		{name: "unspecified", bookType: investapi.OrderBookType_ORDERBOOK_TYPE_UNSPECIFIED},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, streams := newTestSession(nil)
+
+			sub := Subscription{Type: SubscriptionOrderBook, InstrumentID: "BBG1", Depth: 20, OrderBookType: tt.bookType}
+			if err := session.Subscribe(sub); err != nil {
+				t.Fatalf("Subscribe: %v", err)
+			}
+			if err := session.Reconnect(); err != nil {
+				t.Fatalf("Reconnect: %v", err)
+			}
+
+			for i := 0; i < 2; i++ {
+				requests := streams.stream(i).requests()
+				if len(requests) != 1 {
+					t.Fatalf("stream %d requests = %d, want 1", i, len(requests))
+				}
+				instruments := requests[0].GetSubscribeOrderBookRequest().GetInstruments()
+				if len(instruments) != 1 || instruments[0].OrderBookType != tt.bookType || instruments[0].Depth != 20 {
+					t.Fatalf("stream %d order books = %v, want depth 20 of type %v", i, instruments, tt.bookType)
+				}
+			}
+		})
+	}
+}
+
+func TestMarketDataSessionLimitAfterUnsubscribe(t *testing.T) {
+	session, streams := newTestSession(&config.Config{MaxStreamSubscriptions: 2})
+
+	if err := session.Subscribe(figiCandles(2, oneMinute)...); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	// A batch over the limit is rejected whole
+	batch := []Subscription{candles("BBG1", oneMinute), candles("BBG2", oneMinute)}
+	if err := session.Subscribe(batch...); !errors.Is(err, ErrSubscriptionLimit) {
+		t.Fatalf("err = %v, want ErrSubscriptionLimit", err)
+	}
+	if got := len(streams.stream(0).requests()); got != 1 {
+		t.Fatalf("requests after the rejected batch = %d, want 1", got)
+	}
+
+	if err := session.Unsubscribe(candles("FIGI000", oneMinute)); err != nil {
+		t.Fatalf("Unsubscribe: %v", err)
+	}
+	if err := session.Subscribe(candles("BBG1", oneMinute)); err != nil {
+		t.Fatalf("Subscribe after Unsubscribe: %v", err)
+	}
+	assertSubscriptions(t, session.ActiveSubscriptions(), []Subscription{candles("FIGI001", oneMinute), candles("BBG1", oneMinute)})
+}
+
+func TestMarketDataSessionCancelsStreams(t *testing.T) {
+	tests := []struct {
+		name string
+		// end finishes the session; the first stream must be done after it
+		end func(s *MarketDataSession) error
+		// replaced is set when end opens a new stream
+		replaced bool
+	}{
+		{name: "Reconnect", end: (*MarketDataSession).Reconnect, replaced: true},
+		{name: "Close", end: (*MarketDataSession).Close},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, streams := newTestSession(nil)
+			first := streams.stream(0)
+
+			if err := first.Context().Err(); err != nil {
+				t.Fatalf("stream context done before %s: %v", tt.name, err)
+			}
+			if err := tt.end(session); err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			if first.Context().Err() == nil {
+				t.Fatalf("stream context not done after %s", tt.name)
+			}
+			if _, err := first.Recv(); !errors.Is(err, context.Canceled) {
+				t.Fatalf("Recv after %s = %v, want context.Canceled", tt.name, err)
+			}
+
+			if tt.replaced {
+				if err := streams.stream(1).Context().Err(); err != nil {
+					t.Fatalf("new stream context done after Reconnect: %v", err)
+				}
+				if err := session.Close(); err != nil {
+					t.Fatalf("Close: %v", err)
+				}
+				if streams.stream(1).Context().Err() == nil {
+					t.Fatal("new stream context not done after Close")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestGetClosePrices(t *testing.T) {
+	tests := []struct {
+		name         string
+		instruments  []string
+		wantRequests int
+	}{
+		{name: "several instruments", instruments: []string{"BBG1", "BBG2", "uid-3"}, wantRequests: 1},
+		{name: "no instruments", instruments: nil, wantRequests: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			marketData := &fakeMarketDataClient{}
+			c.marketDataClient = marketData
+
+			if _, err := c.GetClosePrices(context.Background(), tt.instruments); err != nil {
+				t.Fatalf("GetClosePrices: %v", err)
+			}
+
+			requests := marketData.recorded()
+			if len(requests) != tt.wantRequests {
+				t.Fatalf("requests = %d, want %d", len(requests), tt.wantRequests)
+			}
+			if tt.wantRequests == 0 {
+				return
+			}
+
+			sent := requests[0].(*investapi.GetClosePricesRequest).Instruments
+			if len(sent) != len(tt.instruments) {
+				t.Fatalf("requested instruments = %v, want %v", sent, tt.instruments)
+			}
+			for i, instrument := range sent {
+				if instrument.InstrumentId != tt.instruments[i] {
+					t.Fatalf("instrument %d = %q, want %q", i, instrument.InstrumentId, tt.instruments[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// MaxLots holds the limits of a GetMaxLots response as plain values.
+// Limits missing from the response are zero.
+type MaxLots struct {
+	Currency string
+
+	// Own funds
+	BuyMoney      float64
+	BuyLots       int64
+	BuyMarketLots int64
+	SellLots      int64
+
+	// Including margin
+	BuyMarginMoney      float64
+	BuyMarginLots       int64
+	BuyMarginMarketLots int64
+	SellMarginLots      int64
+}
+
+// MaxLotsFromResponse flattens a GetMaxLots response; a nil response gives
+// zero limits
+func MaxLotsFromResponse(resp *investapi.GetMaxLotsResponse) MaxLots {
+	return MaxLots{
+		Currency: NormalizeCurrency(resp.GetCurrency()),
+
+		BuyMoney:      quotationToFloat(resp.GetBuyLimits().GetBuyMoneyAmount()),
+		BuyLots:       resp.GetBuyLimits().GetBuyMaxLots(),
+		BuyMarketLots: resp.GetBuyLimits().GetBuyMaxMarketLots(),
+		SellLots:      resp.GetSellLimits().GetSellMaxLots(),
+
+		BuyMarginMoney:      quotationToFloat(resp.GetBuyMarginLimits().GetBuyMoneyAmount()),
+		BuyMarginLots:       resp.GetBuyMarginLimits().GetBuyMaxLots(),
+		BuyMarginMarketLots: resp.GetBuyMarginLimits().GetBuyMaxMarketLots(),
+		SellMarginLots:      resp.GetSellMarginLimits().GetSellMaxLots(),
+	}
+}
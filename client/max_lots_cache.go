@@ -0,0 +1,78 @@
+package client
+
+import (
+	"time"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// maxLotsKey identifies a cached GetMaxLots result
+type maxLotsKey struct {
+	accountID    string
+	instrumentID string
+	hasPrice     bool
+	priceNanos   int64
+}
+
+type maxLotsEntry struct {
+	resp      *investapi.GetMaxLotsResponse
+	fetchedAt time.Time
+}
+
+func newMaxLotsKey(accountID, instrumentID string, price *float64) maxLotsKey {
+	key := maxLotsKey{
+		accountID:    accountID,
+		instrumentID: instrumentID,
+	}
+	if price != nil {
+		key.hasPrice = true
+		key.priceNanos = quotationToNanos(floatToQuotation(*price))
+	}
+	return key
+}
+
+// cachedMaxLots returns a cached result younger than config.MaxLotsCacheTTL
+func (c *RealClient) cachedMaxLots(key maxLotsKey) (*investapi.GetMaxLotsResponse, bool) {
+	if c.config.MaxLotsCacheTTL <= 0 {
+		return nil, false
+	}
+
+	c.maxLotsMu.Lock()
+	defer c.maxLotsMu.Unlock()
+
+	entry, ok := c.maxLots[key]
+	if !ok || time.Since(entry.fetchedAt) >= c.config.MaxLotsCacheTTL {
+		return nil, false
+	}
+	return entry.resp, true
+}
+
+func (c *RealClient) storeMaxLots(key maxLotsKey, resp *investapi.GetMaxLotsResponse) {
+	if c.config.MaxLotsCacheTTL <= 0 {
+		return
+	}
+
+	c.maxLotsMu.Lock()
+	defer c.maxLotsMu.Unlock()
+
+	if c.maxLots == nil {
+		c.maxLots = make(map[maxLotsKey]maxLotsEntry)
+	}
+	c.maxLots[key] = maxLotsEntry{
+		resp:      resp,
+		fetchedAt: time.Now(),
+	}
+}
+
+// invalidateMaxLots drops cached results of the account after an order
+// changed its buying power
+func (c *RealClient) invalidateMaxLots(accountID string) {
+	c.maxLotsMu.Lock()
+	defer c.maxLotsMu.Unlock()
+
+	for key := range c.maxLots {
+		if key.accountID == accountID {
+			delete(c.maxLots, key)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/buurzx/tinkoff-go/config"
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestMaxLotsCache(t *testing.T) {
+	price, otherPrice := 100.0, 101.0
+
+	tests := []struct {
+		name      string
+		ttl       time.Duration
+		second    *float64
+		postOrder bool
+		wantCalls int
+	}{
+		{name: "cache disabled", second: &price, wantCalls: 2},
+		{name: "cache hit", ttl: time.Minute, second: &price, wantCalls: 1},
+		{name: "different price", ttl: time.Minute, second: &otherPrice, wantCalls: 2},
+		{name: "market price", ttl: time.Minute, second: nil, wantCalls: 2},
+		{name: "invalidated by an order", ttl: time.Minute, second: &price, postOrder: true, wantCalls: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(&config.Config{MaxLotsCacheTTL: tt.ttl})
+			orders := &fakeOrdersClient{}
+			c.ordersClient = orders
+			ctx := context.Background()
+
+			if _, err := c.GetMaxLots(ctx, "acc", "BBG1", &price); err != nil {
+				t.Fatalf("GetMaxLots: %v", err)
+			}
+			if tt.postOrder {
+				if _, err := c.PostOrder(ctx, &investapi.PostOrderRequest{AccountId: "acc", InstrumentId: "BBG1", Quantity: 1}); err != nil {
+					t.Fatalf("PostOrder: %v", err)
+				}
+			}
+			if _, err := c.GetMaxLots(ctx, "acc", "BBG1", tt.second); err != nil {
+				t.Fatalf("GetMaxLots: %v", err)
+			}
+
+			calls := 0
+			for _, req := range orders.recorded() {
+				if _, ok := req.(*investapi.GetMaxLotsRequest); ok {
+					calls++
+				}
+			}
+			if calls != tt.wantCalls {
+				t.Fatalf("GetMaxLots API calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"testing"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestMaxLotsFromResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *investapi.GetMaxLotsResponse
+		want MaxLots
+	}{
+		{
+			name: "all limits",
+			resp: &investapi.GetMaxLotsResponse{
+				Currency: "RUB",
+				BuyLimits: &investapi.GetMaxLotsResponse_BuyLimitsView{
+					BuyMoneyAmount:   &investapi.Quotation{Units: 10000, Nano: 500000000},
+					BuyMaxLots:       40,
+					BuyMaxMarketLots: 38,
+				},
+				BuyMarginLimits: &investapi.GetMaxLotsResponse_BuyLimitsView{
+					BuyMoneyAmount:   &investapi.Quotation{Units: 25000},
+					BuyMaxLots:       100,
+					BuyMaxMarketLots: 95,
+				},
+				SellLimits:       &investapi.GetMaxLotsResponse_SellLimitsView{SellMaxLots: 12},
+				SellMarginLimits: &investapi.GetMaxLotsResponse_SellLimitsView{SellMaxLots: 30},
+			},
+			want: MaxLots{
+				Currency:            "rub",
+				BuyMoney:            10000.5,
+				BuyLots:             40,
+				BuyMarketLots:       38,
+				SellLots:            12,
+				BuyMarginMoney:      25000,
+				BuyMarginLots:       100,
+				BuyMarginMarketLots: 95,
+				SellMarginLots:      30,
+			},
+		},
+		{
+			name: "own funds only",
+			resp: &investapi.GetMaxLotsResponse{
+				Currency:   "usd",
+				BuyLimits:  &investapi.GetMaxLotsResponse_BuyLimitsView{BuyMoneyAmount: &investapi.Quotation{Units: 15}, BuyMaxLots: 1},
+				SellLimits: &investapi.GetMaxLotsResponse_SellLimitsView{SellMaxLots: 2},
+			},
+			want: MaxLots{Currency: "usd", BuyMoney: 15, BuyLots: 1, SellLots: 2},
+		},
+		{name: "nil response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MaxLotsFromResponse(tt.resp); got != tt.want {
+				t.Fatalf("MaxLotsFromResponse = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package client
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// NewQuotationRounded converts a float to a Quotation, rounding the fractional
+// part to the nearest nano. Plain truncation turns values such as 0.07 into
+// 0.069999999 because of binary float error.
+func NewQuotationRounded(value float64) *investapi.Quotation {
+	units := math.Trunc(value)
+	nano := math.Round((value - units) * 1e9)
+
+	// Rounding may carry into the units part, e.g. 0.9999999999 -> 1.0
+	if nano >= 1e9 {
+		units++
+		nano -= 1e9
+	} else if nano <= -1e9 {
+		units--
+		nano += 1e9
+	}
+
+	return &investapi.Quotation{
+		Units: int64(units),
+		Nano:  int32(nano),
+	}
+}
+
+// nanosToMoneyValue converts an exact number of nano units to a MoneyValue
+func nanosToMoneyValue(nanos int64, currency string) *investapi.MoneyValue {
+	return &investapi.MoneyValue{
+		Currency: currency,
+		Units:    nanos / 1e9,
+		Nano:     int32(nanos % 1e9),
+	}
+}
+
+// quotationToNanos converts a quotation to an exact number of nano units
+func quotationToNanos(q *investapi.Quotation) int64 {
+	if q == nil {
+		return 0
+	}
+	return q.Units*1e9 + int64(q.Nano)
+}
+
+// moneyValueToNanos converts a money value to an exact number of nano units
+func moneyValueToNanos(m *investapi.MoneyValue) int64 {
+	if m == nil {
+		return 0
+	}
+	return m.Units*1e9 + int64(m.Nano)
+}
+
+// nanosToQuotation converts an exact number of nano units to a Quotation
+func nanosToQuotation(nanos int64) *investapi.Quotation {
+	return &investapi.Quotation{
+		Units: nanos / 1e9,
+		Nano:  int32(nanos % 1e9),
+	}
+}
+
+// NormalizeCurrency returns the currency code in the lowercase form used by the
+// API, so "RUB", "rub" and " Rub " compare equal
+func NormalizeCurrency(currency string) string {
+	return strings.ToLower(strings.TrimSpace(currency))
+}
+
+// NormalizedCurrency returns the normalized currency code of m, or "" for nil
+func NormalizedCurrency(m *investapi.MoneyValue) string {
+	if m == nil {
+		return ""
+	}
+	return NormalizeCurrency(m.Currency)
+}
+
+// QuotationEqual reports whether two quotations hold the same value. Two nil
+// quotations are equal; a nil and a non-nil one are not. A malformed quotation
+// (see ValidateQuotation) is not equal to anything.
+func QuotationEqual(a, b *investapi.Quotation) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if ValidateQuotation(a) != nil || ValidateQuotation(b) != nil {
+		return false
+	}
+	return quotationToNanos(a) == quotationToNanos(b)
+}
+
+// MoneyValueEqual reports whether two money values hold the same amount in the
+// same currency, comparing currency codes case-insensitively. Two nil values
+// are equal; a nil and a non-nil one are not. A malformed value is not equal to
+// anything.
+func MoneyValueEqual(a, b *investapi.MoneyValue) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if ValidateMoneyValue(a) != nil || ValidateMoneyValue(b) != nil {
+		return false
+	}
+	return NormalizedCurrency(a) == NormalizedCurrency(b) && moneyValueToNanos(a) == moneyValueToNanos(b)
+}
+
+// ValidateQuotation checks that units and nano share the same sign and that
+// nano is less than one unit. A value like {Units: 1, Nano: -500000000} is
+// malformed and would silently convert to 0.5.
+func ValidateQuotation(q *investapi.Quotation) error {
+	if q == nil {
+		return nil
+	}
+	return validateParts(q.Units, q.Nano)
+}
+
+// ValidateMoneyValue applies the ValidateQuotation rules to a money value
+func ValidateMoneyValue(m *investapi.MoneyValue) error {
+	if m == nil {
+		return nil
+	}
+	return validateParts(m.Units, m.Nano)
+}
+
+func validateParts(units int64, nano int32) error {
+	if nano <= -1e9 || nano >= 1e9 {
+		return fmt.Errorf("nano %d is out of range", nano)
+	}
+	if (units > 0 && nano < 0) || (units < 0 && nano > 0) {
+		return fmt.Errorf("units %d and nano %d have different signs", units, nano)
+	}
+	return nil
+}
+
+// PercentChange returns the change from one quotation to another in percent,
+// e.g. 5 for 100 -> 105. It returns 0 when from is nil or zero, or when either
+// quotation is malformed.
+func PercentChange(from, to *investapi.Quotation) float64 {
+	if ValidateQuotation(from) != nil || ValidateQuotation(to) != nil {
+		return 0
+	}
+	return percentChangeNanos(quotationToNanos(from), quotationToNanos(to))
+}
+
+// MoneyPercentChange returns the change from one money value to another in
+// percent. Both values must be well formed and in the same currency.
+func MoneyPercentChange(from, to *investapi.MoneyValue) (float64, error) {
+	if from == nil || to == nil {
+		return 0, nil
+	}
+	if err := ValidateMoneyValue(from); err != nil {
+		return 0, fmt.Errorf("invalid from value: %w", err)
+	}
+	if err := ValidateMoneyValue(to); err != nil {
+		return 0, fmt.Errorf("invalid to value: %w", err)
+	}
+	if NormalizedCurrency(from) != NormalizedCurrency(to) {
+		return 0, fmt.Errorf("currency mismatch: %s and %s", from.Currency, to.Currency)
+	}
+	return percentChangeNanos(moneyValueToNanos(from), moneyValueToNanos(to)), nil
+}
+
+// percentChangeNanos divides the exact nano difference by the base, so the
+// only rounding happens in the final float division
+func percentChangeNanos(from, to int64) float64 {
+	if from == 0 {
+		return 0
+	}
+	return float64(to-from) / math.Abs(float64(from)) * 100
+}
+
+// QuotationDivInt divides q by n exactly in nano units, rounding the last nano
+// half away from zero, e.g. 100 / 3 = 33.333333333. It returns nil when n is 0
+// or q is malformed.
+func QuotationDivInt(q *investapi.Quotation, n int64) *investapi.Quotation {
+	if q == nil || n == 0 || ValidateQuotation(q) != nil {
+		return nil
+	}
+	return nanosToQuotation(divRoundNanos(quotationToNanos(q), n))
+}
+
+// MoneyValueDivInt divides m by n like QuotationDivInt, keeping the currency
+func MoneyValueDivInt(m *investapi.MoneyValue, n int64) *investapi.MoneyValue {
+	if m == nil || n == 0 || ValidateMoneyValue(m) != nil {
+		return nil
+	}
+	return nanosToMoneyValue(divRoundNanos(moneyValueToNanos(m), n), m.Currency)
+}
+
+// divRoundNanos divides nanos by a non-zero n, rounding half away from zero
+func divRoundNanos(nanos, n int64) int64 {
+	quo, rem := nanos/n, nanos%n
+	if rem != 0 && abs64(rem) >= abs64(n)-abs64(rem) {
+		if (nanos < 0) != (n < 0) {
+			quo--
+		} else {
+			quo++
+		}
+	}
+	return quo
+}
+
+// SumByCurrency sums money values exactly per normalized currency code,
+// e.g. the Money balances of GetPositions. Nil and malformed values are
+// skipped.
+func SumByCurrency(values []*investapi.MoneyValue) map[string]*investapi.MoneyValue {
+	nanos := make(map[string]int64)
+	for _, value := range values {
+		if value == nil || ValidateMoneyValue(value) != nil {
+			continue
+		}
+		nanos[NormalizedCurrency(value)] += moneyValueToNanos(value)
+	}
+
+	totals := make(map[string]*investapi.MoneyValue, len(nanos))
+	for currency, amount := range nanos {
+		totals[currency] = nanosToMoneyValue(amount, currency)
+	}
+	return totals
+}
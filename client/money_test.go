@@ -0,0 +1,309 @@
+package client
+
+import (
+	"math"
+	"testing"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestNewQuotationRounded(t *testing.T) {
+	tests := []struct {
+		name  string
+		value float64
+		want  *investapi.Quotation
+	}{
+		{name: "binary float error", value: 0.07, want: &investapi.Quotation{Units: 0, Nano: 70000000}},
+		{name: "units and fraction", value: 123.45, want: &investapi.Quotation{Units: 123, Nano: 450000000}},
+		{name: "carry into units", value: 0.9999999999, want: &investapi.Quotation{Units: 1, Nano: 0}},
+		{name: "negative carry", value: -0.9999999999, want: &investapi.Quotation{Units: -1, Nano: 0}},
+		{name: "negative fraction", value: -1.1, want: &investapi.Quotation{Units: -1, Nano: -100000000}},
+		{name: "zero", value: 0, want: &investapi.Quotation{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewQuotationRounded(tt.value)
+			if got.GetUnits() != tt.want.GetUnits() || got.GetNano() != tt.want.GetNano() {
+				t.Fatalf("NewQuotationRounded(%v) = %d.%09d, want %d.%09d",
+					tt.value, got.GetUnits(), got.GetNano(), tt.want.GetUnits(), tt.want.GetNano())
+			}
+		})
+	}
+}
+
+func TestNormalizedCurrency(t *testing.T) {
+	tests := []struct {
+		name  string
+		value *investapi.MoneyValue
+		want  string
+	}{
+		{name: "upper case", value: &investapi.MoneyValue{Currency: "RUB"}, want: "rub"},
+		{name: "mixed case with spaces", value: &investapi.MoneyValue{Currency: " Usd "}, want: "usd"},
+		{name: "already normalized", value: &investapi.MoneyValue{Currency: "cny"}, want: "cny"},
+		{name: "nil value", value: nil, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizedCurrency(tt.value); got != tt.want {
+				t.Fatalf("NormalizedCurrency = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateQuotation(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   *investapi.Quotation
+		wantErr bool
+	}{
+		{name: "positive", value: &investapi.Quotation{Units: 1, Nano: 500000000}},
+		{name: "negative", value: &investapi.Quotation{Units: -1, Nano: -500000000}},
+		{name: "negative fraction only", value: &investapi.Quotation{Nano: -500000000}},
+		{name: "nil", value: nil},
+		{name: "mixed signs", value: &investapi.Quotation{Units: 1, Nano: -500000000}, wantErr: true},
+		{name: "negative units positive nano", value: &investapi.Quotation{Units: -1, Nano: 500000000}, wantErr: true},
+		{name: "nano of a whole unit", value: &investapi.Quotation{Nano: 1000000000}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateQuotation(tt.value); (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateQuotation = %v, want error: %v", err, tt.wantErr)
+			}
+
+			var money *investapi.MoneyValue
+			if tt.value != nil {
+				money = &investapi.MoneyValue{Currency: "rub", Units: tt.value.Units, Nano: tt.value.Nano}
+			}
+			if err := ValidateMoneyValue(money); (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateMoneyValue = %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPercentChange(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to float64
+		want     float64
+	}{
+		{name: "rise", from: 100, to: 105, want: 5},
+		{name: "fall", from: 200, to: 150, want: -25},
+		{name: "negative base", from: -50, to: -25, want: 50},
+		{name: "zero base", from: 0, to: 10, want: 0},
+		{name: "no change", from: 0.07, to: 0.07, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PercentChange(NewQuotationRounded(tt.from), NewQuotationRounded(tt.to))
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("PercentChange(%v, %v) = %v, want %v", tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+
+	if got := PercentChange(nil, NewQuotationRounded(1)); got != 0 {
+		t.Fatalf("PercentChange from nil = %v, want 0", got)
+	}
+	if got := PercentChange(&investapi.Quotation{Units: 1, Nano: -500000000}, NewQuotationRounded(1)); got != 0 {
+		t.Fatalf("PercentChange from malformed = %v, want 0", got)
+	}
+}
+
+func TestMoneyPercentChange(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to *investapi.MoneyValue
+		want     float64
+		wantErr  bool
+	}{
+		{
+			name: "same currency in different case",
+			from: &investapi.MoneyValue{Currency: "RUB", Units: 100},
+			to:   &investapi.MoneyValue{Currency: "rub", Units: 110},
+			want: 10,
+		},
+		{
+			name:    "currency mismatch",
+			from:    &investapi.MoneyValue{Currency: "rub", Units: 100},
+			to:      &investapi.MoneyValue{Currency: "usd", Units: 110},
+			wantErr: true,
+		},
+		{name: "nil value", from: nil, to: &investapi.MoneyValue{Currency: "rub", Units: 1}},
+		{
+			name:    "malformed from",
+			from:    &investapi.MoneyValue{Currency: "rub", Units: 1, Nano: -500000000},
+			to:      &investapi.MoneyValue{Currency: "rub", Units: 1},
+			wantErr: true,
+		},
+		{
+			name:    "malformed to",
+			from:    &investapi.MoneyValue{Currency: "rub", Units: 1},
+			to:      &investapi.MoneyValue{Currency: "rub", Nano: 1000000000},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MoneyPercentChange(tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %v", err, tt.wantErr)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("MoneyPercentChange = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuotationDivInt(t *testing.T) {
+	tests := []struct {
+		name string
+		q    *investapi.Quotation
+		n    int64
+		want *investapi.Quotation
+	}{
+		{name: "100 by 3", q: &investapi.Quotation{Units: 100}, n: 3, want: &investapi.Quotation{Units: 33, Nano: 333333333}},
+		{name: "200 by 3 rounds up", q: &investapi.Quotation{Units: 200}, n: 3, want: &investapi.Quotation{Units: 66, Nano: 666666667}},
+		{name: "negative", q: &investapi.Quotation{Units: -200}, n: 3, want: &investapi.Quotation{Units: -66, Nano: -666666667}},
+		{name: "negative divisor", q: &investapi.Quotation{Units: 200}, n: -3, want: &investapi.Quotation{Units: -66, Nano: -666666667}},
+		{name: "half nano away from zero", q: &investapi.Quotation{Nano: 1}, n: 2, want: &investapi.Quotation{Nano: 1}},
+		{name: "exact", q: &investapi.Quotation{Units: 10, Nano: 500000000}, n: 3, want: &investapi.Quotation{Units: 3, Nano: 500000000}},
+		{name: "by zero", q: &investapi.Quotation{Units: 1}, n: 0},
+		{name: "malformed", q: &investapi.Quotation{Units: 1, Nano: -500000000}, n: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := QuotationDivInt(tt.q, tt.n); !QuotationEqual(got, tt.want) {
+				t.Fatalf("QuotationDivInt = %v, want %v", got, tt.want)
+			}
+
+			m := MoneyValueDivInt(&investapi.MoneyValue{Currency: "rub", Units: tt.q.Units, Nano: tt.q.Nano}, tt.n)
+			if tt.want == nil {
+				if m != nil {
+					t.Fatalf("MoneyValueDivInt = %v, want nil", m)
+				}
+				return
+			}
+			want := &investapi.MoneyValue{Currency: "rub", Units: tt.want.Units, Nano: tt.want.Nano}
+			if !MoneyValueEqual(m, want) {
+				t.Fatalf("MoneyValueDivInt = %v, want %v", m, want)
+			}
+		})
+	}
+}
+
+func TestSumByCurrency(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []*investapi.MoneyValue
+		want   map[string]*investapi.MoneyValue
+	}{
+		{
+			name: "rub and usd",
+			values: []*investapi.MoneyValue{
+				{Currency: "rub", Units: 100, Nano: 500000000},
+				{Currency: "USD", Units: 10, Nano: 100000000},
+				{Currency: "RUB", Units: 50, Nano: 700000000},
+				{Currency: "usd", Units: 0, Nano: 200000000},
+			},
+			want: map[string]*investapi.MoneyValue{
+				"rub": {Currency: "rub", Units: 151, Nano: 200000000},
+				"usd": {Currency: "usd", Units: 10, Nano: 300000000},
+			},
+		},
+		{
+			name: "negative balance",
+			values: []*investapi.MoneyValue{
+				{Currency: "rub", Units: 10},
+				{Currency: "rub", Units: -15, Nano: -500000000},
+			},
+			want: map[string]*investapi.MoneyValue{
+				"rub": {Currency: "rub", Units: -5, Nano: -500000000},
+			},
+		},
+		{
+			name:   "nil values skipped",
+			values: []*investapi.MoneyValue{nil, {Currency: "usd", Units: 1}, nil},
+			want:   map[string]*investapi.MoneyValue{"usd": {Currency: "usd", Units: 1}},
+		},
+		{
+			name: "malformed values skipped",
+			values: []*investapi.MoneyValue{
+				{Currency: "rub", Units: 1, Nano: -500000000},
+				{Currency: "rub", Units: 2},
+			},
+			want: map[string]*investapi.MoneyValue{"rub": {Currency: "rub", Units: 2}},
+		},
+		{name: "empty", want: map[string]*investapi.MoneyValue{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SumByCurrency(tt.values)
+			if len(got) != len(tt.want) {
+				t.Fatalf("SumByCurrency = %v, want %v", got, tt.want)
+			}
+			for currency, want := range tt.want {
+				if !MoneyValueEqual(got[currency], want) {
+					t.Fatalf("total %s = %v, want %v", currency, got[currency], want)
+				}
+			}
+		})
+	}
+}
+
+func TestQuotationEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b *investapi.Quotation
+		want bool
+	}{
+		{name: "equal", a: &investapi.Quotation{Units: 1, Nano: 500000000}, b: &investapi.Quotation{Units: 1, Nano: 500000000}, want: true},
+		{name: "different nanos", a: &investapi.Quotation{Units: 1, Nano: 500000000}, b: &investapi.Quotation{Units: 1, Nano: 500000001}},
+		{name: "different units", a: &investapi.Quotation{Units: 1}, b: &investapi.Quotation{Units: 2}},
+		{name: "both nil", want: true},
+		{name: "one nil", a: &investapi.Quotation{}},
+		{name: "other nil", b: &investapi.Quotation{}},
+		{name: "malformed", a: &investapi.Quotation{Units: 1, Nano: -500000000}, b: &investapi.Quotation{Units: 1, Nano: -500000000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := QuotationEqual(tt.a, tt.b); got != tt.want {
+				t.Fatalf("QuotationEqual(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyValueEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b *investapi.MoneyValue
+		want bool
+	}{
+		{name: "equal", a: &investapi.MoneyValue{Currency: "rub", Units: 10}, b: &investapi.MoneyValue{Currency: "rub", Units: 10}, want: true},
+		{name: "currency case", a: &investapi.MoneyValue{Currency: "RUB", Units: 10}, b: &investapi.MoneyValue{Currency: "rub", Units: 10}, want: true},
+		{name: "different currency", a: &investapi.MoneyValue{Currency: "usd", Units: 10}, b: &investapi.MoneyValue{Currency: "rub", Units: 10}},
+		{name: "different amount", a: &investapi.MoneyValue{Currency: "rub", Units: 10}, b: &investapi.MoneyValue{Currency: "rub", Units: 10, Nano: 1}},
+		{name: "both nil", want: true},
+		{name: "one nil", a: &investapi.MoneyValue{Currency: "rub"}},
+		{name: "malformed", a: &investapi.MoneyValue{Currency: "rub", Nano: 1000000000}, b: &investapi.MoneyValue{Currency: "rub", Nano: 1000000000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MoneyValueEqual(tt.a, tt.b); got != tt.want {
+				t.Fatalf("MoneyValueEqual(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// StreamPortfolio streams portfolio updates of the accounts and calls handler
+// for every message carrying a portfolio. Pings are skipped and failed account
+// subscriptions are logged. A stream interrupted by a transient error is
+// reopened with the config.StreamRetry backoff. It blocks until ctx is
+// cancelled, the client is closed or the stream fails for good.
+func (c *RealClient) StreamPortfolio(ctx context.Context, accountIDs []string, handler func(*investapi.PortfolioStreamResponse)) error {
+	open := func(ctx context.Context) (grpc.ServerStreamingClient[investapi.PortfolioStreamResponse], error) {
+		return c.startPortfolioStream(ctx, accountIDs)
+	}
+
+	return runResumableStream(ctx, c, "portfolio", open, func(resp *investapi.PortfolioStreamResponse) bool {
+		switch payload := resp.Payload.(type) {
+		case *investapi.PortfolioStreamResponse_Portfolio:
+			return true
+		case *investapi.PortfolioStreamResponse_Subscriptions:
+			for _, account := range payload.Subscriptions.GetAccounts() {
+				if account.SubscriptionStatus != investapi.PortfolioSubscriptionStatus_PORTFOLIO_SUBSCRIPTION_STATUS_SUCCESS {
+					log.Printf("❌ Portfolio subscription failed for account %s: %s", account.AccountId, account.SubscriptionStatus)
+				}
+			}
+		}
+		return false
+	}, handler)
+}
+
+// StreamPositions streams position changes of the accounts and calls handler
+// for every message carrying position data. It handles pings, subscription
+// results and reconnects the same way as StreamPortfolio.
+func (c *RealClient) StreamPositions(ctx context.Context, accountIDs []string, handler func(*investapi.PositionsStreamResponse)) error {
+	open := func(ctx context.Context) (grpc.ServerStreamingClient[investapi.PositionsStreamResponse], error) {
+		return c.startPositionsStream(ctx, accountIDs)
+	}
+
+	return runResumableStream(ctx, c, "positions", open, func(resp *investapi.PositionsStreamResponse) bool {
+		switch payload := resp.Payload.(type) {
+		case *investapi.PositionsStreamResponse_Position, *investapi.PositionsStreamResponse_InitialPositions:
+			return true
+		case *investapi.PositionsStreamResponse_Subscriptions:
+			for _, account := range payload.Subscriptions.GetAccounts() {
+				if account.SubscriptionStatus != investapi.PositionsAccountSubscriptionStatus_POSITIONS_SUBSCRIPTION_STATUS_SUCCESS {
+					log.Printf("❌ Positions subscription failed for account %s: %s", account.AccountId, account.SubscriptionStatus)
+				}
+			}
+		}
+		return false
+	}, handler)
+}
+
+// runResumableStream receives from streams returned by open and passes the
+// messages accepted by filter to handler through a dispatcher. Transient
+// failures reopen the stream with the stream retry backoff; the attempt
+// counter is reset by every received message.
+func runResumableStream[T any](
+	ctx context.Context,
+	c *RealClient,
+	name string,
+	open func(context.Context) (grpc.ServerStreamingClient[T], error),
+	filter func(*T) bool,
+	handler func(*T),
+) error {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Tie the stream to both the caller's and the client's lifetime
+	stop := context.AfterFunc(c.ctx, cancel)
+	defer stop()
+
+	// Run the handler behind a bounded buffer so it cannot stall Recv
+	d := newDispatcher(c.config.StreamBufferSize, &c.streamCounters, handler)
+	defer d.close(streamCtx)
+
+	retry := c.streamRetryConfig()
+	attempt := 0
+
+	for {
+		stream, err := open(streamCtx)
+		for err == nil {
+			var resp *T
+			if resp, err = stream.Recv(); err == nil {
+				attempt = 0
+				if filter(resp) {
+					d.push(resp)
+				}
+			}
+		}
+
+		if IsStreamClosed(err) || streamCtx.Err() != nil {
+			return ctx.Err()
+		}
+		if !IsStreamRecoverable(err) || attempt >= retry.MaxRetries {
+			return fmt.Errorf("%s stream error: %w", name, err)
+		}
+
+		log.Printf("⚠️ %s stream interrupted, reopening: %v", name, err)
+		if sleepContext(streamCtx, retry.CalculateBackoff(attempt)) != nil {
+			return ctx.Err()
+		}
+		attempt++
+	}
+}
+
+func (c *RealClient) startPortfolioStream(ctx context.Context, accountIDs []string) (investapi.OperationsStreamService_PortfolioStreamClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.connected {
+		return nil, ErrNotConnected
+	}
+
+	// Create context with authorization
+	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
+
+	req := &investapi.PortfolioStreamRequest{
+		Accounts: accountIDs,
+	}
+
+	stream, err := c.operationsStreamClient.PortfolioStream(ctxWithAuth, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start portfolio stream: %w", err)
+	}
+
+	log.Printf("🚀 Portfolio stream started for %d accounts", len(accountIDs))
+	return stream, nil
+}
+
+func (c *RealClient) startPositionsStream(ctx context.Context, accountIDs []string) (investapi.OperationsStreamService_PositionsStreamClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.connected {
+		return nil, ErrNotConnected
+	}
+
+	// Create context with authorization
+	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
+
+	req := &investapi.PositionsStreamRequest{
+		Accounts: accountIDs,
+	}
+
+	stream, err := c.operationsStreamClient.PositionsStream(ctxWithAuth, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start positions stream: %w", err)
+	}
+
+	log.Printf("🚀 Positions stream started for %d accounts", len(accountIDs))
+	return stream, nil
+}
@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/buurzx/tinkoff-go/config"
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// portfolioStream returns a stream with a portfolio of every account, each
+// preceded by a ping, that ends with end. A stream without accounts receives
+// nothing, so it does not reset the retry attempts.
+func portfolioStream(end error, accounts ...string) *fakePortfolioStream {
+	stream := newFakeStream[investapi.PortfolioStreamRequest, investapi.PortfolioStreamResponse](nil)
+	for _, account := range accounts {
+		stream.recv <- &investapi.PortfolioStreamResponse{
+			Payload: &investapi.PortfolioStreamResponse_Ping{Ping: &investapi.Ping{}},
+		}
+		stream.recv <- &investapi.PortfolioStreamResponse{
+			Payload: &investapi.PortfolioStreamResponse_Portfolio{Portfolio: &investapi.PortfolioResponse{AccountId: account}},
+		}
+	}
+	stream.fail(end)
+	return stream
+}
+
+func TestStreamPortfolio(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "connection reset")
+
+	tests := []struct {
+		name         string
+		streams      []*fakePortfolioStream
+		wantAccounts int
+		wantOpens    int
+		wantErr      bool
+	}{
+		{
+			name:         "closed by the server",
+			streams:      []*fakePortfolioStream{portfolioStream(io.EOF, "acc")},
+			wantAccounts: 1,
+			wantOpens:    1,
+		},
+		{
+			name: "reopened after a transient error",
+			streams: []*fakePortfolioStream{
+				portfolioStream(unavailable, "acc"),
+				portfolioStream(io.EOF, "acc"),
+			},
+			wantAccounts: 2,
+			wantOpens:    2,
+		},
+		{
+			name:         "permanent error",
+			streams:      []*fakePortfolioStream{portfolioStream(status.Error(codes.PermissionDenied, "denied"), "acc")},
+			wantAccounts: 1,
+			wantOpens:    1,
+			wantErr:      true,
+		},
+		{
+			name: "retries exhausted",
+			streams: []*fakePortfolioStream{
+				portfolioStream(unavailable),
+				portfolioStream(unavailable),
+				portfolioStream(unavailable),
+			},
+			wantOpens: 3,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(&config.Config{StreamRetry: config.StreamRetryConfig{
+				MaxAttempts: 2,
+				BaseDelay:   time.Millisecond,
+				MaxDelay:    time.Millisecond,
+			}})
+			operations := &fakeOperationsStreamClient{portfolios: tt.streams}
+			c.operationsStreamClient = operations
+
+			var portfolios int
+			err := c.StreamPortfolio(context.Background(), []string{"acc"}, func(resp *investapi.PortfolioStreamResponse) {
+				if resp.GetPortfolio().GetAccountId() == "acc" {
+					portfolios++
+				}
+			})
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %v", err, tt.wantErr)
+			}
+			if portfolios != tt.wantAccounts {
+				t.Fatalf("handled %d portfolios, want %d", portfolios, tt.wantAccounts)
+			}
+			if got := len(operations.recorded()); got != tt.wantOpens {
+				t.Fatalf("stream opens = %d, want %d", got, tt.wantOpens)
+			}
+		})
+	}
+}
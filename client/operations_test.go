@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func operationPages() map[string]*investapi.GetOperationsByCursorResponse {
+	return map[string]*investapi.GetOperationsByCursorResponse{
+		"": {
+			HasNext:    true,
+			NextCursor: "page2",
+			Items:      []*investapi.OperationItem{{Id: "op1"}, {Id: "op2"}},
+		},
+		"page2": {
+			Items: []*investapi.OperationItem{{Id: "op3"}},
+		},
+	}
+}
+
+func TestIterateOperations(t *testing.T) {
+	tests := []struct {
+		name      string
+		stopAfter int
+		wantIDs   []string
+		wantPages int
+	}{
+		{name: "all pages", wantIDs: []string{"op1", "op2", "op3"}, wantPages: 2},
+		{name: "stop on the first page", stopAfter: 1, wantIDs: []string{"op1"}, wantPages: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			operations := &fakeOperationsClient{pages: operationPages()}
+			c.operationsClient = operations
+
+			var ids []string
+			err := c.IterateOperations(context.Background(), "acc", time.Now().AddDate(0, -1, 0), time.Now(), func(item *investapi.OperationItem) bool {
+				ids = append(ids, item.Id)
+				return tt.stopAfter == 0 || len(ids) < tt.stopAfter
+			})
+			if err != nil {
+				t.Fatalf("IterateOperations: %v", err)
+			}
+
+			if len(ids) != len(tt.wantIDs) {
+				t.Fatalf("operations = %v, want %v", ids, tt.wantIDs)
+			}
+			for i := range ids {
+				if ids[i] != tt.wantIDs[i] {
+					t.Fatalf("operations = %v, want %v", ids, tt.wantIDs)
+				}
+			}
+
+			requests := operations.recorded()
+			if len(requests) != tt.wantPages {
+				t.Fatalf("pages requested = %d, want %d", len(requests), tt.wantPages)
+			}
+			if tt.wantPages > 1 {
+				if cursor := requests[1].(*investapi.GetOperationsByCursorRequest).GetCursor(); cursor != "page2" {
+					t.Fatalf("second page cursor = %q, want page2", cursor)
+				}
+			}
+		})
+	}
+}
+
+func TestIterateOperationsFiltered(t *testing.T) {
+	dividend := investapi.OperationType_OPERATION_TYPE_DIVIDEND
+	minAmount, maxAmount := 50.0, 150.0
+
+	tests := []struct {
+		name           string
+		filter         OperationsFilter
+		wantIDs        []string
+		wantInstrument string
+	}{
+		{name: "no filter", wantIDs: []string{"op1", "op2", "op3"}},
+		{
+			name:           "type and instrument sent to the API",
+			filter:         OperationsFilter{Types: []investapi.OperationType{dividend}, InstrumentID: "BBG1"},
+			wantIDs:        []string{"op1", "op2", "op3"},
+			wantInstrument: "BBG1",
+		},
+		{
+			name:    "absolute amount bounds applied locally",
+			filter:  OperationsFilter{MinAmount: &minAmount, MaxAmount: &maxAmount},
+			wantIDs: []string{"op1", "op3"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			operations := &fakeOperationsClient{pages: map[string]*investapi.GetOperationsByCursorResponse{
+				"": {Items: []*investapi.OperationItem{
+					{Id: "op1", Payment: &investapi.MoneyValue{Currency: "rub", Units: -100}},
+					{Id: "op2", Payment: &investapi.MoneyValue{Currency: "rub", Units: 10}},
+					{Id: "op3", Payment: &investapi.MoneyValue{Currency: "rub", Units: 150}},
+				}},
+			}}
+			c.operationsClient = operations
+
+			var ids []string
+			err := c.IterateOperationsFiltered(context.Background(), "acc", time.Now().AddDate(0, -1, 0), time.Now(), tt.filter, func(item *investapi.OperationItem) bool {
+				ids = append(ids, item.Id)
+				return true
+			})
+			if err != nil {
+				t.Fatalf("IterateOperationsFiltered: %v", err)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.wantIDs) {
+				t.Fatalf("operations = %v, want %v", ids, tt.wantIDs)
+			}
+
+			req := operations.recorded()[0].(*investapi.GetOperationsByCursorRequest)
+			if req.GetInstrumentId() != tt.wantInstrument {
+				t.Fatalf("request instrument = %q, want %q", req.GetInstrumentId(), tt.wantInstrument)
+			}
+			if fmt.Sprint(req.OperationTypes) != fmt.Sprint(tt.filter.Types) {
+				t.Fatalf("request types = %v, want %v", req.OperationTypes, tt.filter.Types)
+			}
+		})
+	}
+}
+
+func TestGetPortfolioValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		currency  investapi.PortfolioRequest_CurrencyRequest
+		portfolio *investapi.PortfolioResponse
+		want      *investapi.MoneyValue
+	}{
+		{
+			name:     "rub total",
+			currency: investapi.PortfolioRequest_RUB,
+			portfolio: &investapi.PortfolioResponse{
+				TotalAmountShares:    &investapi.MoneyValue{Currency: "rub", Units: 900},
+				TotalAmountPortfolio: &investapi.MoneyValue{Currency: "rub", Units: 1000, Nano: 500000000},
+			},
+			want: &investapi.MoneyValue{Currency: "rub", Units: 1000, Nano: 500000000},
+		},
+		{
+			name:      "usd total",
+			currency:  investapi.PortfolioRequest_USD,
+			portfolio: &investapi.PortfolioResponse{TotalAmountPortfolio: &investapi.MoneyValue{Currency: "usd", Units: 11}},
+			want:      &investapi.MoneyValue{Currency: "usd", Units: 11},
+		},
+		{name: "empty portfolio", currency: investapi.PortfolioRequest_RUB, portfolio: &investapi.PortfolioResponse{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			operations := &fakeOperationsClient{portfolio: tt.portfolio}
+			c.operationsClient = operations
+
+			got, err := c.GetPortfolioValue(context.Background(), "acc", tt.currency)
+			if err != nil {
+				t.Fatalf("GetPortfolioValue: %v", err)
+			}
+			if !MoneyValueEqual(got, tt.want) {
+				t.Fatalf("GetPortfolioValue = %v, want %v", got, tt.want)
+			}
+
+			req := operations.recorded()[0].(*investapi.PortfolioRequest)
+			if req.AccountId != "acc" || req.GetCurrency() != tt.currency {
+				t.Fatalf("request = %v, want account acc in %s", req, tt.currency)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// OrderBuilder assembles a PostOrderRequest step by step.
+// New builders place a market order priced in currency with a fresh OrderId;
+// call Buy or Sell and Lots before Build.
+type OrderBuilder struct {
+	req *investapi.PostOrderRequest
+	err error
+	// instrument is set by NewOrderBuilderForInstrument
+	instrument *investapi.Instrument
+}
+
+// NewOrderBuilder starts an order for the instrument (FIGI or UID) on the account
+func NewOrderBuilder(accountID, instrumentID string) *OrderBuilder {
+	return &OrderBuilder{
+		req: &investapi.PostOrderRequest{
+			AccountId:    accountID,
+			InstrumentId: instrumentID,
+			OrderType:    investapi.OrderType_ORDER_TYPE_MARKET,
+			PriceType:    investapi.PriceType_PRICE_TYPE_CURRENCY,
+			OrderId:      NewIdempotencyKey(),
+		},
+	}
+}
+
+// NewOrderBuilderForInstrument starts an order for the instrument and keeps its
+// trading rules: Shares uses its lot size, Limit rounds the price to its
+// minimum price increment and Build checks it with
+// ValidateOrderAgainstInstrument and rejects instruments not tradeable
+// through the API. An instrument without a lot size, e.g. one built from a
+// search result, is fetched once with client so that later calls need no
+// lookups. Set the account with Account.
+func NewOrderBuilderForInstrument(ctx context.Context, client *RealClient, instrument *investapi.Instrument) *OrderBuilder {
+	b := NewOrderBuilder("", "")
+	if instrument == nil {
+		b.err = fmt.Errorf("instrument is required")
+		return b
+	}
+
+	instrumentID := instrument.Uid
+	if instrumentID == "" {
+		instrumentID = instrument.Figi
+	}
+	b.req.InstrumentId = instrumentID
+
+	if instrument.Lot <= 0 {
+		full, err := fetchInstrumentRules(ctx, client, instrument)
+		if err != nil {
+			b.err = fmt.Errorf("failed to load trading rules for %s: %w", instrumentID, err)
+			return b
+		}
+		instrument = full
+	}
+
+	b.instrument = instrument
+	return b
+}
+
+// fetchInstrumentRules loads the full instrument by UID, or by FIGI when the
+// UID is not known
+func fetchInstrumentRules(ctx context.Context, client *RealClient, instrument *investapi.Instrument) (*investapi.Instrument, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client is required to look up the lot size")
+	}
+	if instrument.Uid != "" {
+		return client.GetInstrumentByUID(ctx, instrument.Uid)
+	}
+	return client.GetInstrumentByFIGI(ctx, instrument.Figi)
+}
+
+// Account sets the account the order is placed on
+func (b *OrderBuilder) Account(accountID string) *OrderBuilder {
+	b.req.AccountId = accountID
+	return b
+}
+
+// Buy sets the order direction to buy
+func (b *OrderBuilder) Buy() *OrderBuilder {
+	b.req.Direction = investapi.OrderDirection_ORDER_DIRECTION_BUY
+	return b
+}
+
+// Sell sets the order direction to sell
+func (b *OrderBuilder) Sell() *OrderBuilder {
+	b.req.Direction = investapi.OrderDirection_ORDER_DIRECTION_SELL
+	return b
+}
+
+// Lots sets the order quantity in lots
+func (b *OrderBuilder) Lots(lots int64) *OrderBuilder {
+	b.req.Quantity = lots
+	return b
+}
+
+// Shares sets the order quantity to n shares, converting it to lots with the
+// lot size of the instrument given to NewOrderBuilderForInstrument. Build
+// fails for builders without an instrument and if n is not a whole number of
+// lots.
+func (b *OrderBuilder) Shares(n int64) *OrderBuilder {
+	if b.instrument == nil {
+		b.err = fmt.Errorf("instrument is required to convert shares to lots")
+		return b
+	}
+
+	lots, err := LotsForShares(n, b.instrument.Lot)
+	if err != nil {
+		b.err = fmt.Errorf("invalid quantity for %s: %w", b.instrument.Ticker, err)
+		return b
+	}
+
+	b.req.Quantity = lots
+	return b
+}
+
+// Market makes the order a market order
+func (b *OrderBuilder) Market() *OrderBuilder {
+	b.req.OrderType = investapi.OrderType_ORDER_TYPE_MARKET
+	b.req.Price = nil
+	return b
+}
+
+// Limit makes the order a limit order at price. Builders created with
+// NewOrderBuilderForInstrument round the price to the nearest multiple of the
+// instrument's minimum price increment.
+func (b *OrderBuilder) Limit(price float64) *OrderBuilder {
+	b.req.OrderType = investapi.OrderType_ORDER_TYPE_LIMIT
+	b.req.Price = NewQuotationRounded(price)
+
+	if step := quotationToNanos(b.instrument.GetMinPriceIncrement()); step > 0 {
+		b.req.Price = nanosToQuotation(divRoundNanos(quotationToNanos(b.req.Price), step) * step)
+	}
+	return b
+}
+
+// PriceType sets how the limit price is expressed. Futures and bonds may be
+// priced in points with PRICE_TYPE_POINT; the default is PRICE_TYPE_CURRENCY.
+func (b *OrderBuilder) PriceType(priceType investapi.PriceType) *OrderBuilder {
+	b.req.PriceType = priceType
+	return b
+}
+
+// TimeInForce sets the limit order lifetime
+func (b *OrderBuilder) TimeInForce(tif investapi.TimeInForceType) *OrderBuilder {
+	b.req.TimeInForce = tif
+	return b
+}
+
+// OrderID overrides the generated idempotency key
+func (b *OrderBuilder) OrderID(orderID string) *OrderBuilder {
+	b.req.OrderId = orderID
+	return b
+}
+
+// ConfirmMarginTrade acknowledges that the order may open an uncovered
+// (margin) position. Without it such orders are rejected with
+// ErrMarginConfirmationRequired.
+func (b *OrderBuilder) ConfirmMarginTrade(confirm bool) *OrderBuilder {
+	b.req.ConfirmMarginTrade = confirm
+	return b
+}
+
+// Build validates and returns the request. The builder must not be reused
+// after Build.
+func (b *OrderBuilder) Build() (*investapi.PostOrderRequest, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.req.AccountId == "" {
+		return nil, fmt.Errorf("account ID is required")
+	}
+	if b.req.InstrumentId == "" {
+		return nil, fmt.Errorf("instrument ID is required")
+	}
+	if b.req.Direction == investapi.OrderDirection_ORDER_DIRECTION_UNSPECIFIED {
+		return nil, fmt.Errorf("order direction is required")
+	}
+	if b.req.Quantity <= 0 {
+		return nil, fmt.Errorf("order quantity must be a positive number of lots, got %d", b.req.Quantity)
+	}
+	if b.req.OrderType == investapi.OrderType_ORDER_TYPE_LIMIT && b.req.Price == nil {
+		return nil, fmt.Errorf("limit order requires a price")
+	}
+	if _, ok := investapi.PriceType_name[int32(b.req.PriceType)]; !ok {
+		return nil, fmt.Errorf("unknown price type %d", b.req.PriceType)
+	}
+
+	if b.instrument != nil {
+		if !b.instrument.ApiTradeAvailableFlag {
+			return nil, fmt.Errorf("%s is not available for API trading", b.instrument.Ticker)
+		}
+		if err := ValidateOrderAgainstInstrument(b.req, b.instrument); err != nil {
+			return nil, err
+		}
+	}
+
+	return b.req, nil
+}
+
+// LotsForShares converts a number of shares to lots. The API counts order
+// quantities in lots, so shares must be a whole multiple of lotSize.
+func LotsForShares(shares int64, lotSize int32) (int64, error) {
+	if lotSize <= 0 {
+		return 0, fmt.Errorf("lot size must be positive, got %d", lotSize)
+	}
+	if shares%int64(lotSize) != 0 {
+		return 0, fmt.Errorf("%d shares is not a multiple of lot size %d", shares, lotSize)
+	}
+	return shares / int64(lotSize), nil
+}
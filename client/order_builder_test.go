@@ -0,0 +1,238 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestOrderBuilderPriceType(t *testing.T) {
+	instrument := func(kind investapi.InstrumentType) *investapi.Instrument {
+		return &investapi.Instrument{
+			Figi:                  "BBG1",
+			Ticker:                "TEST",
+			Lot:                   1,
+			InstrumentKind:        kind,
+			ApiTradeAvailableFlag: true,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		instrument *investapi.Instrument
+		priceType  investapi.PriceType
+		want       investapi.PriceType
+		wantErr    bool
+	}{
+		{name: "currency by default", instrument: instrument(investapi.InstrumentType_INSTRUMENT_TYPE_SHARE), want: investapi.PriceType_PRICE_TYPE_CURRENCY},
+		{
+			name:       "points for a future",
+			instrument: instrument(investapi.InstrumentType_INSTRUMENT_TYPE_FUTURES),
+			priceType:  investapi.PriceType_PRICE_TYPE_POINT,
+			want:       investapi.PriceType_PRICE_TYPE_POINT,
+		},
+		{
+			name:       "points for a bond",
+			instrument: instrument(investapi.InstrumentType_INSTRUMENT_TYPE_BOND),
+			priceType:  investapi.PriceType_PRICE_TYPE_POINT,
+			want:       investapi.PriceType_PRICE_TYPE_POINT,
+		},
+		{
+			name:       "points for a share",
+			instrument: instrument(investapi.InstrumentType_INSTRUMENT_TYPE_SHARE),
+			priceType:  investapi.PriceType_PRICE_TYPE_POINT,
+			wantErr:    true,
+		},
+		{
+			name:       "unknown price type",
+			instrument: instrument(investapi.InstrumentType_INSTRUMENT_TYPE_FUTURES),
+			priceType:  investapi.PriceType(42),
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewOrderBuilderForInstrument(context.Background(), nil, tt.instrument).Account("acc").Buy().Lots(1).Limit(100)
+			if tt.priceType != investapi.PriceType_PRICE_TYPE_UNSPECIFIED {
+				b.PriceType(tt.priceType)
+			}
+
+			req, err := b.Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build error = %v, want error: %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			c := newTestClient(nil)
+			orders := &fakeOrdersClient{}
+			c.ordersClient = orders
+			if _, err := c.PostOrder(context.Background(), req); err != nil {
+				t.Fatalf("PostOrder: %v", err)
+			}
+			if got := orders.posted()[0].PriceType; got != tt.want {
+				t.Fatalf("sent price type = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLotsForShares(t *testing.T) {
+	tests := []struct {
+		name     string
+		shares   int64
+		lotSize  int32
+		wantLots int64
+		wantErr  bool
+	}{
+		{name: "whole lots", shares: 100, lotSize: 10, wantLots: 10},
+		{name: "lot of one", shares: 7, lotSize: 1, wantLots: 7},
+		{name: "partial lot", shares: 105, lotSize: 10, wantErr: true},
+		{name: "zero lot size", shares: 10, lotSize: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lots, err := LotsForShares(tt.shares, tt.lotSize)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %v", err, tt.wantErr)
+			}
+			if lots != tt.wantLots {
+				t.Fatalf("lots = %d, want %d", lots, tt.wantLots)
+			}
+
+			instrument := &investapi.Instrument{Figi: "BBG1", Ticker: "TEST", Lot: tt.lotSize, ApiTradeAvailableFlag: true}
+			req, err := NewOrderBuilderForInstrument(context.Background(), nil, instrument).Account("acc").Sell().Shares(tt.shares).Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build error = %v, want error: %v", err, tt.wantErr)
+			}
+			if err == nil && req.Quantity != tt.wantLots {
+				t.Fatalf("request quantity = %d, want %d", req.Quantity, tt.wantLots)
+			}
+		})
+	}
+}
+
+func TestSharesWithoutInstrument(t *testing.T) {
+	if _, err := NewOrderBuilder("acc", "BBG1").Sell().Shares(10).Build(); err == nil {
+		t.Fatal("Build succeeded without an instrument to take the lot size from")
+	}
+}
+
+func TestOrderBuilderForInstrument(t *testing.T) {
+	lotOfTen := &investapi.Instrument{
+		Uid:                   "uid-1",
+		Figi:                  "BBG1",
+		Ticker:                "TEST",
+		Lot:                   10,
+		MinPriceIncrement:     &investapi.Quotation{Nano: 50000000},
+		ApiTradeAvailableFlag: true,
+	}
+
+	tests := []struct {
+		name       string
+		instrument *investapi.Instrument
+		build      func(b *OrderBuilder) *OrderBuilder
+		wantLots   int64
+		wantPrice  *investapi.Quotation
+		wantErr    bool
+	}{
+		{
+			name:       "shares in lots of ten",
+			instrument: lotOfTen,
+			build:      func(b *OrderBuilder) *OrderBuilder { return b.Shares(50).Market() },
+			wantLots:   5,
+		},
+		{
+			name:       "limit price rounded to the increment",
+			instrument: lotOfTen,
+			build:      func(b *OrderBuilder) *OrderBuilder { return b.Shares(20).Limit(100.12) },
+			wantLots:   2,
+			wantPrice:  &investapi.Quotation{Units: 100, Nano: 100000000},
+		},
+		{
+			name:       "partial lot",
+			instrument: lotOfTen,
+			build:      func(b *OrderBuilder) *OrderBuilder { return b.Shares(15).Market() },
+			wantErr:    true,
+		},
+		{
+			name: "not tradeable through the API",
+			instrument: &investapi.Instrument{
+				Figi:   "BBG2",
+				Ticker: "OTC",
+				Lot:    10,
+			},
+			build:   func(b *OrderBuilder) *OrderBuilder { return b.Shares(10).Market() },
+			wantErr: true,
+		},
+		{
+			name:    "nil instrument",
+			build:   func(b *OrderBuilder) *OrderBuilder { return b.Lots(1).Market() },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := tt.build(NewOrderBuilderForInstrument(context.Background(), nil, tt.instrument).Account("acc").Buy()).Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build error = %v, want error: %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if req.InstrumentId != tt.instrument.Uid || req.Quantity != tt.wantLots {
+				t.Fatalf("request = %v, want %d lots of %s", req, tt.wantLots, tt.instrument.Uid)
+			}
+			if !QuotationEqual(req.Price, tt.wantPrice) {
+				t.Fatalf("price = %v, want %v", req.Price, tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestOrderBuilderForInstrumentLookup(t *testing.T) {
+	full := &investapi.Instrument{
+		Uid:                   "uid-1",
+		Figi:                  "BBG1",
+		Ticker:                "TEST",
+		Lot:                   10,
+		ApiTradeAvailableFlag: true,
+	}
+
+	tests := []struct {
+		name       string
+		instrument *investapi.Instrument
+		wantCalls  int
+		wantErr    bool
+	}{
+		{name: "complete instrument", instrument: full},
+		{name: "lot size looked up", instrument: &investapi.Instrument{Uid: "uid-1"}, wantCalls: 1},
+		{name: "unknown instrument", instrument: &investapi.Instrument{Uid: "uid-2"}, wantCalls: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			instruments := &fakeInstrumentsClient{instruments: map[string]*investapi.Instrument{"uid-1": full}}
+			c.instrumentsClient = instruments
+
+			b := NewOrderBuilderForInstrument(context.Background(), c, tt.instrument).Account("acc").Buy()
+			req, err := b.Shares(50).Limit(100).Build()
+			if got := len(instruments.recorded()); got != tt.wantCalls {
+				t.Fatalf("instrument lookups = %d, want %d", got, tt.wantCalls)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build error = %v, want error: %v", err, tt.wantErr)
+			}
+			if err == nil && req.Quantity != 5 {
+				t.Fatalf("quantity = %d lots, want 5", req.Quantity)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestEstimateOrderCost(t *testing.T) {
+	buy := investapi.OrderDirection_ORDER_DIRECTION_BUY
+
+	tests := []struct {
+		name           string
+		resp           *investapi.GetOrderPriceResponse
+		wantTotal      float64
+		wantCommission float64
+		wantCurrency   string
+		wantAccrued    *investapi.MoneyValue
+	}{
+		{
+			name: "share",
+			resp: &investapi.GetOrderPriceResponse{
+				TotalOrderAmount:   &investapi.MoneyValue{Units: 2505, Nano: 250000000, Currency: "RUB"},
+				ExecutedCommission: &investapi.MoneyValue{Units: 5, Nano: 250000000, Currency: "rub"},
+			},
+			wantTotal:      2505.25,
+			wantCommission: 5.25,
+			wantCurrency:   "rub",
+		},
+		{
+			name: "bond with accrued interest",
+			resp: &investapi.GetOrderPriceResponse{
+				TotalOrderAmount:   &investapi.MoneyValue{Units: 10120, Currency: "rub"},
+				ExecutedCommission: &investapi.MoneyValue{Units: 3, Currency: "rub"},
+				InstrumentExtra: &investapi.GetOrderPriceResponse_ExtraBond_{ExtraBond: &investapi.GetOrderPriceResponse_ExtraBond{
+					AciValue: &investapi.MoneyValue{Units: 120, Nano: 500000000, Currency: "rub"},
+				}},
+			},
+			wantTotal:      10120,
+			wantCommission: 3,
+			wantCurrency:   "rub",
+			wantAccrued:    &investapi.MoneyValue{Units: 120, Nano: 500000000, Currency: "rub"},
+		},
+		{
+			name:         "no amounts",
+			resp:         &investapi.GetOrderPriceResponse{},
+			wantCurrency: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			orders := &fakeOrdersClient{orderPrice: tt.resp}
+			c.ordersClient = orders
+
+			total, commission, currency, err := c.EstimateOrderCost(context.Background(), "acc", "BBG1", buy, 10, 250)
+			if err != nil {
+				t.Fatalf("EstimateOrderCost: %v", err)
+			}
+			if total != tt.wantTotal || commission != tt.wantCommission || currency != tt.wantCurrency {
+				t.Fatalf("EstimateOrderCost = %v, %v, %q, want %v, %v, %q",
+					total, commission, currency, tt.wantTotal, tt.wantCommission, tt.wantCurrency)
+			}
+
+			estimate, err := c.EstimateOrderCostDetailed(context.Background(), "acc", "BBG1", buy, 10, 250)
+			if err != nil {
+				t.Fatalf("EstimateOrderCostDetailed: %v", err)
+			}
+			if estimate.Total != tt.wantTotal || estimate.Commission != tt.wantCommission || estimate.Currency != tt.wantCurrency {
+				t.Fatalf("EstimateOrderCostDetailed = %+v, want %v, %v, %q", estimate, tt.wantTotal, tt.wantCommission, tt.wantCurrency)
+			}
+			if !MoneyValueEqual(estimate.AccruedInterest, tt.wantAccrued) {
+				t.Fatalf("accrued interest = %v, want %v", estimate.AccruedInterest, tt.wantAccrued)
+			}
+
+			req := orders.recorded()[0].(*investapi.GetOrderPriceRequest)
+			if req.Quantity != 10 || req.Direction != buy || quotationToFloat(req.Price) != 250 {
+				t.Fatalf("request = %v, want 10 lots to buy at 250", req)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// IsFilled reports whether the order is fully executed
+func IsFilled(os *investapi.OrderState) bool {
+	if os == nil {
+		return false
+	}
+	if os.ExecutionReportStatus == investapi.OrderExecutionReportStatus_EXECUTION_REPORT_STATUS_FILL {
+		return true
+	}
+	return os.LotsRequested > 0 && os.LotsExecuted >= os.LotsRequested
+}
+
+// IsPartiallyFilled reports whether some, but not all, of the requested lots are executed
+func IsPartiallyFilled(os *investapi.OrderState) bool {
+	if os == nil || IsFilled(os) {
+		return false
+	}
+	if os.ExecutionReportStatus == investapi.OrderExecutionReportStatus_EXECUTION_REPORT_STATUS_PARTIALLYFILL {
+		return true
+	}
+	return os.LotsExecuted > 0 && os.LotsExecuted < os.LotsRequested
+}
+
+// RemainingLots returns the number of requested lots that are not executed yet
+func RemainingLots(os *investapi.OrderState) int64 {
+	if os == nil {
+		return 0
+	}
+	remaining := os.LotsRequested - os.LotsExecuted
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// FillRatio returns the executed share of the order in the range [0, 1]
+func FillRatio(os *investapi.OrderState) float64 {
+	if os == nil || os.LotsRequested <= 0 {
+		return 0.0
+	}
+	ratio := float64(os.LotsExecuted) / float64(os.LotsRequested)
+	if ratio > 1 {
+		return 1.0
+	}
+	return ratio
+}
+
+// ExecutionStatus is a simplified order execution status
+type ExecutionStatus int
+
+const (
+	ExecutionStatusUnknown ExecutionStatus = iota
+	ExecutionStatusNew
+	ExecutionStatusPartiallyFilled
+	ExecutionStatusFilled
+	ExecutionStatusRejected
+	ExecutionStatusCancelled
+)
+
+// ExecutionStatusFromProto converts an API execution report status
+func ExecutionStatusFromProto(status investapi.OrderExecutionReportStatus) ExecutionStatus {
+	switch status {
+	case investapi.OrderExecutionReportStatus_EXECUTION_REPORT_STATUS_NEW:
+		return ExecutionStatusNew
+	case investapi.OrderExecutionReportStatus_EXECUTION_REPORT_STATUS_PARTIALLYFILL:
+		return ExecutionStatusPartiallyFilled
+	case investapi.OrderExecutionReportStatus_EXECUTION_REPORT_STATUS_FILL:
+		return ExecutionStatusFilled
+	case investapi.OrderExecutionReportStatus_EXECUTION_REPORT_STATUS_REJECTED:
+		return ExecutionStatusRejected
+	case investapi.OrderExecutionReportStatus_EXECUTION_REPORT_STATUS_CANCELLED:
+		return ExecutionStatusCancelled
+	default:
+		return ExecutionStatusUnknown
+	}
+}
+
+// IsTerminal reports whether the order can get no more fills
+func (s ExecutionStatus) IsTerminal() bool {
+	return s == ExecutionStatusFilled || s == ExecutionStatusRejected || s == ExecutionStatusCancelled
+}
+
+// IsActive reports whether the order is still working on the exchange
+func (s ExecutionStatus) IsActive() bool {
+	return s == ExecutionStatusNew || s == ExecutionStatusPartiallyFilled
+}
+
+// IsRejected reports whether the order was rejected by the broker or exchange
+func (s ExecutionStatus) IsRejected() bool {
+	return s == ExecutionStatusRejected
+}
+
+// String returns a human readable status, e.g. "partially filled"
+func (s ExecutionStatus) String() string {
+	switch s {
+	case ExecutionStatusNew:
+		return "new"
+	case ExecutionStatusPartiallyFilled:
+		return "partially filled"
+	case ExecutionStatusFilled:
+		return "filled"
+	case ExecutionStatusRejected:
+		return "rejected"
+	case ExecutionStatusCancelled:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"testing"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestOrderStateFills(t *testing.T) {
+	tests := []struct {
+		name          string
+		state         *investapi.OrderState
+		wantFilled    bool
+		wantPartial   bool
+		wantRemaining int64
+		wantRatio     float64
+	}{
+		{
+			name: "fully filled",
+			state: &investapi.OrderState{
+				LotsRequested:         10,
+				LotsExecuted:          10,
+				ExecutionReportStatus: investapi.OrderExecutionReportStatus_EXECUTION_REPORT_STATUS_FILL,
+			},
+			wantFilled: true,
+			wantRatio:  1,
+		},
+		{
+			name: "half filled",
+			state: &investapi.OrderState{
+				LotsRequested:         10,
+				LotsExecuted:          5,
+				ExecutionReportStatus: investapi.OrderExecutionReportStatus_EXECUTION_REPORT_STATUS_PARTIALLYFILL,
+			},
+			wantPartial:   true,
+			wantRemaining: 5,
+			wantRatio:     0.5,
+		},
+		{
+			name: "unfilled",
+			state: &investapi.OrderState{
+				LotsRequested:         10,
+				ExecutionReportStatus: investapi.OrderExecutionReportStatus_EXECUTION_REPORT_STATUS_NEW,
+			},
+			wantRemaining: 10,
+		},
+		{
+			name:  "nil state",
+			state: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsFilled(tt.state); got != tt.wantFilled {
+				t.Errorf("IsFilled = %v, want %v", got, tt.wantFilled)
+			}
+			if got := IsPartiallyFilled(tt.state); got != tt.wantPartial {
+				t.Errorf("IsPartiallyFilled = %v, want %v", got, tt.wantPartial)
+			}
+			if got := RemainingLots(tt.state); got != tt.wantRemaining {
+				t.Errorf("RemainingLots = %d, want %d", got, tt.wantRemaining)
+			}
+			if got := FillRatio(tt.state); got != tt.wantRatio {
+				t.Errorf("FillRatio = %v, want %v", got, tt.wantRatio)
+			}
+		})
+	}
+}
+
+func TestExecutionStatus(t *testing.T) {
+	tests := []struct {
+		status       investapi.OrderExecutionReportStatus
+		want         ExecutionStatus
+		wantString   string
+		wantTerminal bool
+		wantActive   bool
+		wantRejected bool
+	}{
+		{status: investapi.OrderExecutionReportStatus_EXECUTION_REPORT_STATUS_NEW, want: ExecutionStatusNew, wantString: "new", wantActive: true},
+		{status: investapi.OrderExecutionReportStatus_EXECUTION_REPORT_STATUS_PARTIALLYFILL, want: ExecutionStatusPartiallyFilled, wantString: "partially filled", wantActive: true},
+		{status: investapi.OrderExecutionReportStatus_EXECUTION_REPORT_STATUS_FILL, want: ExecutionStatusFilled, wantString: "filled", wantTerminal: true},
+		{status: investapi.OrderExecutionReportStatus_EXECUTION_REPORT_STATUS_REJECTED, want: ExecutionStatusRejected, wantString: "rejected", wantTerminal: true, wantRejected: true},
+		{status: investapi.OrderExecutionReportStatus_EXECUTION_REPORT_STATUS_CANCELLED, want: ExecutionStatusCancelled, wantString: "cancelled", wantTerminal: true},
+		{status: investapi.OrderExecutionReportStatus_EXECUTION_REPORT_STATUS_UNSPECIFIED, want: ExecutionStatusUnknown, wantString: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status.String(), func(t *testing.T) {
+			got := ExecutionStatusFromProto(tt.status)
+			if got != tt.want {
+				t.Fatalf("ExecutionStatusFromProto = %v, want %v", got, tt.want)
+			}
+			if got.String() != tt.wantString {
+				t.Fatalf("String = %q, want %q", got.String(), tt.wantString)
+			}
+			if got.IsTerminal() != tt.wantTerminal || got.IsActive() != tt.wantActive || got.IsRejected() != tt.wantRejected {
+				t.Fatalf("terminal, active, rejected = %v, %v, %v, want %v, %v, %v",
+					got.IsTerminal(), got.IsActive(), got.IsRejected(), tt.wantTerminal, tt.wantActive, tt.wantRejected)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestOrderStateChannel(t *testing.T) {
+	errBoom := status.Error(codes.Internal, "boom")
+
+	tests := []struct {
+		name         string
+		disconnected bool
+		end          error
+		cancel       bool
+		wantStates   []string
+		wantErr      bool
+	}{
+		{name: "stream closed", end: io.EOF, wantStates: []string{"order1", "order2"}},
+		{name: "stream failed", end: errBoom, wantStates: []string{"order1", "order2"}, wantErr: true},
+		{name: "caller cancelled", cancel: true, wantStates: []string{"order1", "order2"}},
+		{name: "not connected", disconnected: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			c := newTestClient(nil)
+			c.connected = !tt.disconnected
+			stream := newFakeStream[investapi.OrderStateStreamRequest, investapi.OrderStateStreamResponse](ctx)
+			orders := &fakeOrdersStreamClient{orderStates: stream}
+			c.ordersStreamClient = orders
+
+			stream.recv <- &investapi.OrderStateStreamResponse{
+				Payload: &investapi.OrderStateStreamResponse_Ping{Ping: &investapi.Ping{}},
+			}
+			for _, id := range []string{"order1", "order2"} {
+				stream.recv <- &investapi.OrderStateStreamResponse{
+					Payload: &investapi.OrderStateStreamResponse_OrderState_{
+						OrderState: &investapi.OrderStateStreamResponse_OrderState{OrderId: id},
+					},
+				}
+			}
+			if tt.end != nil {
+				stream.fail(tt.end)
+			}
+
+			states, errs := c.OrderStateChannel(ctx, []string{"acc"})
+
+			var received []string
+			timeout := time.After(time.Second)
+			for states != nil {
+				select {
+				case state, ok := <-states:
+					if !ok {
+						states = nil
+						continue
+					}
+					received = append(received, state.OrderId)
+					if tt.cancel && len(received) == len(tt.wantStates) {
+						cancel()
+					}
+				case <-timeout:
+					t.Fatal("order state channel was not closed")
+				}
+			}
+
+			if fmt.Sprint(received) != fmt.Sprint(tt.wantStates) {
+				t.Fatalf("received %v, want %v", received, tt.wantStates)
+			}
+
+			err := <-errs
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %v", err, tt.wantErr)
+			}
+			if tt.disconnected && !errors.Is(err, ErrNotConnected) {
+				t.Fatalf("err = %v, want %v", err, ErrNotConnected)
+			}
+			if tt.end == errBoom && !errors.Is(err, errBoom) {
+				t.Fatalf("err = %v, want %v", err, errBoom)
+			}
+		})
+	}
+}
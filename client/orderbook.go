@@ -0,0 +1,43 @@
+package client
+
+import (
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// OrderBookSides is implemented by both order book messages,
+// investapi.GetOrderBookResponse and the streamed investapi.OrderBook
+type OrderBookSides interface {
+	GetBids() []*investapi.Order
+	GetAsks() []*investapi.Order
+}
+
+var (
+	_ OrderBookSides = (*investapi.GetOrderBookResponse)(nil)
+	_ OrderBookSides = (*investapi.OrderBook)(nil)
+)
+
+// PriceFromBest returns a limit price derived from the best price on the side
+// the order would join: the best bid for buys and the best ask for sells.
+// Positive offsetTicks move the price into the spread by that many ticks, so
+// 0 joins the best price and 1 improves it by one tick. The result is aligned
+// to tick when the best price is. It returns nil when the side is empty.
+func PriceFromBest(book OrderBookSides, side investapi.OrderDirection, offsetTicks int, tick *investapi.Quotation) *investapi.Quotation {
+	var levels []*investapi.Order
+	step := quotationToNanos(tick) * int64(offsetTicks)
+
+	switch side {
+	case investapi.OrderDirection_ORDER_DIRECTION_BUY:
+		levels = book.GetBids()
+	case investapi.OrderDirection_ORDER_DIRECTION_SELL:
+		levels = book.GetAsks()
+		step = -step
+	default:
+		return nil
+	}
+
+	if len(levels) == 0 || levels[0].GetPrice() == nil {
+		return nil
+	}
+
+	return nanosToQuotation(quotationToNanos(levels[0].Price) + step)
+}
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"testing"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestPriceFromBest(t *testing.T) {
+	book := &investapi.GetOrderBookResponse{
+		Bids: []*investapi.Order{{Price: NewQuotationRounded(99.5)}, {Price: NewQuotationRounded(99)}},
+		Asks: []*investapi.Order{{Price: NewQuotationRounded(100.5)}, {Price: NewQuotationRounded(101)}},
+	}
+	tick := NewQuotationRounded(0.1)
+
+	tests := []struct {
+		name   string
+		book   OrderBookSides
+		side   investapi.OrderDirection
+		offset int
+		want   *investapi.Quotation
+	}{
+		{name: "buy joins the best bid", book: book, side: buy, want: NewQuotationRounded(99.5)},
+		{name: "buy improves by two ticks", book: book, side: buy, offset: 2, want: NewQuotationRounded(99.7)},
+		{name: "sell joins the best ask", book: book, side: sell, want: NewQuotationRounded(100.5)},
+		{name: "sell improves by one tick", book: book, side: sell, offset: 1, want: NewQuotationRounded(100.4)},
+		{name: "streamed order book", book: &investapi.OrderBook{Asks: book.Asks}, side: sell, want: NewQuotationRounded(100.5)},
+		{name: "empty side", book: &investapi.OrderBook{Asks: book.Asks}, side: buy},
+		{name: "unspecified direction", book: book},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PriceFromBest(tt.book, tt.side, tt.offset, tick)
+			if !QuotationEqual(got, tt.want) {
+				t.Fatalf("PriceFromBest = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
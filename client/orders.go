@@ -0,0 +1,170 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// PostSlicedOrder splits an order into child orders of at most sliceLots lots and
+// submits them one by one, waiting interval between submissions. Each child order
+// gets its own OrderId. Remaining slices are aborted when a submission fails or ctx
+// is cancelled; responses of the slices submitted so far are returned with the error.
+func (c *RealClient) PostSlicedOrder(ctx context.Context, req *investapi.PostOrderRequest, sliceLots int64, interval time.Duration) ([]*investapi.PostOrderResponse, error) {
+	if sliceLots <= 0 {
+		return nil, fmt.Errorf("slice size must be positive, got %d", sliceLots)
+	}
+	if req.Quantity <= 0 {
+		return nil, fmt.Errorf("order quantity must be positive, got %d", req.Quantity)
+	}
+
+	var responses []*investapi.PostOrderResponse
+	for remaining := req.Quantity; remaining > 0; remaining -= sliceLots {
+		if len(responses) > 0 && interval > 0 {
+			timer := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return responses, ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			return responses, err
+		}
+
+		slice := proto.Clone(req).(*investapi.PostOrderRequest)
+		slice.Quantity = min(sliceLots, remaining)
+		slice.OrderId = NewIdempotencyKey()
+
+		resp, err := c.PostOrder(ctx, slice)
+		if err != nil {
+			return responses, fmt.Errorf("slice %d of order failed: %w", len(responses)+1, err)
+		}
+		responses = append(responses, resp)
+	}
+
+	return responses, nil
+}
+
+// EnrichedOrder is an active order with the human readable instrument name
+type EnrichedOrder struct {
+	Order  *investapi.OrderState
+	Name   string
+	Ticker string
+}
+
+// ClosePosition flattens the account's position in the instrument with a
+// market order in the opposite direction: a long position is sold and a short
+// one bought back. Only the unblocked balance is closed, in whole lots.
+// ErrNoPosition is returned when there is nothing to close.
+func (c *RealClient) ClosePosition(ctx context.Context, accountID, figi string) (*investapi.PostOrderResponse, error) {
+	positions, err := c.GetPositions(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := positionBalance(positions, figi)
+	if balance == 0 {
+		return nil, fmt.Errorf("failed to close position in %s: %w", figi, ErrNoPosition)
+	}
+
+	instrument, err := c.ResolveInstrument(ctx, figi)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve instrument %s: %w", figi, err)
+	}
+	if instrument.Lot <= 0 {
+		return nil, fmt.Errorf("invalid lot size %d for %s", instrument.Lot, figi)
+	}
+
+	lots := abs64(balance) / int64(instrument.Lot)
+	if lots == 0 {
+		return nil, fmt.Errorf("position of %d in %s is smaller than one lot of %d", balance, figi, instrument.Lot)
+	}
+
+	direction := investapi.OrderDirection_ORDER_DIRECTION_SELL
+	if balance < 0 {
+		direction = investapi.OrderDirection_ORDER_DIRECTION_BUY
+	}
+
+	return c.PostOrder(ctx, &investapi.PostOrderRequest{
+		AccountId:    accountID,
+		InstrumentId: figi,
+		Quantity:     lots,
+		Direction:    direction,
+		OrderType:    investapi.OrderType_ORDER_TYPE_MARKET,
+		OrderId:      NewIdempotencyKey(),
+	})
+}
+
+// positionBalance returns the signed unblocked quantity of the instrument in
+// securities or futures, in pieces
+func positionBalance(positions *investapi.PositionsResponse, figi string) int64 {
+	for _, security := range positions.GetSecurities() {
+		if security.Figi == figi {
+			return security.Balance
+		}
+	}
+	for _, future := range positions.GetFutures() {
+		if future.Figi == figi {
+			return future.Balance
+		}
+	}
+	return 0
+}
+
+// GetOrdersEnriched returns active orders of the account together with the name
+// and ticker of each order's instrument. Instruments are resolved with
+// ResolveInstrument, so every FIGI is looked up at most once per client.
+func (c *RealClient) GetOrdersEnriched(ctx context.Context, accountID string) ([]EnrichedOrder, error) {
+	resp, err := c.GetOrders(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := make([]EnrichedOrder, len(resp.Orders))
+	for i, order := range resp.Orders {
+		instrument, err := c.ResolveInstrument(ctx, order.Figi)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve instrument for order %s: %w", order.OrderId, err)
+		}
+
+		orders[i] = EnrichedOrder{
+			Order:  order,
+			Name:   instrument.Name,
+			Ticker: instrument.Ticker,
+		}
+	}
+
+	return orders, nil
+}
+
+// CancelAllStopOrders cancels every active stop order of the account and returns
+// the IDs of the cancelled ones. A failed cancel does not stop the remaining
+// ones; all failures are returned joined in the error.
+func (c *RealClient) CancelAllStopOrders(ctx context.Context, accountID string) ([]string, error) {
+	resp, err := c.GetStopOrders(ctx, accountID, investapi.StopOrderStatusOption_STOP_ORDER_STATUS_ACTIVE)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		cancelled []string
+		errs      []error
+	)
+	for _, stopOrder := range resp.StopOrders {
+		if _, err := c.CancelStopOrder(ctx, accountID, stopOrder.StopOrderId); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		cancelled = append(cancelled, stopOrder.StopOrderId)
+	}
+
+	return cancelled, errors.Join(errs...)
+}
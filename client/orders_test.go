@@ -0,0 +1,321 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestPostSlicedOrder(t *testing.T) {
+	errRejected := errors.New("rejected")
+
+	tests := []struct {
+		name          string
+		quantity      int64
+		sliceLots     int64
+		failOn        int
+		wantSlices    []int64
+		wantResponses int
+		wantErr       bool
+	}{
+		{
+			name:          "10 lots by 3",
+			quantity:      10,
+			sliceLots:     3,
+			wantSlices:    []int64{3, 3, 3, 1},
+			wantResponses: 4,
+		},
+		{
+			name:          "single slice",
+			quantity:      2,
+			sliceLots:     5,
+			wantSlices:    []int64{2},
+			wantResponses: 1,
+		},
+		{
+			name:          "failed slice aborts the rest",
+			quantity:      10,
+			sliceLots:     3,
+			failOn:        2,
+			wantSlices:    []int64{3, 3},
+			wantResponses: 1,
+			wantErr:       true,
+		},
+		{
+			name:      "zero slice size",
+			quantity:  10,
+			sliceLots: 0,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			orders := &fakeOrdersClient{}
+			if tt.failOn > 0 {
+				calls := 0
+				orders.postOrder = func(_ context.Context, req *investapi.PostOrderRequest) (*investapi.PostOrderResponse, error) {
+					calls++
+					if calls == tt.failOn {
+						return nil, errRejected
+					}
+					return &investapi.PostOrderResponse{OrderId: req.OrderId}, nil
+				}
+			}
+			c.ordersClient = orders
+
+			responses, err := c.PostSlicedOrder(context.Background(), &investapi.PostOrderRequest{
+				AccountId:    "acc",
+				InstrumentId: "BBG1",
+				Quantity:     tt.quantity,
+				Direction:    investapi.OrderDirection_ORDER_DIRECTION_BUY,
+				OrderType:    investapi.OrderType_ORDER_TYPE_MARKET,
+			}, tt.sliceLots, 0)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %v", err, tt.wantErr)
+			}
+			if tt.failOn > 0 && !errors.Is(err, errRejected) {
+				t.Fatalf("err = %v, want %v", err, errRejected)
+			}
+			if len(responses) != tt.wantResponses {
+				t.Fatalf("responses = %d, want %d", len(responses), tt.wantResponses)
+			}
+
+			posted := orders.posted()
+			if len(posted) != len(tt.wantSlices) {
+				t.Fatalf("submissions = %d, want %d", len(posted), len(tt.wantSlices))
+			}
+			ids := make(map[string]bool)
+			for i, req := range posted {
+				if req.Quantity != tt.wantSlices[i] {
+					t.Fatalf("slice %d quantity = %d, want %d", i+1, req.Quantity, tt.wantSlices[i])
+				}
+				if req.OrderId == "" || ids[req.OrderId] {
+					t.Fatalf("slice %d order ID %q is empty or reused", i+1, req.OrderId)
+				}
+				ids[req.OrderId] = true
+			}
+		})
+	}
+}
+
+func TestGetOrdersEnriched(t *testing.T) {
+	c := newTestClient(nil)
+	c.ordersClient = &fakeOrdersClient{activeOrders: []*investapi.OrderState{
+		{OrderId: "1", Figi: "BBG1"},
+		{OrderId: "2", Figi: "BBG2"},
+		{OrderId: "3", Figi: "BBG1"},
+	}}
+	instruments := &fakeInstrumentsClient{instruments: map[string]*investapi.Instrument{
+		"BBG1": {Figi: "BBG1", Ticker: "SBER", Name: "Sberbank"},
+		"BBG2": {Figi: "BBG2", Ticker: "GAZP", Name: "Gazprom"},
+	}}
+	c.instrumentsClient = instruments
+
+	tests := []struct {
+		name        string
+		wantLookups int
+	}{
+		{name: "first call resolves each FIGI once", wantLookups: 2},
+		{name: "second call is served from the cache", wantLookups: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders, err := c.GetOrdersEnriched(context.Background(), "acc")
+			if err != nil {
+				t.Fatalf("GetOrdersEnriched: %v", err)
+			}
+
+			want := []string{"SBER", "GAZP", "SBER"}
+			if len(orders) != len(want) {
+				t.Fatalf("orders = %d, want %d", len(orders), len(want))
+			}
+			for i, order := range orders {
+				if order.Ticker != want[i] || order.Order.OrderId != fmt.Sprint(i+1) {
+					t.Fatalf("order %d = %s %s, want %d %s", i, order.Order.OrderId, order.Ticker, i+1, want[i])
+				}
+			}
+			if got := len(instruments.recorded()); got != tt.wantLookups {
+				t.Fatalf("instrument lookups = %d, want %d", got, tt.wantLookups)
+			}
+		})
+	}
+}
+
+func TestPostOrderMarginConfirmation(t *testing.T) {
+	rejected := status.Error(codes.FailedPrecondition, marginConfirmationErrorCode)
+
+	tests := []struct {
+		name       string
+		confirm    bool
+		err        error
+		wantErr    bool
+		wantMargin bool
+	}{
+		{name: "unconfirmed margin order", err: rejected, wantErr: true, wantMargin: true},
+		{name: "confirmed order rejected for another reason", confirm: true, err: rejected, wantErr: true},
+		{name: "other error", err: status.Error(codes.Internal, "internal"), wantErr: true},
+		{name: "accepted", confirm: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			c.ordersClient = &fakeOrdersClient{postOrder: func(_ context.Context, req *investapi.PostOrderRequest) (*investapi.PostOrderResponse, error) {
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return &investapi.PostOrderResponse{OrderId: req.OrderId}, nil
+			}}
+
+			req, err := NewOrderBuilder("acc", "BBG1").Buy().Lots(1).ConfirmMarginTrade(tt.confirm).Build()
+			if err != nil {
+				t.Fatalf("Build: %v", err)
+			}
+			_, err = c.PostOrder(context.Background(), req)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %v", err, tt.wantErr)
+			}
+			if got := errors.Is(err, ErrMarginConfirmationRequired); got != tt.wantMargin {
+				t.Fatalf("err = %v, want ErrMarginConfirmationRequired: %v", err, tt.wantMargin)
+			}
+		})
+	}
+}
+
+func TestCancelAllStopOrders(t *testing.T) {
+	errNotFound := status.Error(codes.NotFound, "stop order not found")
+
+	tests := []struct {
+		name          string
+		cancelErrs    map[string]error
+		wantCancelled []string
+		wantErr       bool
+	}{
+		{name: "all cancelled", wantCancelled: []string{"s1", "s2", "s3"}},
+		{
+			name:          "failure does not stop the rest",
+			cancelErrs:    map[string]error{"s2": errNotFound},
+			wantCancelled: []string{"s1", "s3"},
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			stopOrders := &fakeStopOrdersClient{
+				stopOrders: []*investapi.StopOrder{{StopOrderId: "s1"}, {StopOrderId: "s2"}, {StopOrderId: "s3"}},
+				cancelErrs: tt.cancelErrs,
+			}
+			c.stopOrdersClient = stopOrders
+
+			cancelled, err := c.CancelAllStopOrders(context.Background(), "acc")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, errNotFound) {
+				t.Fatalf("err = %v, want %v", err, errNotFound)
+			}
+			if fmt.Sprint(cancelled) != fmt.Sprint(tt.wantCancelled) {
+				t.Fatalf("cancelled = %v, want %v", cancelled, tt.wantCancelled)
+			}
+
+			list := stopOrders.recorded()[0].(*investapi.GetStopOrdersRequest)
+			if list.Status != investapi.StopOrderStatusOption_STOP_ORDER_STATUS_ACTIVE {
+				t.Fatalf("listed status = %v, want active", list.Status)
+			}
+			if got := len(stopOrders.recorded()) - 1; got != 3 {
+				t.Fatalf("cancel requests = %d, want 3", got)
+			}
+		})
+	}
+}
+
+func TestClosePosition(t *testing.T) {
+	instruments := map[string]*investapi.Instrument{
+		"BBG1": {Figi: "BBG1", Ticker: "SHARE", Lot: 10},
+		"FUT1": {Figi: "FUT1", Ticker: "FUTURE", Lot: 1},
+	}
+
+	tests := []struct {
+		name          string
+		figi          string
+		positions     *investapi.PositionsResponse
+		wantDirection investapi.OrderDirection
+		wantLots      int64
+		wantErr       error
+	}{
+		{
+			name:          "long position",
+			figi:          "BBG1",
+			positions:     &investapi.PositionsResponse{Securities: []*investapi.PositionsSecurities{{Figi: "BBG1", Balance: 50, Blocked: 20}}},
+			wantDirection: investapi.OrderDirection_ORDER_DIRECTION_SELL,
+			wantLots:      5,
+		},
+		{
+			name:          "short position",
+			figi:          "BBG1",
+			positions:     &investapi.PositionsResponse{Securities: []*investapi.PositionsSecurities{{Figi: "BBG1", Balance: -30}}},
+			wantDirection: investapi.OrderDirection_ORDER_DIRECTION_BUY,
+			wantLots:      3,
+		},
+		{
+			name:          "futures position",
+			figi:          "FUT1",
+			positions:     &investapi.PositionsResponse{Futures: []*investapi.PositionsFutures{{Figi: "FUT1", Balance: 2}}},
+			wantDirection: investapi.OrderDirection_ORDER_DIRECTION_SELL,
+			wantLots:      2,
+		},
+		{
+			name:      "no position",
+			figi:      "BBG1",
+			positions: &investapi.PositionsResponse{Securities: []*investapi.PositionsSecurities{{Figi: "BBG2", Balance: 10}}},
+			wantErr:   ErrNoPosition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			orders := &fakeOrdersClient{}
+			c.ordersClient = orders
+			c.operationsClient = &fakeOperationsClient{positions: tt.positions}
+			c.instrumentsClient = &fakeInstrumentsClient{instruments: instruments}
+
+			_, err := c.ClosePosition(context.Background(), "acc", tt.figi)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				if len(orders.posted()) != 0 {
+					t.Fatalf("posted %v, want no orders", orders.posted())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ClosePosition: %v", err)
+			}
+
+			posted := orders.posted()
+			if len(posted) != 1 {
+				t.Fatalf("posted %d orders, want 1", len(posted))
+			}
+			req := posted[0]
+			if req.Direction != tt.wantDirection || req.Quantity != tt.wantLots ||
+				req.OrderType != investapi.OrderType_ORDER_TYPE_MARKET || req.InstrumentId != tt.figi {
+				t.Fatalf("order = %v, want a %v market order for %d lots of %s", req, tt.wantDirection, tt.wantLots, tt.figi)
+			}
+		})
+	}
+}
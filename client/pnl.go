@@ -0,0 +1,139 @@
+package client
+
+import (
+	"sync"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// openLot is an unmatched fill. Quantity is positive for long and negative for short lots.
+type openLot struct {
+	quantity   int64
+	priceNanos int64
+}
+
+// PnLTracker computes realized and unrealized P&L per instrument using FIFO lot matching.
+// Fills are matched against the oldest open lots of the opposite side, so a position
+// can be increased, partially closed or flipped from long to short.
+// Quantities are in instrument units (pieces), not lots.
+type PnLTracker struct {
+	currency string
+
+	mu       sync.Mutex
+	lots     map[string][]openLot
+	realized map[string]int64
+}
+
+// NewPnLTracker creates a tracker reporting P&L in the given currency
+func NewPnLTracker(currency string) *PnLTracker {
+	return &PnLTracker{
+		currency: NormalizeCurrency(currency),
+		lots:     make(map[string][]openLot),
+		realized: make(map[string]int64),
+	}
+}
+
+// ApplyFill records an executed fill for the instrument
+func (t *PnLTracker) ApplyFill(figi string, direction investapi.OrderDirection, price *investapi.Quotation, qty int64) {
+	if qty <= 0 {
+		return
+	}
+
+	signed := qty
+	if direction == investapi.OrderDirection_ORDER_DIRECTION_SELL {
+		signed = -qty
+	}
+	priceNanos := quotationToNanos(price)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	lots := t.lots[figi]
+	for signed != 0 && len(lots) > 0 && (lots[0].quantity > 0) != (signed > 0) {
+		matched := min(abs64(signed), abs64(lots[0].quantity))
+		if lots[0].quantity < 0 {
+			matched = -matched
+		}
+
+		// Closing a long lot by selling, or a short lot by buying
+		t.realized[figi] += (priceNanos - lots[0].priceNanos) * matched
+
+		lots[0].quantity -= matched
+		signed += matched
+		if lots[0].quantity == 0 {
+			lots = lots[1:]
+		}
+	}
+
+	if signed != 0 {
+		lots = append(lots, openLot{quantity: signed, priceNanos: priceNanos})
+	}
+
+	t.lots[figi] = lots
+}
+
+// Position returns the current signed position for the instrument
+func (t *PnLTracker) Position(figi string) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var position int64
+	for _, lot := range t.lots[figi] {
+		position += lot.quantity
+	}
+	return position
+}
+
+// Realized returns the P&L realized by closed lots of the instrument
+func (t *PnLTracker) Realized(figi string) *investapi.MoneyValue {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return nanosToMoneyValue(t.realized[figi], t.currency)
+}
+
+// Unrealized returns the P&L of open lots of the instrument valued at markPrice
+func (t *PnLTracker) Unrealized(figi string, markPrice *investapi.Quotation) *investapi.MoneyValue {
+	markNanos := quotationToNanos(markPrice)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var unrealized int64
+	for _, lot := range t.lots[figi] {
+		unrealized += (markNanos - lot.priceNanos) * lot.quantity
+	}
+	return nanosToMoneyValue(unrealized, t.currency)
+}
+
+// Breakeven returns the exit price per share at which a position opened at
+// entry neither gains nor loses after commission. commission is the total
+// charged for the entry fill; the exit is assumed to cost the same, so twice
+// the commission is spread over lots*lotSize shares. The per-share cost is
+// rounded up, keeping the result on the safe side. It returns nil for an
+// empty position or an unknown direction.
+func Breakeven(entry *investapi.Quotation, lots int64, lotSize int32, commission *investapi.MoneyValue, direction investapi.OrderDirection) *investapi.Quotation {
+	shares := lots * int64(lotSize)
+	if entry == nil || shares <= 0 {
+		return nil
+	}
+
+	roundTrip := 2 * abs64(moneyValueToNanos(commission))
+	perShare := (roundTrip + shares - 1) / shares
+
+	switch direction {
+	case investapi.OrderDirection_ORDER_DIRECTION_BUY:
+		return nanosToQuotation(quotationToNanos(entry) + perShare)
+	case investapi.OrderDirection_ORDER_DIRECTION_SELL:
+		return nanosToQuotation(quotationToNanos(entry) - perShare)
+	default:
+		return nil
+	}
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
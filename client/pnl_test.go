@@ -0,0 +1,120 @@
+package client
+
+import (
+	"testing"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+const (
+	buy  = investapi.OrderDirection_ORDER_DIRECTION_BUY
+	sell = investapi.OrderDirection_ORDER_DIRECTION_SELL
+)
+
+type tradeFill struct {
+	direction investapi.OrderDirection
+	price     float64
+	qty       int64
+}
+
+func TestPnLTrackerFIFO(t *testing.T) {
+	tests := []struct {
+		name           string
+		fills          []tradeFill
+		mark           float64
+		wantPosition   int64
+		wantRealized   float64
+		wantUnrealized float64
+	}{
+		{
+			name:           "buy buy sell closes the oldest lot first",
+			fills:          []tradeFill{{buy, 100, 10}, {buy, 110, 10}, {sell, 120, 15}},
+			mark:           130,
+			wantPosition:   5,
+			wantRealized:   20*10 + 10*5,
+			wantUnrealized: 20 * 5,
+		},
+		{
+			name:           "flip from long to short",
+			fills:          []tradeFill{{buy, 100, 10}, {sell, 90, 15}},
+			mark:           80,
+			wantPosition:   -5,
+			wantRealized:   -10 * 10,
+			wantUnrealized: 10 * 5,
+		},
+		{
+			name:         "short covered at a profit",
+			fills:        []tradeFill{{sell, 50.5, 4}, {buy, 50.25, 4}},
+			mark:         60,
+			wantRealized: 0.25 * 4,
+		},
+		{
+			name:           "zero quantity is ignored",
+			fills:          []tradeFill{{buy, 100, 2}, {sell, 200, 0}},
+			mark:           100,
+			wantPosition:   2,
+			wantUnrealized: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := NewPnLTracker("RUB")
+			for _, f := range tt.fills {
+				tracker.ApplyFill("BBG1", f.direction, NewQuotationRounded(f.price), f.qty)
+			}
+
+			if got := tracker.Position("BBG1"); got != tt.wantPosition {
+				t.Fatalf("position = %d, want %d", got, tt.wantPosition)
+			}
+			realized := tracker.Realized("BBG1")
+			if got := moneyValueToFloat(realized); got != tt.wantRealized {
+				t.Fatalf("realized = %v, want %v", got, tt.wantRealized)
+			}
+			if realized.GetCurrency() != "rub" {
+				t.Fatalf("currency = %q, want rub", realized.GetCurrency())
+			}
+			if got := moneyValueToFloat(tracker.Unrealized("BBG1", NewQuotationRounded(tt.mark))); got != tt.wantUnrealized {
+				t.Fatalf("unrealized = %v, want %v", got, tt.wantUnrealized)
+			}
+		})
+	}
+}
+
+func TestBreakeven(t *testing.T) {
+	commission := &investapi.MoneyValue{Currency: "rub", Units: 5}
+
+	tests := []struct {
+		name       string
+		entry      float64
+		lots       int64
+		lotSize    int32
+		commission *investapi.MoneyValue
+		direction  investapi.OrderDirection
+		want       *investapi.Quotation
+	}{
+		{name: "long", entry: 100, lots: 1, lotSize: 10, commission: commission, direction: buy, want: NewQuotationRounded(101)},
+		{name: "short", entry: 100, lots: 1, lotSize: 10, commission: commission, direction: sell, want: NewQuotationRounded(99)},
+		{
+			name:       "per-share cost rounded up",
+			entry:      100,
+			lots:       3,
+			lotSize:    1,
+			commission: &investapi.MoneyValue{Currency: "rub", Units: 1},
+			direction:  buy,
+			want:       &investapi.Quotation{Units: 100, Nano: 666666667},
+		},
+		{name: "no commission", entry: 100, lots: 1, lotSize: 1, direction: buy, want: NewQuotationRounded(100)},
+		{name: "empty position", entry: 100, lotSize: 10, commission: commission, direction: buy},
+		{name: "unknown direction", entry: 100, lots: 1, lotSize: 10, commission: commission},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Breakeven(NewQuotationRounded(tt.entry), tt.lots, tt.lotSize, tt.commission, tt.direction)
+			if !QuotationEqual(got, tt.want) {
+				t.Fatalf("Breakeven = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
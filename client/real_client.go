@@ -5,12 +5,18 @@ import (
 	"crypto/tls"
 	"fmt"
 	"log"
+	"math"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/buurzx/tinkoff-go/config"
@@ -23,6 +29,9 @@ type RealClient struct {
 	conn     *grpc.ClientConn
 	metadata metadata.MD
 
+	// Connection pool, set when config.ConnectionPoolSize > 1; conn is its first connection
+	pool *connPool
+
 	// gRPC service clients
 	usersClient       investapi.UsersServiceClient
 	instrumentsClient investapi.InstrumentsServiceClient
@@ -39,6 +48,9 @@ type RealClient struct {
 	// Signals service
 	signalsClient investapi.SignalServiceClient
 
+	// Sandbox service, used by VerifyEnvironment
+	sandboxClient investapi.SandboxServiceClient
+
 	// Context and cancellation
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -49,8 +61,47 @@ type RealClient struct {
 	// Connection state
 	connected bool
 
-	// Accounts cache
-	accounts []*investapi.Account
+	// Accounts cache, see GetAccounts
+	accountsMu        sync.Mutex
+	accounts          []*investapi.Account
+	accountsFetchedAt time.Time
+
+	// Tracking ID of the last successful unary call
+	lastTrackingID atomic.Value
+
+	// Outcome and rate limit of the latest unary calls, see Status
+	callStats callStats
+
+	// Per-account order locks, see lockAccount
+	accountLocks sync.Map
+
+	// Circuit breaker guarding PostOrder
+	orderBreaker *circuitBreaker
+
+	// User info cache
+	userInfoMu sync.Mutex
+	userInfo   *investapi.GetInfoResponse
+
+	// Instrument cache keyed by FIGI, see ResolveInstrument
+	instrumentsMu     sync.RWMutex
+	instruments       map[string]*InstrumentCacheEntry
+	instrumentFetches map[string]*instrumentFetch
+
+	// Reconnect handling, see watchConnection
+	reconnectMu    sync.Mutex
+	sessions       map[*MarketDataSession]struct{}
+	reconnectHooks []func()
+
+	// Handler buffer counters, see StreamStats
+	streamCounters streamCounters
+
+	// Streamed last prices keyed by FIGI and instrument UID, see StartLastPriceCache
+	lastPricesMu sync.RWMutex
+	lastPrices   map[string]lastPriceEntry
+
+	// GetMaxLots results, see config.MaxLotsCacheTTL
+	maxLotsMu sync.Mutex
+	maxLots   map[maxLotsKey]maxLotsEntry
 }
 
 // NewReal creates a new real Tinkoff client using actual API
@@ -75,19 +126,29 @@ func NewRealWithDemo(token string, isDemo bool) (*RealClient, error) {
 
 // NewRealWithConfig creates a new real Tinkoff client with provided config
 func NewRealWithConfig(cfg *config.Config) (*RealClient, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 
 	client := &RealClient{
-		config:   cfg,
-		metadata: metadata.Pairs("authorization", "Bearer "+cfg.Token),
-		ctx:      ctx,
-		cancel:   cancel,
+		config:       cfg,
+		metadata:     metadata.Pairs("authorization", "Bearer "+cfg.Token),
+		ctx:          ctx,
+		cancel:       cancel,
+		orderBreaker: newCircuitBreaker(cfg.OrderBreakerThreshold, cfg.OrderBreakerCooldown),
 	}
 
 	if err := client.connect(); err != nil {
+		cancel()
 		return nil, fmt.Errorf("failed to connect: %w", err)
 	}
 
+	if cfg.AutoReconnect {
+		client.watchConnections()
+	}
+
 	return client, nil
 }
 
@@ -96,42 +157,74 @@ func (c *RealClient) connect() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Create TLS credentials
+	if c.config.Debug {
+		enableGRPCDebugLogging()
+	}
+
+	// Create TLS credentials, pinned to the configured roots if any
+	rootCAs, err := c.config.CertPool()
+	if err != nil {
+		return fmt.Errorf("failed to load root certificates: %w", err)
+	}
+
 	creds := credentials.NewTLS(&tls.Config{
 		ServerName: "invest-public-api.tinkoff.ru",
+		RootCAs:    rootCAs,
 	})
 
-	// Dial options
-	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(creds),
-		grpc.WithDefaultCallOptions(
-			grpc.MaxCallRecvMsgSize(64*1024*1024), // 64MB
-			grpc.MaxCallSendMsgSize(64*1024*1024), // 64MB
-		),
+	poolSize := max(c.config.ConnectionPoolSize, 1)
+	conns := make([]*grpc.ClientConn, 0, poolSize)
+	closeAll := func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
 	}
 
-	conn, err := grpc.NewClient(c.config.ServerURL, opts...)
-	if err != nil {
-		return fmt.Errorf("failed to dial: %w", err)
+	for len(conns) < poolSize {
+		conn, err := grpc.NewClient(c.config.ServerURL, c.dialOptions(creds)...)
+		if err != nil {
+			closeAll()
+			return fmt.Errorf("failed to dial: %w", err)
+		}
+		conns = append(conns, conn)
+
+		if c.config.BlockingConnect {
+			if err := waitForReady(conn, c.config.DialTimeout); err != nil {
+				closeAll()
+				return err
+			}
+		}
 	}
 
-	c.conn = conn
+	// The first connection serves callers that need a single channel
+	c.conn = conns[0]
+
+	var cc grpc.ClientConnInterface = c.conn
+	if poolSize > 1 {
+		c.pool = newConnPool(conns)
+		cc = c.pool
+	}
 
 	// Initialize service clients
-	c.usersClient = investapi.NewUsersServiceClient(conn)
-	c.instrumentsClient = investapi.NewInstrumentsServiceClient(conn)
-	c.marketDataClient = investapi.NewMarketDataServiceClient(conn)
-	c.ordersClient = investapi.NewOrdersServiceClient(conn)
-	c.operationsClient = investapi.NewOperationsServiceClient(conn)
-	c.stopOrdersClient = investapi.NewStopOrdersServiceClient(conn)
+	c.usersClient = investapi.NewUsersServiceClient(cc)
+	c.instrumentsClient = investapi.NewInstrumentsServiceClient(cc)
+	c.marketDataClient = investapi.NewMarketDataServiceClient(cc)
+	c.ordersClient = investapi.NewOrdersServiceClient(cc)
+	if c.config.IsDemo {
+		// Demo orders go through SandboxService
+		c.ordersClient = newSandboxOrdersClient(cc)
+	}
+	c.operationsClient = investapi.NewOperationsServiceClient(cc)
+	c.stopOrdersClient = investapi.NewStopOrdersServiceClient(cc)
 
 	// Initialize streaming clients
-	c.marketDataStreamClient = investapi.NewMarketDataStreamServiceClient(conn)
-	c.ordersStreamClient = investapi.NewOrdersStreamServiceClient(conn)
-	c.operationsStreamClient = investapi.NewOperationsStreamServiceClient(conn)
+	c.marketDataStreamClient = investapi.NewMarketDataStreamServiceClient(cc)
+	c.ordersStreamClient = investapi.NewOrdersStreamServiceClient(cc)
+	c.operationsStreamClient = investapi.NewOperationsStreamServiceClient(cc)
 
 	// Initialize signals client
-	c.signalsClient = investapi.NewSignalServiceClient(conn)
+	c.signalsClient = investapi.NewSignalServiceClient(cc)
+	c.sandboxClient = investapi.NewSandboxServiceClient(cc)
 
 	c.connected = true
 
@@ -140,7 +233,62 @@ func (c *RealClient) connect() error {
 	return nil
 }
 
-// Close closes the client connection
+// dialOptions returns gRPC dial options built from the client config
+func (c *RealClient) dialOptions(creds credentials.TransportCredentials) []grpc.DialOption {
+	interceptors := []grpc.UnaryClientInterceptor{c.trackingInterceptor}
+	if c.config.MaxRetries > 0 {
+		interceptors = append(interceptors, c.retryInterceptor)
+	}
+	if len(c.config.MethodTimeouts) > 0 {
+		interceptors = append(interceptors, c.timeoutInterceptor)
+	}
+	if c.config.Debug {
+		interceptors = append(interceptors, debugInterceptor)
+	}
+	if c.config.RecordMode != config.RecordOff {
+		interceptors = append(interceptors, newCallRecorder(c.config.RecordMode, c.config.RecordDir).interceptor)
+	}
+
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(64*1024*1024), // 64MB
+			grpc.MaxCallSendMsgSize(64*1024*1024), // 64MB
+		),
+		grpc.WithChainUnaryInterceptor(interceptors...),
+	}
+
+	if c.config.UserAgent != "" {
+		opts = append(opts, grpc.WithUserAgent(c.config.UserAgent))
+	}
+
+	return opts
+}
+
+// waitForReady blocks until conn is ready or the timeout expires
+func waitForReady(conn *grpc.ClientConn, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = config.DefaultDialTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("failed to connect within %s: last state %s", timeout, state)
+		}
+	}
+}
+
+// Close closes the client connection.
+// It is safe to call Close multiple times and from several goroutines;
+// only the first call closes the connection, later calls return nil.
 func (c *RealClient) Close() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -149,17 +297,23 @@ func (c *RealClient) Close() error {
 		return nil
 	}
 
+	// Mark as closed first so a failed close is not retried
+	c.connected = false
+
 	// Cancel context to stop all goroutines
 	c.cancel()
 
-	// Close gRPC connection
-	if c.conn != nil {
+	// Close gRPC connections
+	if c.pool != nil {
+		if err := c.pool.Close(); err != nil {
+			return fmt.Errorf("failed to close connection pool: %w", err)
+		}
+	} else if c.conn != nil {
 		if err := c.conn.Close(); err != nil {
 			return fmt.Errorf("failed to close connection: %w", err)
 		}
 	}
 
-	c.connected = false
 	log.Println("Real Tinkoff client closed")
 
 	return nil
@@ -172,13 +326,41 @@ func (c *RealClient) IsConnected() bool {
 	return c.connected
 }
 
-// GetAccounts returns list of accounts using real API
+// UpdateToken replaces the API token used for new calls and streams.
+// Streams that are already open keep the old token until they are reopened.
+func (c *RealClient) UpdateToken(token string) error {
+	if token == "" {
+		return fmt.Errorf("token is required")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.metadata = metadata.Pairs("authorization", "Bearer "+token)
+	return nil
+}
+
+// GetAccounts returns list of accounts using real API.
+// Accounts are cached for config.AccountsCacheTTL; use RefreshAccounts to bypass the cache.
 func (c *RealClient) GetAccounts(ctx context.Context) ([]*investapi.Account, error) {
+	c.accountsMu.Lock()
+	if c.accounts != nil && c.config.AccountsCacheTTL > 0 && time.Since(c.accountsFetchedAt) < c.config.AccountsCacheTTL {
+		accounts := c.accounts
+		c.accountsMu.Unlock()
+		return accounts, nil
+	}
+	c.accountsMu.Unlock()
+
+	return c.RefreshAccounts(ctx)
+}
+
+// RefreshAccounts fetches accounts from the API and replaces the cached list
+func (c *RealClient) RefreshAccounts(ctx context.Context) ([]*investapi.Account, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
@@ -191,7 +373,10 @@ func (c *RealClient) GetAccounts(ctx context.Context) ([]*investapi.Account, err
 	}
 
 	// Cache accounts
+	c.accountsMu.Lock()
 	c.accounts = resp.Accounts
+	c.accountsFetchedAt = time.Now()
+	c.accountsMu.Unlock()
 
 	return resp.Accounts, nil
 }
@@ -202,7 +387,7 @@ func (c *RealClient) GetInstrumentByFIGI(ctx context.Context, figi string) (*inv
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
@@ -218,6 +403,8 @@ func (c *RealClient) GetInstrumentByFIGI(ctx context.Context, figi string) (*inv
 		return nil, fmt.Errorf("failed to get instrument by FIGI %s: %w", figi, err)
 	}
 
+	c.cacheInstrument(resp.Instrument)
+
 	return resp.Instrument, nil
 }
 
@@ -227,7 +414,7 @@ func (c *RealClient) GetInstrumentByTicker(ctx context.Context, ticker, classCod
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
@@ -253,7 +440,7 @@ func (c *RealClient) GetInstrumentByUID(ctx context.Context, uid string) (*inves
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
@@ -271,28 +458,49 @@ func (c *RealClient) GetInstrumentByUID(ctx context.Context, uid string) (*inves
 	return resp.Instrument, nil
 }
 
+// FindInstrumentOptions narrows the results of FindInstrumentWithOptions
+type FindInstrumentOptions struct {
+	// InstrumentType filters by instrument kind when set
+	InstrumentType *investapi.InstrumentType
+	// APITradeAvailableOnly keeps only instruments tradeable through the API
+	APITradeAvailableOnly bool
+	// Limit caps the number of returned instruments; zero returns all matches.
+	// The API has no limit parameter, so it is applied client-side.
+	Limit int
+}
+
 // FindInstrument searches for instruments by query string using real API
 func (c *RealClient) FindInstrument(ctx context.Context, query string, instrumentType *investapi.InstrumentType, apiTradeAvailableOnly bool) ([]*investapi.InstrumentShort, error) {
+	return c.FindInstrumentWithOptions(ctx, query, FindInstrumentOptions{
+		InstrumentType:        instrumentType,
+		APITradeAvailableOnly: apiTradeAvailableOnly,
+	})
+}
+
+// FindInstrumentWithOptions searches for instruments by query string and
+// returns at most opts.Limit of them in the API order
+func (c *RealClient) FindInstrumentWithOptions(ctx context.Context, query string, opts FindInstrumentOptions) ([]*investapi.InstrumentShort, error) {
+	if opts.Limit < 0 {
+		return nil, fmt.Errorf("find instrument limit must not be negative, got %d", opts.Limit)
+	}
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
 	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
 
 	req := &investapi.FindInstrumentRequest{
-		Query: query,
+		Query:          query,
+		InstrumentKind: opts.InstrumentType,
 	}
 
-	if instrumentType != nil {
-		req.InstrumentKind = instrumentType
-	}
-
-	if apiTradeAvailableOnly {
-		req.ApiTradeAvailableFlag = &apiTradeAvailableOnly
+	if opts.APITradeAvailableOnly {
+		req.ApiTradeAvailableFlag = &opts.APITradeAvailableOnly
 	}
 
 	resp, err := c.instrumentsClient.FindInstrument(ctxWithAuth, req)
@@ -300,7 +508,14 @@ func (c *RealClient) FindInstrument(ctx context.Context, query string, instrumen
 		return nil, fmt.Errorf("failed to find instruments for query '%s': %w", query, err)
 	}
 
-	return resp.Instruments, nil
+	c.cacheInstrumentShorts(resp.Instruments)
+
+	instruments := resp.Instruments
+	if opts.Limit > 0 && len(instruments) > opts.Limit {
+		instruments = instruments[:opts.Limit]
+	}
+
+	return instruments, nil
 }
 
 // GetBonds returns all bonds from Tinkoff Investment API
@@ -309,7 +524,7 @@ func (c *RealClient) GetBonds(ctx context.Context) (*investapi.BondsResponse, er
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
@@ -331,7 +546,7 @@ func (c *RealClient) GetBondCoupons(ctx context.Context, instrumentID string, fr
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
@@ -356,13 +571,44 @@ func (c *RealClient) GetBondCoupons(ctx context.Context, instrumentID string, fr
 	return resp, nil
 }
 
+// GetDividends returns dividend payments of a share in the given period
+func (c *RealClient) GetDividends(ctx context.Context, instrumentID string, from, to *time.Time) (*investapi.GetDividendsResponse, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected {
+		return nil, ErrNotConnected
+	}
+
+	// Create context with authorization
+	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
+
+	req := &investapi.GetDividendsRequest{
+		InstrumentId: instrumentID,
+	}
+
+	if from != nil {
+		req.From = timestamppb.New(*from)
+	}
+	if to != nil {
+		req.To = timestamppb.New(*to)
+	}
+
+	resp, err := c.instrumentsClient.GetDividends(ctxWithAuth, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dividends for %s: %w", instrumentID, err)
+	}
+
+	return resp, nil
+}
+
 // GetBondEvents returns events for a bond
 func (c *RealClient) GetBondEvents(ctx context.Context, instrumentID string, from, to *time.Time, eventType investapi.GetBondEventsRequest_EventType) (*investapi.GetBondEventsResponse, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
@@ -395,7 +641,7 @@ func (c *RealClient) GetAssetBy(ctx context.Context, assetUID string) (*investap
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
@@ -420,7 +666,7 @@ func (c *RealClient) GetAssetFundamentals(ctx context.Context, assetUIDs []strin
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
@@ -438,370 +684,892 @@ func (c *RealClient) GetAssetFundamentals(ctx context.Context, assetUIDs []strin
 	return resp, nil
 }
 
-// GetPortfolio returns portfolio information for an account using real API
-func (c *RealClient) GetPortfolio(ctx context.Context, accountID string) (*investapi.PortfolioResponse, error) {
+// GetBrands returns the list of brands with their logos and descriptions using real API
+func (c *RealClient) GetBrands(ctx context.Context) (*investapi.GetBrandsResponse, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
 	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
 
-	currency := investapi.PortfolioRequest_RUB
-	req := &investapi.PortfolioRequest{
-		AccountId: accountID,
-		Currency:  &currency, // Default to RUB
-	}
-
-	resp, err := c.operationsClient.GetPortfolio(ctxWithAuth, req)
+	resp, err := c.instrumentsClient.GetBrands(ctxWithAuth, &investapi.GetBrandsRequest{})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get portfolio for account %s: %w", accountID, err)
+		return nil, fmt.Errorf("failed to get brands: %w", err)
 	}
 
 	return resp, nil
 }
 
-// GetPositions returns positions for an account using real API
-func (c *RealClient) GetPositions(ctx context.Context, accountID string) (*investapi.PositionsResponse, error) {
+// GetBrandBy returns a brand by its UID using real API
+func (c *RealClient) GetBrandBy(ctx context.Context, brandID string) (*investapi.Brand, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
 	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
 
-	req := &investapi.PositionsRequest{
-		AccountId: accountID,
+	req := &investapi.GetBrandRequest{
+		Id: brandID,
 	}
 
-	resp, err := c.operationsClient.GetPositions(ctxWithAuth, req)
+	resp, err := c.instrumentsClient.GetBrandBy(ctxWithAuth, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get positions for account %s: %w", accountID, err)
+		return nil, fmt.Errorf("failed to get brand %s: %w", brandID, err)
 	}
 
 	return resp, nil
 }
 
-// GetOrders returns orders for an account using real API
-func (c *RealClient) GetOrders(ctx context.Context, accountID string) (*investapi.GetOrdersResponse, error) {
+// GetFuturesMargin returns initial margin and price step cost of a futures contract using real API.
+// instrumentID is a FIGI or instrument UID.
+func (c *RealClient) GetFuturesMargin(ctx context.Context, instrumentID string) (*investapi.GetFuturesMarginResponse, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
 	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
 
-	req := &investapi.GetOrdersRequest{
-		AccountId: accountID,
+	req := &investapi.GetFuturesMarginRequest{
+		InstrumentId: instrumentID,
 	}
 
-	resp, err := c.ordersClient.GetOrders(ctxWithAuth, req)
+	resp, err := c.instrumentsClient.GetFuturesMargin(ctxWithAuth, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get orders for account %s: %w", accountID, err)
+		return nil, fmt.Errorf("failed to get futures margin for %s: %w", instrumentID, err)
 	}
 
 	return resp, nil
 }
 
-// GetLastPrices returns last prices for given FIGIs using real API
-func (c *RealClient) GetLastPrices(ctx context.Context, figis []string) (*investapi.GetLastPricesResponse, error) {
+// GetTradingSchedules returns exchange trading schedules for the given period using real API.
+// An empty exchange returns schedules for all exchanges.
+func (c *RealClient) GetTradingSchedules(ctx context.Context, exchange string, from, to time.Time) (*investapi.TradingSchedulesResponse, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
 	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
 
-	req := &investapi.GetLastPricesRequest{
-		Figi: figis,
+	req := &investapi.TradingSchedulesRequest{
+		From: timestamppb.New(from),
+		To:   timestamppb.New(to),
 	}
 
-	resp, err := c.marketDataClient.GetLastPrices(ctxWithAuth, req)
+	if exchange != "" {
+		req.Exchange = &exchange
+	}
+
+	resp, err := c.instrumentsClient.TradingSchedules(ctxWithAuth, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get last prices: %w", err)
+		return nil, fmt.Errorf("failed to get trading schedules for exchange '%s': %w", exchange, err)
 	}
 
 	return resp, nil
 }
 
-// GetCandles returns historical candles using real API
-func (c *RealClient) GetCandles(ctx context.Context, figi string, from, to time.Time, interval investapi.CandleInterval) (*investapi.GetCandlesResponse, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
-	}
-
-	// Create context with authorization
-	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
-
-	req := &investapi.GetCandlesRequest{
-		Figi:     &figi,
-		From:     timestamppb.New(from),
-		To:       timestamppb.New(to),
-		Interval: interval,
-	}
+// GetPortfolio returns portfolio information for an account using real API
+func (c *RealClient) GetPortfolio(ctx context.Context, accountID string) (*investapi.PortfolioResponse, error) {
+	return c.getPortfolio(ctx, accountID, investapi.PortfolioRequest_RUB) // Default to RUB
+}
 
-	resp, err := c.marketDataClient.GetCandles(ctxWithAuth, req)
+// GetPortfolioValue returns the total portfolio value of an account in the given currency
+func (c *RealClient) GetPortfolioValue(ctx context.Context, accountID string, currency investapi.PortfolioRequest_CurrencyRequest) (*investapi.MoneyValue, error) {
+	portfolio, err := c.getPortfolio(ctx, accountID, currency)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get candles for %s: %w", figi, err)
+		return nil, err
 	}
 
-	return resp, nil
+	return portfolio.GetTotalAmountPortfolio(), nil
 }
 
-// GetLastTrades returns last trades for an instrument using real API
-func (c *RealClient) GetLastTrades(ctx context.Context, req *investapi.GetLastTradesRequest) (*investapi.GetLastTradesResponse, error) {
+func (c *RealClient) getPortfolio(ctx context.Context, accountID string, currency investapi.PortfolioRequest_CurrencyRequest) (*investapi.PortfolioResponse, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
 	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
 
-	resp, err := c.marketDataClient.GetLastTrades(ctxWithAuth, req)
+	req := &investapi.PortfolioRequest{
+		AccountId: accountID,
+		Currency:  &currency,
+	}
+
+	resp, err := c.operationsClient.GetPortfolio(ctxWithAuth, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get last trades: %w", err)
+		return nil, fmt.Errorf("failed to get portfolio for account %s: %w", accountID, err)
 	}
 
 	return resp, nil
 }
 
-// GetOrderBook returns order book for an instrument using real API
-func (c *RealClient) GetOrderBook(ctx context.Context, req *investapi.GetOrderBookRequest) (*investapi.GetOrderBookResponse, error) {
+// GetPositions returns positions for an account using real API
+func (c *RealClient) GetPositions(ctx context.Context, accountID string) (*investapi.PositionsResponse, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
 	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
 
-	resp, err := c.marketDataClient.GetOrderBook(ctxWithAuth, req)
+	req := &investapi.PositionsRequest{
+		AccountId: accountID,
+	}
+
+	resp, err := c.operationsClient.GetPositions(ctxWithAuth, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get order book: %w", err)
+		return nil, fmt.Errorf("failed to get positions for account %s: %w", accountID, err)
 	}
 
 	return resp, nil
 }
 
-// PostOrder places an order using real API
-func (c *RealClient) PostOrder(ctx context.Context, req *investapi.PostOrderRequest) (*investapi.PostOrderResponse, error) {
+// GenerateBrokerReport requests generation of a broker report for the given period.
+// Report generation is asynchronous: the returned task ID is used to fetch
+// report pages with GetBrokerReport once the report is ready.
+func (c *RealClient) GenerateBrokerReport(ctx context.Context, accountID string, from, to time.Time) (string, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return "", ErrNotConnected
 	}
 
 	// Create context with authorization
 	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
 
-	resp, err := c.ordersClient.PostOrder(ctxWithAuth, req)
+	req := &investapi.BrokerReportRequest{
+		Payload: &investapi.BrokerReportRequest_GenerateBrokerReportRequest{
+			GenerateBrokerReportRequest: &investapi.GenerateBrokerReportRequest{
+				AccountId: accountID,
+				From:      timestamppb.New(from),
+				To:        timestamppb.New(to),
+			},
+		},
+	}
+
+	resp, err := c.operationsClient.GetBrokerReport(ctxWithAuth, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to post order: %w", err)
+		return "", fmt.Errorf("failed to generate broker report for account %s: %w", accountID, err)
 	}
 
-	return resp, nil
+	generated := resp.GetGenerateBrokerReportResponse()
+	if generated == nil {
+		return "", fmt.Errorf("unexpected broker report response for account %s", accountID)
+	}
+
+	return generated.TaskId, nil
 }
 
-// CancelOrder cancels an order using real API
-func (c *RealClient) CancelOrder(ctx context.Context, accountID, orderID string) (*investapi.CancelOrderResponse, error) {
+// GetBrokerReport returns a page of a broker report generated by GenerateBrokerReport
+func (c *RealClient) GetBrokerReport(ctx context.Context, taskID string, page int32) (*investapi.BrokerReportResponse, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
 	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
 
-	req := &investapi.CancelOrderRequest{
-		AccountId: accountID,
-		OrderId:   orderID,
+	req := &investapi.BrokerReportRequest{
+		Payload: &investapi.BrokerReportRequest_GetBrokerReportRequest{
+			GetBrokerReportRequest: &investapi.GetBrokerReportRequest{
+				TaskId: taskID,
+				Page:   &page,
+			},
+		},
 	}
 
-	resp, err := c.ordersClient.CancelOrder(ctxWithAuth, req)
+	resp, err := c.operationsClient.GetBrokerReport(ctxWithAuth, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to cancel order %s: %w", orderID, err)
+		return nil, fmt.Errorf("failed to get broker report %s: %w", taskID, err)
 	}
 
 	return resp, nil
 }
 
-// GetUserInfo returns user information using real API
-func (c *RealClient) GetUserInfo(ctx context.Context) (*investapi.GetInfoResponse, error) {
+// GetOperationsByCursor returns a page of account operations using cursor pagination
+func (c *RealClient) GetOperationsByCursor(ctx context.Context, req *investapi.GetOperationsByCursorRequest) (*investapi.GetOperationsByCursorResponse, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
 	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
 
-	req := &investapi.GetInfoRequest{}
-	resp, err := c.usersClient.GetInfo(ctxWithAuth, req)
+	resp, err := c.operationsClient.GetOperationsByCursor(ctxWithAuth, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user info: %w", err)
+		return nil, fmt.Errorf("failed to get operations for account %s: %w", req.AccountId, err)
 	}
 
 	return resp, nil
 }
 
-// Context returns the client's context
-func (c *RealClient) Context() context.Context {
-	return c.ctx
+// IterateOperations walks all account operations in the given period page by page,
+// calling yield for each operation. Iteration stops when there are no more pages,
+// yield returns false or ctx is cancelled.
+func (c *RealClient) IterateOperations(ctx context.Context, accountID string, from, to time.Time, yield func(*investapi.OperationItem) bool) error {
+	return c.IterateOperationsFiltered(ctx, accountID, from, to, OperationsFilter{}, yield)
 }
 
-// STREAMING FUNCTIONALITY
-
-// StartMarketDataStream starts real-time market data streaming
-func (c *RealClient) StartMarketDataStream() (investapi.MarketDataStreamService_MarketDataStreamClient, error) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
+// OperationsFilter narrows down the operations returned by IterateOperationsFiltered
+type OperationsFilter struct {
+	// Types limits operations to the given types, e.g. OPERATION_TYPE_DIVIDEND
+	Types []investapi.OperationType
+	// InstrumentID limits operations to one instrument (FIGI or UID)
+	InstrumentID string
+	// MinAmount and MaxAmount bound the absolute payment amount. The API has
+	// no amount filter, so they are applied locally.
+	MinAmount *float64
+	MaxAmount *float64
+}
 
-	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+// match reports whether the operation passes the locally applied filters
+func (f OperationsFilter) match(item *investapi.OperationItem) bool {
+	amount := math.Abs(moneyValueToFloat(item.Payment))
+	if f.MinAmount != nil && amount < *f.MinAmount {
+		return false
 	}
-
-	// Create context with authorization
-	ctxWithAuth := metadata.NewOutgoingContext(c.ctx, c.metadata)
-
-	// Start bidirectional stream
-	stream, err := c.marketDataStreamClient.MarketDataStream(ctxWithAuth)
-	if err != nil {
-		return nil, fmt.Errorf("failed to start market data stream: %w", err)
+	if f.MaxAmount != nil && amount > *f.MaxAmount {
+		return false
 	}
-
-	log.Println("🚀 Market data stream started")
-	return stream, nil
+	return true
 }
 
-// SubscribeCandles subscribes to candle updates for instruments
-func (c *RealClient) SubscribeCandles(stream investapi.MarketDataStreamService_MarketDataStreamClient, instruments []string, interval investapi.SubscriptionInterval, waitingClose bool) error {
-	candleInstruments := make([]*investapi.CandleInstrument, len(instruments))
-	for i, instrumentID := range instruments {
-		candleInstruments[i] = &investapi.CandleInstrument{
-			InstrumentId: instrumentID,
-			Interval:     interval,
-		}
+// IterateOperationsFiltered is IterateOperations with operation type, instrument
+// and amount filters. Type and instrument filters are sent to the API.
+func (c *RealClient) IterateOperationsFiltered(ctx context.Context, accountID string, from, to time.Time, filter OperationsFilter, yield func(*investapi.OperationItem) bool) error {
+	req := &investapi.GetOperationsByCursorRequest{
+		AccountId:      accountID,
+		From:           timestamppb.New(from),
+		To:             timestamppb.New(to),
+		OperationTypes: filter.Types,
 	}
-
-	req := &investapi.MarketDataRequest{
-		Payload: &investapi.MarketDataRequest_SubscribeCandlesRequest{
-			SubscribeCandlesRequest: &investapi.SubscribeCandlesRequest{
-				SubscriptionAction: investapi.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE,
-				Instruments:        candleInstruments,
-				WaitingClose:       waitingClose,
-			},
-		},
+	if filter.InstrumentID != "" {
+		req.InstrumentId = &filter.InstrumentID
 	}
 
-	err := stream.Send(req)
-	if err != nil {
-		return fmt.Errorf("failed to subscribe to candles: %w", err)
-	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
-	log.Printf("📊 Subscribed to candles for %d instruments", len(instruments))
-	return nil
-}
+		resp, err := c.GetOperationsByCursor(ctx, req)
+		if err != nil {
+			return err
+		}
 
-// SubscribeOrderBook subscribes to order book updates for instruments
-func (c *RealClient) SubscribeOrderBook(stream investapi.MarketDataStreamService_MarketDataStreamClient, instruments []string, depth int32) error {
-	orderBookInstruments := make([]*investapi.OrderBookInstrument, len(instruments))
-	for i, instrumentID := range instruments {
-		orderBookInstruments[i] = &investapi.OrderBookInstrument{
-			InstrumentId: instrumentID,
-			Depth:        depth,
+		for _, item := range resp.Items {
+			if !filter.match(item) {
+				continue
+			}
+			if !yield(item) {
+				return nil
+			}
 		}
-	}
 
-	req := &investapi.MarketDataRequest{
-		Payload: &investapi.MarketDataRequest_SubscribeOrderBookRequest{
-			SubscribeOrderBookRequest: &investapi.SubscribeOrderBookRequest{
-				SubscriptionAction: investapi.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE,
-				Instruments:        orderBookInstruments,
-			},
-		},
+		if !resp.HasNext || resp.NextCursor == "" {
+			return nil
+		}
+
+		cursor := resp.NextCursor
+		req.Cursor = &cursor
 	}
+}
 
-	err := stream.Send(req)
+// GetOrders returns orders for an account using real API
+func (c *RealClient) GetOrders(ctx context.Context, accountID string) (*investapi.GetOrdersResponse, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected {
+		return nil, ErrNotConnected
+	}
+
+	// Create context with authorization
+	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
+
+	req := &investapi.GetOrdersRequest{
+		AccountId: accountID,
+	}
+
+	resp, err := c.ordersClient.GetOrders(ctxWithAuth, req)
 	if err != nil {
-		return fmt.Errorf("failed to subscribe to order book: %w", err)
+		return nil, fmt.Errorf("failed to get orders for account %s: %w", accountID, err)
 	}
 
-	log.Printf("📖 Subscribed to order book for %d instruments", len(instruments))
-	return nil
+	return resp, nil
 }
 
-// SubscribeTrades subscribes to trade updates for instruments
-func (c *RealClient) SubscribeTrades(stream investapi.MarketDataStreamService_MarketDataStreamClient, instruments []string) error {
-	tradeInstruments := make([]*investapi.TradeInstrument, len(instruments))
-	for i, instrumentID := range instruments {
-		tradeInstruments[i] = &investapi.TradeInstrument{
+// GetOrderState returns the current state of an order using real API
+func (c *RealClient) GetOrderState(ctx context.Context, accountID, orderID string) (*investapi.OrderState, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected {
+		return nil, ErrNotConnected
+	}
+
+	// Create context with authorization
+	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
+
+	req := &investapi.GetOrderStateRequest{
+		AccountId: accountID,
+		OrderId:   orderID,
+	}
+
+	resp, err := c.ordersClient.GetOrderState(ctxWithAuth, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get state of order %s: %w", orderID, err)
+	}
+
+	return resp, nil
+}
+
+// GetLastPrices returns last prices for given FIGIs using real API
+func (c *RealClient) GetLastPrices(ctx context.Context, figis []string) (*investapi.GetLastPricesResponse, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected {
+		return nil, ErrNotConnected
+	}
+
+	// Create context with authorization
+	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
+
+	req := &investapi.GetLastPricesRequest{
+		Figi: figis,
+	}
+
+	resp, err := c.marketDataClient.GetLastPrices(ctxWithAuth, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last prices: %w", err)
+	}
+
+	return resp, nil
+}
+
+// GetClosePrices returns trading session close prices for instruments using real API
+func (c *RealClient) GetClosePrices(ctx context.Context, instrumentIDs []string) (*investapi.GetClosePricesResponse, error) {
+	if len(instrumentIDs) == 0 {
+		return &investapi.GetClosePricesResponse{}, nil
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected {
+		return nil, ErrNotConnected
+	}
+
+	// Create context with authorization
+	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
+
+	instruments := make([]*investapi.InstrumentClosePriceRequest, len(instrumentIDs))
+	for i, instrumentID := range instrumentIDs {
+		instruments[i] = &investapi.InstrumentClosePriceRequest{
 			InstrumentId: instrumentID,
 		}
 	}
 
-	req := &investapi.MarketDataRequest{
-		Payload: &investapi.MarketDataRequest_SubscribeTradesRequest{
-			SubscribeTradesRequest: &investapi.SubscribeTradesRequest{
-				SubscriptionAction: investapi.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE,
-				Instruments:        tradeInstruments,
-			},
-		},
+	req := &investapi.GetClosePricesRequest{
+		Instruments: instruments,
 	}
 
-	err := stream.Send(req)
+	resp, err := c.marketDataClient.GetClosePrices(ctxWithAuth, req)
 	if err != nil {
-		return fmt.Errorf("failed to subscribe to trades: %w", err)
+		return nil, fmt.Errorf("failed to get close prices: %w", err)
+	}
+
+	return resp, nil
+}
+
+// GetCandles returns historical candles using real API
+func (c *RealClient) GetCandles(ctx context.Context, figi string, from, to time.Time, interval investapi.CandleInterval) (*investapi.GetCandlesResponse, error) {
+	if err := validateCandleInterval(interval); err != nil {
+		return nil, fmt.Errorf("failed to get candles for %s: %w", figi, err)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected {
+		return nil, ErrNotConnected
+	}
+
+	// Create context with authorization
+	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
+
+	req := &investapi.GetCandlesRequest{
+		Figi:     &figi,
+		From:     timestamppb.New(from),
+		To:       timestamppb.New(to),
+		Interval: interval,
+	}
+
+	resp, err := c.marketDataClient.GetCandles(ctxWithAuth, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get candles for %s: %w", figi, err)
+	}
+
+	return resp, nil
+}
+
+// GetLastTrades returns last trades for an instrument using real API
+func (c *RealClient) GetLastTrades(ctx context.Context, req *investapi.GetLastTradesRequest) (*investapi.GetLastTradesResponse, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected {
+		return nil, ErrNotConnected
+	}
+
+	// Create context with authorization
+	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
+
+	resp, err := c.marketDataClient.GetLastTrades(ctxWithAuth, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last trades: %w", err)
+	}
+
+	return resp, nil
+}
+
+// GetOrderBook returns order book for an instrument using real API
+func (c *RealClient) GetOrderBook(ctx context.Context, req *investapi.GetOrderBookRequest) (*investapi.GetOrderBookResponse, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected {
+		return nil, ErrNotConnected
+	}
+
+	// Create context with authorization
+	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
+
+	resp, err := c.marketDataClient.GetOrderBook(ctxWithAuth, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order book: %w", err)
+	}
+
+	return resp, nil
+}
+
+// PostOrder places an order using real API.
+// An empty req.OrderId is filled with NewIdempotencyKey; reuse the populated
+// request when retrying so the order is not placed twice.
+func (c *RealClient) PostOrder(ctx context.Context, req *investapi.PostOrderRequest) (*investapi.PostOrderResponse, error) {
+	if req.OrderId == "" {
+		req.OrderId = NewIdempotencyKey()
+	}
+
+	if c.config.DryRun {
+		return dryRunPostOrder(req), nil
+	}
+
+	unlock := c.lockAccount(req.AccountId)
+	defer unlock()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected {
+		return nil, ErrNotConnected
+	}
+
+	if err := c.orderBreaker.allow(); err != nil {
+		return nil, fmt.Errorf("failed to post order: %w", err)
+	}
+
+	// Create context with authorization
+	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
+
+	var trailer metadata.MD
+	resp, err := c.ordersClient.PostOrder(ctxWithAuth, req, grpc.Trailer(&trailer))
+	c.orderBreaker.record(ctx, err)
+	if err != nil {
+		if !req.ConfirmMarginTrade && isMarginConfirmationError(err, trailer) {
+			return nil, fmt.Errorf("failed to post order: %w: %w", ErrMarginConfirmationRequired, err)
+		}
+		return nil, fmt.Errorf("failed to post order: %w", err)
+	}
+
+	c.invalidateMaxLots(req.AccountId)
+
+	return resp, nil
+}
+
+// PostOrderWithInstrument validates the order against the instrument trading rules
+// with ValidateOrderAgainstInstrument and places it if the order is valid
+func (c *RealClient) PostOrderWithInstrument(ctx context.Context, req *investapi.PostOrderRequest, instrument *investapi.Instrument) (*investapi.PostOrderResponse, error) {
+	if err := ValidateOrderAgainstInstrument(req, instrument); err != nil {
+		return nil, fmt.Errorf("invalid order: %w", err)
+	}
+
+	return c.PostOrder(ctx, req)
+}
+
+// CancelOrder cancels an order using real API
+func (c *RealClient) CancelOrder(ctx context.Context, accountID, orderID string) (*investapi.CancelOrderResponse, error) {
+	if c.config.DryRun {
+		return dryRunCancelOrder(accountID, orderID), nil
+	}
+
+	unlock := c.lockAccount(accountID)
+	defer unlock()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected {
+		return nil, ErrNotConnected
+	}
+
+	// Create context with authorization
+	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
+
+	req := &investapi.CancelOrderRequest{
+		AccountId: accountID,
+		OrderId:   orderID,
+	}
+
+	resp, err := c.ordersClient.CancelOrder(ctxWithAuth, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel order %s: %w", orderID, err)
+	}
+
+	c.invalidateMaxLots(accountID)
+
+	return resp, nil
+}
+
+// lockAccount serializes order operations for a single account while
+// letting operations for different accounts run in parallel
+func (c *RealClient) lockAccount(accountID string) func() {
+	lock, _ := c.accountLocks.LoadOrStore(accountID, &sync.Mutex{})
+	mu := lock.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// GetUserInfo returns user information using real API
+func (c *RealClient) GetUserInfo(ctx context.Context) (*investapi.GetInfoResponse, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected {
+		return nil, ErrNotConnected
+	}
+
+	// Create context with authorization
+	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
+
+	req := &investapi.GetInfoRequest{}
+	resp, err := c.usersClient.GetInfo(ctxWithAuth, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
+	}
+
+	return resp, nil
+}
+
+// GetUserTariff returns the request and stream limits of the account's tariff
+func (c *RealClient) GetUserTariff(ctx context.Context) (*investapi.GetUserTariffResponse, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected {
+		return nil, ErrNotConnected
+	}
+
+	// Create context with authorization
+	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
+
+	req := &investapi.GetUserTariffRequest{}
+	resp, err := c.usersClient.GetUserTariff(ctxWithAuth, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user tariff: %w", err)
+	}
+
+	return resp, nil
+}
+
+// UnaryLimitsByMethod maps every method listed in the tariff to its requests
+// per minute. Keys are full gRPC method names such as
+// investapi.UsersService_GetAccounts_FullMethodName, the form used by
+// config.MethodTimeouts, so the limits can drive a client-side rate limiter.
+func UnaryLimitsByMethod(tariff *investapi.GetUserTariffResponse) map[string]int32 {
+	limits := make(map[string]int32)
+	for _, limit := range tariff.GetUnaryLimits() {
+		for _, method := range limit.Methods {
+			if !strings.HasPrefix(method, "/") {
+				method = "/" + method
+			}
+			limits[method] = limit.LimitPerMinute
+		}
+	}
+	return limits
+}
+
+// VerifyEnvironment checks that the token belongs to the environment selected
+// by config.IsDemo. The accounts are listed from the service of that
+// environment, SandboxService for demo and UsersService for production, and
+// compared with it:
+//   - a token rejected as unauthenticated or lacking permissions is a mismatch
+//   - in demo mode, an IIS, invest box or fund account is a mismatch, since
+//     the sandbox only opens brokerage accounts
+//   - in production, accounts that all have an unspecified type are a
+//     mismatch, since that is how sandbox accounts are reported
+//
+// Mismatches are reported as *EnvironmentMismatchError. The accounts cache is
+// left untouched.
+func (c *RealClient) VerifyEnvironment(ctx context.Context) error {
+	if _, err := c.GetUserInfo(ctx); err != nil {
+		return c.environmentError(err)
+	}
+
+	accounts, err := c.environmentAccounts(ctx)
+	if err != nil {
+		return c.environmentError(err)
+	}
+
+	if err := checkAccountsEnvironment(accounts, c.config.IsDemo); err != nil {
+		return &EnvironmentMismatchError{IsDemo: c.config.IsDemo, Err: err}
 	}
 
-	log.Printf("💰 Subscribed to trades for %d instruments", len(instruments))
 	return nil
 }
 
-// SubscribeLastPrices subscribes to last price updates for instruments
-func (c *RealClient) SubscribeLastPrices(stream investapi.MarketDataStreamService_MarketDataStreamClient, instruments []string) error {
-	lastPriceInstruments := make([]*investapi.LastPriceInstrument, len(instruments))
-	for i, instrumentID := range instruments {
-		lastPriceInstruments[i] = &investapi.LastPriceInstrument{
-			InstrumentId: instrumentID,
+// environmentAccounts lists the accounts of the configured environment
+// without going through the accounts cache
+func (c *RealClient) environmentAccounts(ctx context.Context) ([]*investapi.Account, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected {
+		return nil, ErrNotConnected
+	}
+
+	// Create context with authorization
+	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
+
+	req := &investapi.GetAccountsRequest{}
+
+	var (
+		resp *investapi.GetAccountsResponse
+		err  error
+	)
+	if c.config.IsDemo {
+		resp, err = c.sandboxClient.GetSandboxAccounts(ctxWithAuth, req)
+	} else {
+		resp, err = c.usersClient.GetAccounts(ctxWithAuth, req)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get accounts: %w", err)
+	}
+
+	return resp.Accounts, nil
+}
+
+// checkAccountsEnvironment compares account types with the expected environment
+func checkAccountsEnvironment(accounts []*investapi.Account, isDemo bool) error {
+	if isDemo {
+		for _, account := range accounts {
+			switch account.Type {
+			case investapi.AccountType_ACCOUNT_TYPE_TINKOFF_IIS,
+				investapi.AccountType_ACCOUNT_TYPE_INVEST_BOX,
+				investapi.AccountType_ACCOUNT_TYPE_INVEST_FUND:
+				return fmt.Errorf("account %s has type %s, which the sandbox does not open", account.Id, account.Type)
+			}
 		}
+		return nil
 	}
 
-	req := &investapi.MarketDataRequest{
-		Payload: &investapi.MarketDataRequest_SubscribeLastPriceRequest{
-			SubscribeLastPriceRequest: &investapi.SubscribeLastPriceRequest{
-				SubscriptionAction: investapi.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE,
-				Instruments:        lastPriceInstruments,
-			},
-		},
+	if len(accounts) == 0 {
+		return nil
+	}
+	for _, account := range accounts {
+		if account.Type != investapi.AccountType_ACCOUNT_TYPE_UNSPECIFIED {
+			return nil
+		}
+	}
+	return fmt.Errorf("all %d accounts have an unspecified type, as sandbox accounts do", len(accounts))
+}
+
+// environmentError wraps authentication failures in EnvironmentMismatchError
+func (c *RealClient) environmentError(err error) error {
+	switch status.Code(err) {
+	case codes.Unauthenticated, codes.PermissionDenied:
+		return &EnvironmentMismatchError{IsDemo: c.config.IsDemo, Err: err}
+	default:
+		return err
 	}
+}
+
+// LastTrackingID returns the tracking ID of the last successful unary call.
+// Failed calls carry their tracking ID in the returned error instead.
+func (c *RealClient) LastTrackingID() string {
+	id, _ := c.lastTrackingID.Load().(string)
+	return id
+}
 
-	err := stream.Send(req)
+// CanTradeInstrumentType reports whether the user is qualified to trade the given
+// instrument category, as listed in GetInfoResponse.QualifiedForWorkWith.
+// User info is fetched once and cached for the lifetime of the client.
+func (c *RealClient) CanTradeInstrumentType(ctx context.Context, category string) (bool, error) {
+	info, err := c.cachedUserInfo(ctx)
 	if err != nil {
+		return false, err
+	}
+
+	for _, qualified := range info.QualifiedForWorkWith {
+		if strings.EqualFold(qualified, category) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// cachedUserInfo returns cached user info, fetching it on first use
+func (c *RealClient) cachedUserInfo(ctx context.Context) (*investapi.GetInfoResponse, error) {
+	c.userInfoMu.Lock()
+	defer c.userInfoMu.Unlock()
+
+	if c.userInfo != nil {
+		return c.userInfo, nil
+	}
+
+	info, err := c.GetUserInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.userInfo = info
+	return info, nil
+}
+
+// Context returns the client's context
+func (c *RealClient) Context() context.Context {
+	return c.ctx
+}
+
+// STREAMING FUNCTIONALITY
+
+// StartMarketDataStream starts real-time market data streaming
+func (c *RealClient) StartMarketDataStream() (investapi.MarketDataStreamService_MarketDataStreamClient, error) {
+	return c.startMarketDataStream(c.ctx)
+}
+
+func (c *RealClient) startMarketDataStream(ctx context.Context) (investapi.MarketDataStreamService_MarketDataStreamClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.connected {
+		return nil, ErrNotConnected
+	}
+
+	// Create context with authorization
+	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
+
+	// Start bidirectional stream
+	stream, err := c.marketDataStreamClient.MarketDataStream(ctxWithAuth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start market data stream: %w", err)
+	}
+
+	log.Println("🚀 Market data stream started")
+	return stream, nil
+}
+
+// SubscribeCandles subscribes to candle updates for instruments.
+// waitingClose selects when candles are sent: with CandlesOnClose only
+// completed candles arrive, one per interval; with CandlesOnEveryTrade the
+// current candle is sent again after every trade until it closes.
+// Large instrument lists are sent in batches of config.SubscriptionBatchSize.
+func (c *RealClient) SubscribeCandles(stream investapi.MarketDataStreamService_MarketDataStreamClient, instruments []string, interval investapi.SubscriptionInterval, waitingClose bool) error {
+	spec := MarketDataSubscription{
+		Type:          SubscriptionCandles,
+		InstrumentIDs: instruments,
+		Interval:      interval,
+		WaitingClose:  waitingClose,
+	}
+
+	if err := c.subscribe(stream, spec); err != nil {
+		return fmt.Errorf("failed to subscribe to candles: %w", err)
+	}
+
+	log.Printf("📊 Subscribed to candles for %d instruments", len(instruments))
+	return nil
+}
+
+// SubscribeOrderBook subscribes to order book updates for instruments
+func (c *RealClient) SubscribeOrderBook(stream investapi.MarketDataStreamService_MarketDataStreamClient, instruments []string, depth int32) error {
+	spec := MarketDataSubscription{
+		Type:          SubscriptionOrderBook,
+		InstrumentIDs: instruments,
+		Depth:         depth,
+	}
+
+	if err := c.subscribe(stream, spec); err != nil {
+		return fmt.Errorf("failed to subscribe to order book: %w", err)
+	}
+
+	log.Printf("📖 Subscribed to order book for %d instruments", len(instruments))
+	return nil
+}
+
+// SubscribeTrades subscribes to trade updates for instruments
+func (c *RealClient) SubscribeTrades(stream investapi.MarketDataStreamService_MarketDataStreamClient, instruments []string) error {
+	spec := MarketDataSubscription{
+		Type:          SubscriptionTrades,
+		InstrumentIDs: instruments,
+	}
+
+	if err := c.subscribe(stream, spec); err != nil {
+		return fmt.Errorf("failed to subscribe to trades: %w", err)
+	}
+
+	log.Printf("💰 Subscribed to trades for %d instruments", len(instruments))
+	return nil
+}
+
+// SubscribeLastPrices subscribes to last price updates for instruments
+func (c *RealClient) SubscribeLastPrices(stream investapi.MarketDataStreamService_MarketDataStreamClient, instruments []string) error {
+	spec := MarketDataSubscription{
+		Type:          SubscriptionLastPrices,
+		InstrumentIDs: instruments,
+	}
+
+	if err := c.subscribe(stream, spec); err != nil {
 		return fmt.Errorf("failed to subscribe to last prices: %w", err)
 	}
 
@@ -809,17 +1577,83 @@ func (c *RealClient) SubscribeLastPrices(stream investapi.MarketDataStreamServic
 	return nil
 }
 
+// subscribe sends the batched subscribe requests for spec on stream
+func (c *RealClient) subscribe(stream investapi.MarketDataStreamService_MarketDataStreamClient, spec MarketDataSubscription) error {
+	requests := buildSubscriptionRequests(investapi.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE, spec.Subscriptions(), c.subscriptionBatchSize())
+	return sendSubscriptionRequests(stream, requests)
+}
+
 // StartOrderStream starts order state streaming
 func (c *RealClient) StartOrderStream(accountIDs []string) (investapi.OrdersStreamService_OrderStateStreamClient, error) {
+	return c.startOrderStream(c.ctx, accountIDs)
+}
+
+// OrderStateChannel streams order state changes of the accounts into the
+// returned channel, skipping pings and subscription messages. The channel is
+// closed when ctx is cancelled, the client is closed or the stream fails;
+// a stream failure is sent to the error channel first. Both channels are
+// closed when streaming ends.
+func (c *RealClient) OrderStateChannel(ctx context.Context, accountIDs []string) (<-chan *investapi.OrderStateStreamResponse_OrderState, <-chan error) {
+	size := c.config.StreamBufferSize
+	if size <= 0 {
+		size = defaultStreamBufferSize
+	}
+
+	states := make(chan *investapi.OrderStateStreamResponse_OrderState, size)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(states)
+
+		streamCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		// Tie the stream to both the caller's and the client's lifetime
+		stop := context.AfterFunc(c.ctx, cancel)
+		defer stop()
+
+		stream, err := c.startOrderStream(streamCtx, accountIDs)
+		if err != nil {
+			errs <- err
+			return
+		}
+
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				if !IsStreamClosed(err) && streamCtx.Err() == nil {
+					errs <- fmt.Errorf("order state stream error: %w", err)
+				}
+				return
+			}
+
+			payload, ok := resp.Payload.(*investapi.OrderStateStreamResponse_OrderState_)
+			if !ok {
+				continue
+			}
+
+			select {
+			case states <- payload.OrderState:
+			case <-streamCtx.Done():
+				return
+			}
+		}
+	}()
+
+	return states, errs
+}
+
+func (c *RealClient) startOrderStream(ctx context.Context, accountIDs []string) (investapi.OrdersStreamService_OrderStateStreamClient, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
-	ctxWithAuth := metadata.NewOutgoingContext(c.ctx, c.metadata)
+	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
 
 	req := &investapi.OrderStateStreamRequest{
 		Accounts: accountIDs,
@@ -834,15 +1668,92 @@ func (c *RealClient) StartOrderStream(accountIDs []string) (investapi.OrdersStre
 	return stream, nil
 }
 
+// StartTradesStream starts streaming of trade fills for the given accounts
+func (c *RealClient) StartTradesStream(accountIDs []string) (investapi.OrdersStreamService_TradesStreamClient, error) {
+	return c.startTradesStream(c.ctx, accountIDs)
+}
+
+// StreamTradesFunc streams trade fills and calls handler for each OrderTrades message.
+// It blocks until ctx is cancelled, the client is closed or the stream fails.
+// The handler runs behind a buffer of config.StreamBufferSize messages; when it
+// falls behind, the oldest messages are dropped and counted in StreamStats.
+// Messages still queued when the stream ends are handled before it returns,
+// unless ctx is cancelled or the client closed, in which case they are discarded.
+func (c *RealClient) StreamTradesFunc(ctx context.Context, accountIDs []string, handler func(*investapi.OrderTrades)) error {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Tie the stream to both the caller's and the client's lifetime
+	stop := context.AfterFunc(c.ctx, cancel)
+	defer stop()
+
+	stream, err := c.startTradesStream(streamCtx, accountIDs)
+	if err != nil {
+		return err
+	}
+
+	// Run the handler behind a bounded buffer so it cannot stall Recv
+	d := newDispatcher(c.config.StreamBufferSize, &c.streamCounters, handler)
+	defer d.close(streamCtx)
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if IsStreamClosed(err) || streamCtx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("trades stream error: %w", err)
+		}
+
+		if payload, ok := resp.Payload.(*investapi.TradesStreamResponse_OrderTrades); ok {
+			d.push(payload.OrderTrades)
+		}
+	}
+}
+
+func (c *RealClient) startTradesStream(ctx context.Context, accountIDs []string) (investapi.OrdersStreamService_TradesStreamClient, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.connected {
+		return nil, ErrNotConnected
+	}
+
+	// Create context with authorization
+	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
+
+	req := &investapi.TradesStreamRequest{
+		Accounts: accountIDs,
+	}
+
+	stream, err := c.ordersStreamClient.TradesStream(ctxWithAuth, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start trades stream: %w", err)
+	}
+
+	log.Printf("🚀 Trades stream started for %d accounts", len(accountIDs))
+	return stream, nil
+}
+
 // ADVANCED ORDER FUNCTIONALITY
 
-// PostStopOrder places a stop order using real API
+// PostStopOrder places a stop order using real API.
+// An empty req.OrderId is filled with NewIdempotencyKey; reuse the populated
+// request when retrying so the stop order is not placed twice.
 func (c *RealClient) PostStopOrder(ctx context.Context, req *investapi.PostStopOrderRequest) (*investapi.PostStopOrderResponse, error) {
+	if req.OrderId == "" {
+		req.OrderId = NewIdempotencyKey()
+	}
+
+	if c.config.DryRun {
+		return dryRunPostStopOrder(req), nil
+	}
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
@@ -862,7 +1773,7 @@ func (c *RealClient) GetStopOrders(ctx context.Context, accountID string, status
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
@@ -883,11 +1794,15 @@ func (c *RealClient) GetStopOrders(ctx context.Context, accountID string, status
 
 // CancelStopOrder cancels a stop order using real API
 func (c *RealClient) CancelStopOrder(ctx context.Context, accountID, stopOrderID string) (*investapi.CancelStopOrderResponse, error) {
+	if c.config.DryRun {
+		return dryRunCancelStopOrder(accountID, stopOrderID), nil
+	}
+
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
@@ -906,13 +1821,23 @@ func (c *RealClient) CancelStopOrder(ctx context.Context, accountID, stopOrderID
 	return resp, nil
 }
 
-// GetMaxLots returns maximum available lots for trading
+// GetMaxLots returns maximum available lots for trading.
+// Results are cached for config.MaxLotsCacheTTL; use RefreshMaxLots to bypass the cache.
 func (c *RealClient) GetMaxLots(ctx context.Context, accountID, instrumentID string, price *float64) (*investapi.GetMaxLotsResponse, error) {
+	if resp, ok := c.cachedMaxLots(newMaxLotsKey(accountID, instrumentID, price)); ok {
+		return resp, nil
+	}
+
+	return c.RefreshMaxLots(ctx, accountID, instrumentID, price)
+}
+
+// RefreshMaxLots fetches maximum available lots from the API and replaces the cached result
+func (c *RealClient) RefreshMaxLots(ctx context.Context, accountID, instrumentID string, price *float64) (*investapi.GetMaxLotsResponse, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
@@ -932,6 +1857,8 @@ func (c *RealClient) GetMaxLots(ctx context.Context, accountID, instrumentID str
 		return nil, fmt.Errorf("failed to get max lots: %w", err)
 	}
 
+	c.storeMaxLots(newMaxLotsKey(accountID, instrumentID, price), resp)
+
 	return resp, nil
 }
 
@@ -941,7 +1868,7 @@ func (c *RealClient) GetOrderPrice(ctx context.Context, accountID, instrumentID
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	// Create context with authorization
@@ -963,17 +1890,56 @@ func (c *RealClient) GetOrderPrice(ctx context.Context, accountID, instrumentID
 	return resp, nil
 }
 
-// ReplaceOrder replaces an existing order
-func (c *RealClient) ReplaceOrder(ctx context.Context, accountID, orderID, newIdempotencyKey string, quantity int64, price *float64) (*investapi.PostOrderResponse, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+// EstimateOrderCost returns the preliminary total cost of an order including
+// commission, the commission alone and the currency of both amounts.
+// Use EstimateOrderCostDetailed for the accrued interest of bonds.
+func (c *RealClient) EstimateOrderCost(ctx context.Context, accountID, instrumentID string, direction investapi.OrderDirection, lots int64, price float64) (total float64, commission float64, currency string, err error) {
+	estimate, err := c.EstimateOrderCostDetailed(ctx, accountID, instrumentID, direction, lots, price)
+	if err != nil {
+		return 0, 0, "", err
+	}
 
-	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+	return estimate.Total, estimate.Commission, estimate.Currency, nil
+}
+
+// OrderCostEstimate is the preliminary cost of an order, see
+// EstimateOrderCostDetailed
+type OrderCostEstimate struct {
+	// Total includes the commission
+	Total      float64
+	Commission float64
+	Currency   string
+	// AccruedInterest is the accrued coupon interest (NKD) a bond buyer pays
+	// on top of the price; it is nil for other instruments
+	AccruedInterest *investapi.MoneyValue
+}
+
+// EstimateOrderCostDetailed returns the same amounts as EstimateOrderCost
+// together with the accrued interest of bonds
+func (c *RealClient) EstimateOrderCostDetailed(ctx context.Context, accountID, instrumentID string, direction investapi.OrderDirection, lots int64, price float64) (OrderCostEstimate, error) {
+	resp, err := c.GetOrderPrice(ctx, accountID, instrumentID, price, direction, lots)
+	if err != nil {
+		return OrderCostEstimate{}, err
 	}
 
-	// Create context with authorization
-	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
+	estimate := OrderCostEstimate{
+		Total:      moneyValueToFloat(resp.TotalOrderAmount),
+		Commission: moneyValueToFloat(resp.ExecutedCommission),
+		Currency:   NormalizedCurrency(resp.TotalOrderAmount),
+	}
+	if bond := resp.GetExtraBond(); bond != nil {
+		estimate.AccruedInterest = bond.AciValue
+	}
+
+	return estimate, nil
+}
+
+// ReplaceOrder replaces an existing order. An empty newIdempotencyKey is
+// replaced with NewIdempotencyKey; pass an explicit key to retry safely.
+func (c *RealClient) ReplaceOrder(ctx context.Context, accountID, orderID, newIdempotencyKey string, quantity int64, price *float64) (*investapi.PostOrderResponse, error) {
+	if newIdempotencyKey == "" {
+		newIdempotencyKey = NewIdempotencyKey()
+	}
 
 	req := &investapi.ReplaceOrderRequest{
 		AccountId:      accountID,
@@ -986,11 +1952,30 @@ func (c *RealClient) ReplaceOrder(ctx context.Context, accountID, orderID, newId
 		req.Price = floatToQuotation(*price)
 	}
 
+	if c.config.DryRun {
+		return dryRunReplaceOrder(req), nil
+	}
+
+	unlock := c.lockAccount(accountID)
+	defer unlock()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.connected {
+		return nil, ErrNotConnected
+	}
+
+	// Create context with authorization
+	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
+
 	resp, err := c.ordersClient.ReplaceOrder(ctxWithAuth, req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to replace order %s: %w", orderID, err)
 	}
 
+	c.invalidateMaxLots(accountID)
+
 	return resp, nil
 }
 
@@ -1000,7 +1985,7 @@ func (c *RealClient) GetStrategies(ctx context.Context, req *investapi.GetStrate
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
@@ -1020,7 +2005,7 @@ func (c *RealClient) GetSignals(ctx context.Context, req *investapi.GetSignalsRe
 	defer c.mu.RUnlock()
 
 	if !c.connected {
-		return nil, fmt.Errorf("client not connected")
+		return nil, ErrNotConnected
 	}
 
 	ctxWithAuth := metadata.NewOutgoingContext(ctx, c.metadata)
@@ -1036,13 +2021,7 @@ func (c *RealClient) GetSignals(ctx context.Context, req *investapi.GetSignalsRe
 
 // Helper function to convert float64 to Quotation
 func floatToQuotation(value float64) *investapi.Quotation {
-	units := int64(value)
-	nano := int32((value - float64(units)) * 1e9)
-
-	return &investapi.Quotation{
-		Units: units,
-		Nano:  nano,
-	}
+	return NewQuotationRounded(value)
 }
 
 // Helper function to convert Quotation to float64
@@ -1052,3 +2031,11 @@ func quotationToFloat(q *investapi.Quotation) float64 {
 	}
 	return float64(q.Units) + float64(q.Nano)/1e9
 }
+
+// Helper function to convert MoneyValue to float64
+func moneyValueToFloat(m *investapi.MoneyValue) float64 {
+	if m == nil {
+		return 0.0
+	}
+	return float64(m.Units) + float64(m.Nano)/1e9
+}
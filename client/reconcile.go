@@ -0,0 +1,116 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// PositionDiff describes a quantity mismatch between portfolio and positions
+// data. Expected and Actual are in instrument units (pieces); ExpectedLots and
+// ActualLots give the same quantities in lots. GetPositions carries no lot
+// size, so it is taken from the portfolio position, and the lot fields are nil
+// for instruments missing from the portfolio. Divide by Instrument.Lot there.
+type PositionDiff struct {
+	Figi string
+	// Expected is the quantity reported by GetPortfolio
+	Expected *investapi.Quotation
+	// Actual is the balance plus blocked quantity reported by GetPositions
+	Actual *investapi.Quotation
+	// Lot is the lot size, zero when it is unknown
+	Lot          int32
+	ExpectedLots *investapi.Quotation
+	ActualLots   *investapi.Quotation
+	// InPortfolio and InPositions report which responses contain the instrument
+	InPortfolio bool
+	InPositions bool
+}
+
+// ReconcilePositions cross-checks portfolio and positions responses by FIGI and
+// returns a diff for every instrument whose quantities disagree, including
+// instruments present in only one of the responses. Currency positions are
+// skipped because GetPositions reports them as money, not securities.
+func ReconcilePositions(portfolio *investapi.PortfolioResponse, positions *investapi.PositionsResponse) ([]PositionDiff, error) {
+	if portfolio == nil || positions == nil {
+		return nil, fmt.Errorf("portfolio and positions are required")
+	}
+
+	// Quantities are summed in nanos so the comparison is exact
+	type quantities struct {
+		expected, actual         int64
+		lot                      int64
+		inPortfolio, inPositions bool
+	}
+	byFigi := make(map[string]*quantities)
+	entry := func(figi string) *quantities {
+		q, ok := byFigi[figi]
+		if !ok {
+			q = &quantities{}
+			byFigi[figi] = q
+		}
+		return q
+	}
+
+	for _, position := range portfolio.Positions {
+		if position.InstrumentType == "currency" {
+			continue
+		}
+		q := entry(position.Figi)
+		q.expected += quotationToNanos(position.Quantity)
+		if lot := portfolioLotSize(position); lot > 0 {
+			q.lot = lot
+		}
+		q.inPortfolio = true
+	}
+
+	for _, security := range positions.Securities {
+		q := entry(security.Figi)
+		q.actual += (security.Balance + security.Blocked) * 1e9
+		q.inPositions = true
+	}
+
+	for _, future := range positions.Futures {
+		q := entry(future.Figi)
+		q.actual += (future.Balance + future.Blocked) * 1e9
+		q.inPositions = true
+	}
+
+	var result []PositionDiff
+	for figi, q := range byFigi {
+		if q.inPortfolio && q.inPositions && q.expected == q.actual {
+			continue
+		}
+		diff := PositionDiff{
+			Figi:        figi,
+			Expected:    nanosToQuotation(q.expected),
+			Actual:      nanosToQuotation(q.actual),
+			InPortfolio: q.inPortfolio,
+			InPositions: q.inPositions,
+		}
+		if q.lot > 0 {
+			diff.Lot = int32(q.lot)
+			diff.ExpectedLots = nanosToQuotation(divRoundNanos(q.expected, q.lot))
+			diff.ActualLots = nanosToQuotation(divRoundNanos(q.actual, q.lot))
+		}
+		result = append(result, diff)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Figi < result[j].Figi
+	})
+
+	return result, nil
+}
+
+// portfolioLotSize derives the lot size from the quantity of a portfolio
+// position in pieces and in lots, or returns 0 when they don't determine it
+func portfolioLotSize(position *investapi.PortfolioPosition) int64 {
+	pieces := quotationToNanos(position.Quantity)
+	// QuantityLots is deprecated but is the only lot size in the response
+	lots := quotationToNanos(position.QuantityLots)
+	if pieces == 0 || lots == 0 || pieces%lots != 0 {
+		return 0
+	}
+	return pieces / lots
+}
@@ -0,0 +1,125 @@
+package client
+
+import (
+	"testing"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func portfolioPosition(figi string, units int64, nano int32) *investapi.PortfolioPosition {
+	return &investapi.PortfolioPosition{
+		Figi:           figi,
+		InstrumentType: "share",
+		Quantity:       &investapi.Quotation{Units: units, Nano: nano},
+	}
+}
+
+func TestReconcilePositions(t *testing.T) {
+	tests := []struct {
+		name      string
+		portfolio []*investapi.PortfolioPosition
+		positions *investapi.PositionsResponse
+		want      []PositionDiff
+	}{
+		{
+			name:      "matching",
+			portfolio: []*investapi.PortfolioPosition{portfolioPosition("SBER", 100, 0), portfolioPosition("SiZ4", 2, 0)},
+			positions: &investapi.PositionsResponse{
+				Securities: []*investapi.PositionsSecurities{{Figi: "SBER", Balance: 90, Blocked: 10}},
+				Futures:    []*investapi.PositionsFutures{{Figi: "SiZ4", Balance: 2}},
+			},
+		},
+		{
+			name: "currency skipped",
+			portfolio: []*investapi.PortfolioPosition{{
+				Figi:           "RUB000UTSTOM",
+				InstrumentType: "currency",
+				Quantity:       &investapi.Quotation{Units: 1000},
+			}},
+			positions: &investapi.PositionsResponse{},
+		},
+		{
+			name:      "mismatched",
+			portfolio: []*investapi.PortfolioPosition{portfolioPosition("SBER", 100, 0), portfolioPosition("GAZP", 10, 0)},
+			positions: &investapi.PositionsResponse{
+				Securities: []*investapi.PositionsSecurities{
+					{Figi: "SBER", Balance: 100},
+					{Figi: "GAZP", Balance: 5},
+					{Figi: "LKOH", Balance: 1},
+				},
+			},
+			want: []PositionDiff{
+				{Figi: "GAZP", Expected: &investapi.Quotation{Units: 10}, Actual: &investapi.Quotation{Units: 5}, InPortfolio: true, InPositions: true},
+				{Figi: "LKOH", Expected: &investapi.Quotation{}, Actual: &investapi.Quotation{Units: 1}, InPositions: true},
+			},
+		},
+		{
+			name:      "fractional difference",
+			portfolio: []*investapi.PortfolioPosition{portfolioPosition("SBER", 100, 1)},
+			positions: &investapi.PositionsResponse{
+				Securities: []*investapi.PositionsSecurities{{Figi: "SBER", Balance: 100}},
+			},
+			want: []PositionDiff{
+				{Figi: "SBER", Expected: &investapi.Quotation{Units: 100, Nano: 1}, Actual: &investapi.Quotation{Units: 100}, InPortfolio: true, InPositions: true},
+			},
+		},
+		{
+			name: "mismatch in lots",
+			portfolio: []*investapi.PortfolioPosition{{
+				Figi:           "SBER",
+				InstrumentType: "share",
+				Quantity:       &investapi.Quotation{Units: 100},
+				QuantityLots:   &investapi.Quotation{Units: 10},
+			}},
+			positions: &investapi.PositionsResponse{
+				Securities: []*investapi.PositionsSecurities{{Figi: "SBER", Balance: 80, Blocked: 10}},
+			},
+			want: []PositionDiff{{
+				Figi:         "SBER",
+				Expected:     &investapi.Quotation{Units: 100},
+				Actual:       &investapi.Quotation{Units: 90},
+				Lot:          10,
+				ExpectedLots: &investapi.Quotation{Units: 10},
+				ActualLots:   &investapi.Quotation{Units: 9},
+				InPortfolio:  true,
+				InPositions:  true,
+			}},
+		},
+		{
+			name:      "missing from positions",
+			portfolio: []*investapi.PortfolioPosition{portfolioPosition("SBER", 100, 0)},
+			positions: &investapi.PositionsResponse{},
+			want: []PositionDiff{
+				{Figi: "SBER", Expected: &investapi.Quotation{Units: 100}, Actual: &investapi.Quotation{}, InPortfolio: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReconcilePositions(&investapi.PortfolioResponse{Positions: tt.portfolio}, tt.positions)
+			if err != nil {
+				t.Fatalf("ReconcilePositions: %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("diffs = %+v, want %+v", got, tt.want)
+			}
+			for i, want := range tt.want {
+				diff := got[i]
+				if diff.Figi != want.Figi || diff.InPortfolio != want.InPortfolio || diff.InPositions != want.InPositions ||
+					!QuotationEqual(diff.Expected, want.Expected) || !QuotationEqual(diff.Actual, want.Actual) ||
+					diff.Lot != want.Lot || !QuotationEqual(diff.ExpectedLots, want.ExpectedLots) ||
+					!QuotationEqual(diff.ActualLots, want.ActualLots) {
+					t.Fatalf("diff %d = %+v, want %+v", i, diff, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReconcilePositionsRequiresBothResponses(t *testing.T) {
+	if _, err := ReconcilePositions(nil, &investapi.PositionsResponse{}); err == nil {
+		t.Fatal("expected an error for a missing portfolio")
+	}
+}
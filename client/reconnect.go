@@ -0,0 +1,151 @@
+package client
+
+import (
+	"log"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+
+	"github.com/buurzx/tinkoff-go/config"
+	"github.com/buurzx/tinkoff-go/internal"
+)
+
+// OnReconnect registers a hook called after a connection is restored
+// following a connection loss. Hooks require config.AutoReconnect and run
+// once per restored pool connection.
+//
+// Only market data sessions are replayed automatically. Order state, trades
+// and other streams opened directly fail on a connection loss and should be
+// reopened by the caller, for example from a hook. StreamPortfolio and
+// StreamPositions resume on their own.
+func (c *RealClient) OnReconnect(hook func()) {
+	c.reconnectMu.Lock()
+	defer c.reconnectMu.Unlock()
+
+	c.reconnectHooks = append(c.reconnectHooks, hook)
+}
+
+// watchConnections starts a watcher for every connection of the pool
+func (c *RealClient) watchConnections() {
+	for _, conn := range c.connections() {
+		go c.watchConnection(conn)
+	}
+}
+
+// connections returns the pool connections, or the single connection when
+// the client is not pooled
+func (c *RealClient) connections() []*grpc.ClientConn {
+	if c.pool != nil {
+		return c.pool.conns
+	}
+	return []*grpc.ClientConn{c.conn}
+}
+
+// watchConnection follows the channel state until the client is closed.
+// When the channel enters TRANSIENT_FAILURE it reconnects immediately instead of
+// waiting for the gRPC backoff, and once the channel is ready again it replays
+// market data sessions and fires the reconnect hooks.
+func (c *RealClient) watchConnection(conn *grpc.ClientConn) {
+	lost := false
+	state := conn.GetState()
+
+	for {
+		switch state {
+		case connectivity.TransientFailure:
+			if !lost {
+				log.Println("⚠️ Connection to Tinkoff API lost, reconnecting...")
+			}
+			lost = true
+			conn.ResetConnectBackoff()
+		case connectivity.Idle:
+			if lost {
+				conn.Connect()
+			}
+		case connectivity.Ready:
+			if lost {
+				lost = false
+				log.Println("🔌 Connection to Tinkoff API restored")
+				c.handleReconnect()
+			}
+		case connectivity.Shutdown:
+			return
+		}
+
+		if !conn.WaitForStateChange(c.ctx, state) {
+			return
+		}
+		state = conn.GetState()
+	}
+}
+
+// handleReconnect replays registered market data sessions and runs reconnect hooks
+func (c *RealClient) handleReconnect() {
+	c.reconnectMu.Lock()
+	sessions := make([]*MarketDataSession, 0, len(c.sessions))
+	for session := range c.sessions {
+		sessions = append(sessions, session)
+	}
+	hooks := append([]func(){}, c.reconnectHooks...)
+	c.reconnectMu.Unlock()
+
+	for _, session := range sessions {
+		if err := c.reconnectSession(session); err != nil {
+			log.Printf("❌ Failed to restore market data session: %v", err)
+		}
+	}
+
+	for _, hook := range hooks {
+		hook()
+	}
+}
+
+// reconnectSession reopens a session, retrying with the config.StreamRetry backoff
+func (c *RealClient) reconnectSession(session *MarketDataSession) error {
+	retry := c.streamRetryConfig()
+
+	var err error
+	for attempt := 0; attempt < retry.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if sleepContext(c.ctx, retry.CalculateBackoff(attempt-1)) != nil {
+				return err
+			}
+		}
+		if err = session.Reconnect(); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// streamRetryConfig returns the stream backoff policy, falling back to the
+// defaults when config.StreamRetry is not set
+func (c *RealClient) streamRetryConfig() *internal.RetryConfig {
+	stream := c.config.StreamRetry
+	if stream.MaxAttempts == 0 {
+		stream = config.DefaultStreamRetryConfig()
+	}
+
+	return &internal.RetryConfig{
+		MaxRetries: stream.MaxAttempts,
+		BaseDelay:  stream.BaseDelay,
+		MaxDelay:   stream.MaxDelay,
+	}
+}
+
+func (c *RealClient) registerSession(session *MarketDataSession) {
+	c.reconnectMu.Lock()
+	defer c.reconnectMu.Unlock()
+
+	if c.sessions == nil {
+		c.sessions = make(map[*MarketDataSession]struct{})
+	}
+	c.sessions[session] = struct{}{}
+}
+
+func (c *RealClient) unregisterSession(session *MarketDataSession) {
+	c.reconnectMu.Lock()
+	defer c.reconnectMu.Unlock()
+
+	delete(c.sessions, session)
+}
@@ -0,0 +1,142 @@
+package client
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/buurzx/tinkoff-go/config"
+)
+
+func TestConnectionsCoversPool(t *testing.T) {
+	dial := func(t *testing.T) *grpc.ClientConn {
+		conn, err := grpc.NewClient("passthrough:///test", grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			t.Fatalf("NewClient: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		return conn
+	}
+
+	tests := []struct {
+		name string
+		size int
+	}{
+		{name: "single connection", size: 1},
+		{name: "pool", size: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			conns := make([]*grpc.ClientConn, tt.size)
+			for i := range conns {
+				conns[i] = dial(t)
+			}
+			c.conn = conns[0]
+			if tt.size > 1 {
+				c.pool = newConnPool(conns)
+			}
+
+			got := c.connections()
+			if len(got) != tt.size {
+				t.Fatalf("connections = %d, want %d", len(got), tt.size)
+			}
+			for i := range conns {
+				if got[i] != conns[i] {
+					t.Fatalf("connection %d is not watched", i)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleReconnectReplaysSessionsAndRunsHooks(t *testing.T) {
+	session, streams := newTestSession(nil)
+	c := session.client
+
+	if err := session.Subscribe(candles("BBG1", oneMinute)); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	var fired atomic.Int32
+	c.OnReconnect(func() { fired.Add(1) })
+
+	c.handleReconnect()
+
+	if got := fired.Load(); got != 1 {
+		t.Fatalf("hooks fired %d times, want 1", got)
+	}
+	if got := subscribedInstruments(streams.stream(1).requests()); got != 1 {
+		t.Fatalf("replayed instruments = %d, want 1", got)
+	}
+}
+
+func TestStreamRetryDelays(t *testing.T) {
+	tests := []struct {
+		name       string
+		retry      config.StreamRetryConfig
+		wantDelays []time.Duration
+	}{
+		{
+			name:       "defaults when unset",
+			wantDelays: []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 30 * time.Second},
+		},
+		{
+			name:       "configured backoff",
+			retry:      config.StreamRetryConfig{MaxAttempts: 4, BaseDelay: 100 * time.Millisecond, MaxDelay: 250 * time.Millisecond},
+			wantDelays: []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 250 * time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(&config.Config{StreamRetry: tt.retry})
+			retry := c.streamRetryConfig()
+
+			for attempt, want := range tt.wantDelays {
+				if got := retry.CalculateBackoff(attempt); got != want {
+					t.Fatalf("delay before attempt %d = %s, want %s", attempt+2, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestReconnectSessionAttempts(t *testing.T) {
+	tests := []struct {
+		name         string
+		openFailures int
+		wantOpens    int
+		wantErr      bool
+	}{
+		{name: "reopened on the first attempt", wantOpens: 2},
+		{name: "reopened on the third attempt", openFailures: 2, wantOpens: 4},
+		{name: "attempts exhausted", openFailures: 3, wantOpens: 4, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, streams := newTestSession(&config.Config{StreamRetry: config.StreamRetryConfig{
+				MaxAttempts: 3,
+				BaseDelay:   time.Millisecond,
+				MaxDelay:    time.Millisecond,
+			}})
+			// The initial stream is already open, so only reopening attempts fail
+			streams.mu.Lock()
+			streams.openFailures = streams.opens + tt.openFailures
+			streams.mu.Unlock()
+
+			err := session.client.reconnectSession(session)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %v", err, tt.wantErr)
+			}
+			if got := streams.openCount(); got != tt.wantOpens {
+				t.Fatalf("stream opens = %d, want %d", got, tt.wantOpens)
+			}
+		})
+	}
+}
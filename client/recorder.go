@@ -0,0 +1,120 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// Recorder writes market data responses to a stream so they can be replayed
+// later with a Player. Each record is the receive time as big-endian Unix
+// nanoseconds followed by the varint length-prefixed protobuf message.
+type Recorder struct {
+	mu  sync.Mutex
+	w   io.Writer
+	now func() time.Time
+}
+
+// NewRecorder creates a recorder writing to w
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{
+		w:   w,
+		now: time.Now,
+	}
+}
+
+// Record appends resp to the recording. It is safe for concurrent use.
+func (r *Recorder) Record(resp *investapi.MarketDataResponse) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var header [8]byte
+	binary.BigEndian.PutUint64(header[:], uint64(r.now().UnixNano()))
+
+	if _, err := r.w.Write(header[:]); err != nil {
+		return fmt.Errorf("failed to write record header: %w", err)
+	}
+	if _, err := protodelim.MarshalTo(r.w, resp); err != nil {
+		return fmt.Errorf("failed to write record: %w", err)
+	}
+
+	return nil
+}
+
+// Player replays a recording made by Recorder
+type Player struct {
+	r     *bufio.Reader
+	speed float64
+}
+
+// NewPlayer creates a player reading from r. The original gaps between
+// messages are multiplied by speed: 1 replays in real time, 0.5 twice as
+// fast and 0 (or less) without any delay.
+func NewPlayer(r io.Reader, speed float64) *Player {
+	return &Player{
+		r:     bufio.NewReader(r),
+		speed: speed,
+	}
+}
+
+// Play calls handler for every recorded message until the recording ends or
+// ctx is cancelled. It returns nil at the end of the recording.
+func (p *Player) Play(ctx context.Context, handler func(*investapi.MarketDataResponse)) error {
+	var prev time.Time
+
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(p.r, header[:]); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("failed to read record header: %w", err)
+		}
+		at := time.Unix(0, int64(binary.BigEndian.Uint64(header[:])))
+
+		resp := &investapi.MarketDataResponse{}
+		if err := protodelim.UnmarshalFrom(p.r, resp); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("failed to read record: %w", err)
+		}
+
+		if !prev.IsZero() && p.speed > 0 {
+			if err := sleepContext(ctx, time.Duration(float64(at.Sub(prev))*p.speed)); err != nil {
+				return err
+			}
+		} else if err := ctx.Err(); err != nil {
+			return err
+		}
+		prev = at
+
+		handler(resp)
+	}
+}
+
+// sleepContext waits for d or until ctx is cancelled
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// recording returns n last price responses recorded an hour apart
+func recording(t *testing.T, n int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	recorder := NewRecorder(&buf)
+	at := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	recorder.now = func() time.Time {
+		at = at.Add(time.Hour)
+		return at
+	}
+
+	for i := 0; i < n; i++ {
+		resp := &investapi.MarketDataResponse{Payload: &investapi.MarketDataResponse_LastPrice{
+			LastPrice: &investapi.LastPrice{Figi: "BBG1", Price: &investapi.Quotation{Units: int64(100 + i)}},
+		}}
+		if err := recorder.Record(resp); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestPlayerReplaysRecording(t *testing.T) {
+	full := recording(t, 3)
+
+	tests := []struct {
+		name       string
+		data       []byte
+		speed      float64
+		cancel     bool
+		wantPrices []int64
+		wantErr    error
+	}{
+		{
+			name:       "speed 0 replays without delay",
+			data:       full,
+			speed:      0,
+			wantPrices: []int64{100, 101, 102},
+		},
+		{
+			name:       "truncated record",
+			data:       full[:len(full)-2],
+			wantPrices: []int64{100, 101},
+			wantErr:    io.ErrUnexpectedEOF,
+		},
+		{
+			name:       "cancelled while waiting for the next message",
+			data:       full,
+			speed:      1,
+			cancel:     true,
+			wantPrices: []int64{100},
+			wantErr:    context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			var prices []int64
+			err := NewPlayer(bytes.NewReader(tt.data), tt.speed).Play(ctx, func(resp *investapi.MarketDataResponse) {
+				prices = append(prices, resp.GetLastPrice().GetPrice().GetUnits())
+				if tt.cancel {
+					cancel()
+				}
+			})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Play = %v, want %v", err, tt.wantErr)
+			}
+			if len(prices) != len(tt.wantPrices) {
+				t.Fatalf("prices = %v, want %v", prices, tt.wantPrices)
+			}
+			for i := range prices {
+				if prices[i] != tt.wantPrices[i] {
+					t.Fatalf("prices = %v, want %v", prices, tt.wantPrices)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/buurzx/tinkoff-go/config"
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// MarketDataProvider is implemented by both the gRPC and REST transports,
+// so code can switch between them
+type MarketDataProvider interface {
+	GetCandles(ctx context.Context, figi string, from, to time.Time, interval investapi.CandleInterval) (*investapi.GetCandlesResponse, error)
+	GetLastPrices(ctx context.Context, figis []string) (*investapi.GetLastPricesResponse, error)
+}
+
+var (
+	_ MarketDataProvider = (*RealClient)(nil)
+	_ MarketDataProvider = (*RESTClient)(nil)
+)
+
+// marketDataRESTService is the REST path prefix of the market data service
+const marketDataRESTService = "tinkoff.public.invest.api.contract.v1.MarketDataService"
+
+// RESTClient is a market data client for the Tinkoff REST API.
+// It is meant for networks where the gRPC endpoint is not reachable.
+type RESTClient struct {
+	config     *config.Config
+	httpClient *http.Client
+}
+
+// NewRESTClient creates a REST market data client with provided config
+func NewRESTClient(cfg *config.Config) (*RESTClient, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if cfg.RESTURL == "" {
+		return nil, fmt.Errorf("REST URL is required")
+	}
+
+	rootCAs, err := cfg.CertPool()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load root certificates: %w", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: rootCAs}
+
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = config.DefaultRESTTimeout
+	}
+
+	return &RESTClient{
+		config: cfg,
+		httpClient: &http.Client{
+			Timeout:   timeout,
+			Transport: transport,
+		},
+	}, nil
+}
+
+// GetCandles returns historical candles using REST API
+func (c *RESTClient) GetCandles(ctx context.Context, figi string, from, to time.Time, interval investapi.CandleInterval) (*investapi.GetCandlesResponse, error) {
+	if err := validateCandleInterval(interval); err != nil {
+		return nil, fmt.Errorf("failed to get candles for %s: %w", figi, err)
+	}
+
+	req := &investapi.GetCandlesRequest{
+		Figi:     &figi,
+		From:     timestamppb.New(from),
+		To:       timestamppb.New(to),
+		Interval: interval,
+	}
+
+	resp := &investapi.GetCandlesResponse{}
+	if err := c.call(ctx, marketDataRESTService, "GetCandles", req, resp); err != nil {
+		return nil, fmt.Errorf("failed to get candles for %s: %w", figi, err)
+	}
+
+	return resp, nil
+}
+
+// GetLastPrices returns last prices for given FIGIs using REST API
+func (c *RESTClient) GetLastPrices(ctx context.Context, figis []string) (*investapi.GetLastPricesResponse, error) {
+	req := &investapi.GetLastPricesRequest{
+		Figi: figis,
+	}
+
+	resp := &investapi.GetLastPricesResponse{}
+	if err := c.call(ctx, marketDataRESTService, "GetLastPrices", req, resp); err != nil {
+		return nil, fmt.Errorf("failed to get last prices: %w", err)
+	}
+
+	return resp, nil
+}
+
+// restError is the error body returned by the REST API. Code is a gRPC
+// status code and Message the API error code, as in gRPC responses.
+type restError struct {
+	Code        int    `json:"code"`
+	Message     string `json:"message"`
+	Description string `json:"description"`
+}
+
+// call posts req as JSON to the service method and decodes the response into
+// resp. Errors carry a gRPC status, so code checks such as status.Code treat
+// both transports alike.
+func (c *RESTClient) call(ctx context.Context, service, method string, req, resp proto.Message) error {
+	body, err := protojson.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	url := strings.TrimSuffix(c.config.RESTURL, "/") + "/" + service + "/" + method
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Authorization", "Bearer "+c.config.Token)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+	if c.config.UserAgent != "" {
+		httpReq.Header.Set("User-Agent", c.config.UserAgent)
+	}
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return status.Errorf(codes.DeadlineExceeded, "request failed: %v", err)
+		}
+		return status.Errorf(codes.Unavailable, "request failed: %v", err)
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		return restStatusError(httpResp.StatusCode, data)
+	}
+
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, resp); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// restStatusError converts a failed REST response to a gRPC status error. The
+// status code comes from the error body when it carries a valid gRPC code and
+// from the HTTP status otherwise.
+func restStatusError(httpStatus int, body []byte) error {
+	var apiErr restError
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Message != "" {
+		code := httpStatusCode(httpStatus)
+		if apiErr.Code > 0 && apiErr.Code <= int(codes.Unauthenticated) {
+			code = codes.Code(apiErr.Code)
+		}
+		err := status.Error(code, apiErr.Message)
+		if apiErr.Description != "" {
+			return fmt.Errorf("status %d: %w: %s", httpStatus, err, apiErr.Description)
+		}
+		return fmt.Errorf("status %d: %w", httpStatus, err)
+	}
+	return status.Errorf(httpStatusCode(httpStatus), "status %d: %s", httpStatus, strings.TrimSpace(string(body)))
+}
+
+// httpStatusCode maps an HTTP status to the gRPC code the gRPC transport
+// reports for the same failure
+func httpStatusCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusInternalServerError:
+		return codes.Internal
+	}
+	return codes.Unknown
+}
@@ -0,0 +1,248 @@
+package client
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/buurzx/tinkoff-go/config"
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestRESTClientGetCandles(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantErr  string
+		wantCode codes.Code
+	}{
+		{
+			name:   "canned candle",
+			status: http.StatusOK,
+			body:   `{"candles":[{"open":{"units":"100","nano":500000000},"close":{"units":"101"},"volume":"42","isComplete":true,"unknownField":1}]}`,
+		},
+		{
+			name:     "api error",
+			status:   http.StatusUnauthorized,
+			body:     `{"code":16,"message":"authentication token is missing or invalid"}`,
+			wantErr:  "authentication token is missing or invalid",
+			wantCode: codes.Unauthenticated,
+		},
+		{
+			name:     "api error code",
+			status:   http.StatusBadRequest,
+			body:     `{"code":3,"message":"30014","description":"the maximum request period for the candle interval has been exceeded"}`,
+			wantErr:  "30014",
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name:     "code from the HTTP status",
+			status:   http.StatusTooManyRequests,
+			body:     `{"message":"80002"}`,
+			wantErr:  "80002",
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			name:     "plain error",
+			status:   http.StatusBadGateway,
+			body:     "bad gateway\n",
+			wantErr:  "status 502: bad gateway",
+			wantCode: codes.Unavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotPath, gotAuth, gotBody string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				gotAuth = r.Header.Get("Authorization")
+				body, _ := io.ReadAll(r.Body)
+				gotBody = string(body)
+				w.WriteHeader(tt.status)
+				_, _ = io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+
+			client, err := NewRESTClient(&config.Config{
+				Token:     "test-token",
+				ServerURL: "localhost:0",
+				RESTURL:   server.URL + "/",
+			})
+			if err != nil {
+				t.Fatalf("NewRESTClient: %v", err)
+			}
+
+			from := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+			resp, err := client.GetCandles(context.Background(), "BBG1", from, from.Add(time.Hour),
+				investapi.CandleInterval_CANDLE_INTERVAL_1_MIN)
+
+			if wantPath := "/" + marketDataRESTService + "/GetCandles"; gotPath != wantPath {
+				t.Fatalf("path = %q, want %q", gotPath, wantPath)
+			}
+			if gotAuth != "Bearer test-token" {
+				t.Fatalf("Authorization = %q, want bearer token", gotAuth)
+			}
+			if !strings.Contains(gotBody, `"figi":"BBG1"`) {
+				t.Fatalf("request body = %s, want figi BBG1", gotBody)
+			}
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				if got := status.Code(err); got != tt.wantCode {
+					t.Fatalf("code = %v, want %v", got, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetCandles: %v", err)
+			}
+			if len(resp.GetCandles()) != 1 {
+				t.Fatalf("candles = %d, want 1", len(resp.GetCandles()))
+			}
+			candle := resp.GetCandles()[0]
+			if got := quotationToFloat(candle.GetOpen()); got != 100.5 {
+				t.Fatalf("open = %v, want 100.5", got)
+			}
+			if candle.GetVolume() != 42 || !candle.GetIsComplete() {
+				t.Fatalf("candle = %v, want volume 42 and complete", candle)
+			}
+		})
+	}
+}
+
+func TestRESTClientPinnedRootCAs(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	// The mismatched pin makes the server log a failed handshake
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	serverPool := x509.NewCertPool()
+	serverPool.AddCert(server.Certificate())
+
+	tests := []struct {
+		name    string
+		cfg     func(c *config.Config)
+		wantErr string
+	}{
+		{
+			name: "pinned server certificate",
+			cfg:  func(c *config.Config) { c.RootCAs = serverPool },
+		},
+		{
+			name: "pinned PEM",
+			cfg: func(c *config.Config) {
+				c.RootCAsPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+			},
+		},
+		{
+			name:    "mismatched pin",
+			cfg:     func(c *config.Config) { c.RootCAs = x509.NewCertPool() },
+			wantErr: "certificate",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Token: "test-token", ServerURL: "localhost:0", RESTURL: server.URL}
+			tt.cfg(cfg)
+
+			client, err := NewRESTClient(cfg)
+			if err != nil {
+				t.Fatalf("NewRESTClient: %v", err)
+			}
+
+			_, err = client.GetLastPrices(context.Background(), []string{"BBG1"})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("GetLastPrices: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewRESTClientRejectsInvalidPEM(t *testing.T) {
+	_, err := NewRESTClient(&config.Config{
+		Token:      "test-token",
+		ServerURL:  "localhost:0",
+		RESTURL:    "https://localhost",
+		RootCAsPEM: []byte("not a certificate"),
+	})
+	if err == nil {
+		t.Fatal("NewRESTClient accepted an invalid PEM")
+	}
+}
+
+func TestRESTClientTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+		_, _ = io.WriteString(w, `{}`)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	tests := []struct {
+		name     string
+		ctx      func() (context.Context, context.CancelFunc)
+		wantCode codes.Code
+	}{
+		{
+			name:     "config timeout",
+			ctx:      func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			wantCode: codes.DeadlineExceeded,
+		},
+		{
+			name: "caller cancels",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			wantCode: codes.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := NewRESTClient(&config.Config{
+				Token:     "test-token",
+				ServerURL: "localhost:0",
+				RESTURL:   server.URL,
+				Timeout:   50 * time.Millisecond,
+			})
+			if err != nil {
+				t.Fatalf("NewRESTClient: %v", err)
+			}
+
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			_, err = client.GetLastPrices(ctx, []string{"BBG1"})
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("code = %v (%v), want %v", got, err, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"log"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/buurzx/tinkoff-go/internal"
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// retryableMethods lists the read-only unary methods that are safe to repeat.
+// Methods that place, replace or cancel orders are never retried.
+var retryableMethods = map[string]struct{}{
+	investapi.UsersService_GetAccounts_FullMethodName:                    {},
+	investapi.UsersService_GetInfo_FullMethodName:                        {},
+	investapi.UsersService_GetUserTariff_FullMethodName:                  {},
+	investapi.UsersService_GetMarginAttributes_FullMethodName:            {},
+	investapi.InstrumentsService_GetInstrumentBy_FullMethodName:          {},
+	investapi.InstrumentsService_FindInstrument_FullMethodName:           {},
+	investapi.InstrumentsService_Bonds_FullMethodName:                    {},
+	investapi.InstrumentsService_GetBondCoupons_FullMethodName:           {},
+	investapi.InstrumentsService_GetBondEvents_FullMethodName:            {},
+	investapi.InstrumentsService_GetAssetBy_FullMethodName:               {},
+	investapi.InstrumentsService_GetAssetFundamentals_FullMethodName:     {},
+	investapi.InstrumentsService_GetFuturesMargin_FullMethodName:         {},
+	investapi.InstrumentsService_GetDividends_FullMethodName:             {},
+	investapi.InstrumentsService_GetBrands_FullMethodName:                {},
+	investapi.InstrumentsService_GetBrandBy_FullMethodName:               {},
+	investapi.InstrumentsService_GetAccruedInterests_FullMethodName:      {},
+	investapi.InstrumentsService_TradingSchedules_FullMethodName:         {},
+	investapi.MarketDataService_GetCandles_FullMethodName:                {},
+	investapi.MarketDataService_GetLastPrices_FullMethodName:             {},
+	investapi.MarketDataService_GetClosePrices_FullMethodName:            {},
+	investapi.MarketDataService_GetOrderBook_FullMethodName:              {},
+	investapi.MarketDataService_GetLastTrades_FullMethodName:             {},
+	investapi.MarketDataService_GetTradingStatus_FullMethodName:          {},
+	investapi.OperationsService_GetPortfolio_FullMethodName:              {},
+	investapi.OperationsService_GetPositions_FullMethodName:              {},
+	investapi.OperationsService_GetOperationsByCursor_FullMethodName:     {},
+	investapi.OperationsService_GetBrokerReport_FullMethodName:           {},
+	investapi.OrdersService_GetOrders_FullMethodName:                     {},
+	investapi.OrdersService_GetOrderState_FullMethodName:                 {},
+	investapi.OrdersService_GetMaxLots_FullMethodName:                    {},
+	investapi.OrdersService_GetOrderPrice_FullMethodName:                 {},
+	investapi.StopOrdersService_GetStopOrders_FullMethodName:             {},
+	investapi.SignalService_GetStrategies_FullMethodName:                 {},
+	investapi.SignalService_GetSignals_FullMethodName:                    {},
+	investapi.SandboxService_GetSandboxAccounts_FullMethodName:           {},
+	investapi.SandboxService_GetSandboxPortfolio_FullMethodName:          {},
+	investapi.SandboxService_GetSandboxPositions_FullMethodName:          {},
+	investapi.SandboxService_GetSandboxOrders_FullMethodName:             {},
+	investapi.SandboxService_GetSandboxOrderState_FullMethodName:         {},
+	investapi.SandboxService_GetSandboxOperationsByCursor_FullMethodName: {},
+	investapi.SandboxService_GetSandboxMaxLots_FullMethodName:            {},
+}
+
+// retryInterceptor repeats retryable methods that fail with a transient error,
+// waiting with exponential backoff between attempts
+func (c *RealClient) retryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if _, ok := retryableMethods[method]; !ok {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	retry := internal.DefaultRetryConfig()
+	retry.MaxRetries = c.config.MaxRetries
+
+	for attempt := 0; ; attempt++ {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if err == nil || attempt >= retry.MaxRetries || !isTransientError(ctx, err) {
+			return err
+		}
+
+		delay := retry.CalculateBackoff(attempt)
+		log.Printf("🔄 %s failed with %s, retrying in %s", method, status.Code(err), delay)
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// isTransientError reports whether a failed call may succeed when repeated.
+// A deadline error caused by the caller's own context is not transient.
+func isTransientError(ctx context.Context, err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable:
+		return true
+	case codes.DeadlineExceeded:
+		return ctx.Err() == nil
+	default:
+		return false
+	}
+}
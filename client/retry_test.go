@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/buurzx/tinkoff-go/config"
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestRetryInterceptor(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+		errs       []codes.Code
+		wantCalls  int
+		wantErr    bool
+	}{
+		{name: "succeeds on the second attempt", maxRetries: 2, errs: []codes.Code{codes.Unavailable}, wantCalls: 2},
+		{name: "retries disabled", errs: []codes.Code{codes.Unavailable}, wantCalls: 1, wantErr: true},
+		{name: "permanent error", maxRetries: 2, errs: []codes.Code{codes.NotFound}, wantCalls: 1, wantErr: true},
+		{
+			name:       "retries exhausted",
+			maxRetries: 1,
+			errs:       []codes.Code{codes.Unavailable, codes.Unavailable},
+			wantCalls:  2,
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &usersServer{}
+			users.accounts = func(context.Context) (*investapi.GetAccountsResponse, error) {
+				if call := users.callCount(); call <= len(tt.errs) {
+					return nil, status.Error(tt.errs[call-1], "failed")
+				}
+				return &investapi.GetAccountsResponse{Accounts: []*investapi.Account{{Id: "acc"}}}, nil
+			}
+			c := newServerClient(t, &config.Config{MaxRetries: tt.maxRetries}, func(srv *grpc.Server) {
+				investapi.RegisterUsersServiceServer(srv, users)
+			})
+
+			_, err := c.GetAccounts(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error: %v", err, tt.wantErr)
+			}
+			if got := users.callCount(); got != tt.wantCalls {
+				t.Fatalf("calls = %d, want %d", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestOrderMethodsAreNotRetried(t *testing.T) {
+	for _, method := range []string{
+		investapi.OrdersService_PostOrder_FullMethodName,
+		investapi.OrdersService_CancelOrder_FullMethodName,
+		investapi.OrdersService_ReplaceOrder_FullMethodName,
+		investapi.StopOrdersService_PostStopOrder_FullMethodName,
+		investapi.StopOrdersService_CancelStopOrder_FullMethodName,
+	} {
+		if _, ok := retryableMethods[method]; ok {
+			t.Errorf("%s must not be retried", method)
+		}
+	}
+}
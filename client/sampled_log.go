@@ -0,0 +1,102 @@
+package client
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SampledLogger limits logging of high-frequency stream messages to one line
+// per interval for every message kind and instrument. Suppressed messages are
+// counted and reported with the next logged line and by Summary.
+type SampledLogger struct {
+	interval time.Duration
+	logf     func(format string, args ...any)
+	now      func() time.Time
+
+	mu      sync.Mutex
+	samples map[sampleKey]*sample
+}
+
+type sampleKey struct {
+	kind         string
+	instrumentID string
+}
+
+type sample struct {
+	lastLogged time.Time
+	suppressed int
+	total      int
+	logged     int
+}
+
+// NewSampledLogger creates a sampled logger writing to the standard logger.
+// A zero interval logs every message.
+func NewSampledLogger(interval time.Duration) *SampledLogger {
+	return &SampledLogger{
+		interval: interval,
+		logf:     log.Printf,
+		now:      time.Now,
+		samples:  make(map[sampleKey]*sample),
+	}
+}
+
+// Printf logs the message unless a message of the same kind for the same
+// instrument was logged less than the interval ago. It reports whether the
+// line was written.
+func (l *SampledLogger) Printf(kind, instrumentID, format string, args ...any) bool {
+	l.mu.Lock()
+	key := sampleKey{kind: kind, instrumentID: instrumentID}
+	s, ok := l.samples[key]
+	if !ok {
+		s = &sample{}
+		l.samples[key] = s
+	}
+	s.total++
+
+	now := l.now()
+	if !s.lastLogged.IsZero() && now.Sub(s.lastLogged) < l.interval {
+		s.suppressed++
+		l.mu.Unlock()
+		return false
+	}
+
+	suppressed := s.suppressed
+	s.suppressed = 0
+	s.lastLogged = now
+	s.logged++
+	l.mu.Unlock()
+
+	msg := fmt.Sprintf(format, args...)
+	if suppressed > 0 {
+		msg = fmt.Sprintf("%s (+%d suppressed)", msg, suppressed)
+	}
+	l.logf("%s", msg)
+
+	return true
+}
+
+// Summary logs how many messages of every kind and instrument were received
+// and how many of them were written
+func (l *SampledLogger) Summary() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	keys := make([]sampleKey, 0, len(l.samples))
+	for key := range l.samples {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].kind != keys[j].kind {
+			return keys[i].kind < keys[j].kind
+		}
+		return keys[i].instrumentID < keys[j].instrumentID
+	})
+
+	for _, key := range keys {
+		s := l.samples[key]
+		l.logf("📉 %s %s: %d messages, %d logged", key.kind, key.instrumentID, s.total, s.logged)
+	}
+}
@@ -0,0 +1,86 @@
+package client
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSampledLogger(t *testing.T) {
+	type message struct {
+		at         time.Duration
+		kind       string
+		instrument string
+	}
+
+	tests := []struct {
+		name      string
+		interval  time.Duration
+		messages  []message
+		wantLines []string
+	}{
+		{
+			name:     "suppressed within the interval",
+			interval: time.Second,
+			messages: []message{
+				{0, "candle", "BBG1"},
+				{300 * time.Millisecond, "candle", "BBG1"},
+				{600 * time.Millisecond, "candle", "BBG1"},
+				{1200 * time.Millisecond, "candle", "BBG1"},
+			},
+			wantLines: []string{"candle BBG1", "candle BBG1 (+2 suppressed)"},
+		},
+		{
+			name:     "kinds and instruments sampled separately",
+			interval: time.Second,
+			messages: []message{
+				{0, "candle", "BBG1"},
+				{0, "candle", "BBG2"},
+				{0, "trade", "BBG1"},
+			},
+			wantLines: []string{"candle BBG1", "candle BBG2", "trade BBG1"},
+		},
+		{
+			name:      "zero interval logs everything",
+			messages:  []message{{0, "candle", "BBG1"}, {0, "candle", "BBG1"}},
+			wantLines: []string{"candle BBG1", "candle BBG1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+			var now time.Time
+			var lines []string
+
+			logger := NewSampledLogger(tt.interval)
+			logger.now = func() time.Time { return now }
+			logger.logf = func(format string, args ...any) { lines = append(lines, fmt.Sprintf(format, args...)) }
+
+			for _, msg := range tt.messages {
+				now = start.Add(msg.at)
+				logger.Printf(msg.kind, msg.instrument, "%s %s", msg.kind, msg.instrument)
+			}
+
+			if fmt.Sprintf("%q", lines) != fmt.Sprintf("%q", tt.wantLines) {
+				t.Fatalf("lines = %q, want %q", lines, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestSampledLoggerSummary(t *testing.T) {
+	var lines []string
+	logger := NewSampledLogger(time.Hour)
+	logger.logf = func(format string, args ...any) { lines = append(lines, fmt.Sprintf(format, args...)) }
+
+	for i := 0; i < 3; i++ {
+		logger.Printf("candle", "BBG1", "candle")
+	}
+	lines = nil
+	logger.Summary()
+
+	if len(lines) != 1 || lines[0] != "📉 candle BBG1: 3 messages, 1 logged" {
+		t.Fatalf("summary = %q, want one line with 3 messages and 1 logged", lines)
+	}
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// sandboxOrdersClient sends order calls to SandboxService, so demo clients
+// exercise the sandbox order flow. Calls the sandbox has no counterpart for,
+// such as GetOrderPrice, go to the embedded OrdersService client.
+type sandboxOrdersClient struct {
+	investapi.OrdersServiceClient
+	sandbox investapi.SandboxServiceClient
+}
+
+var _ investapi.OrdersServiceClient = (*sandboxOrdersClient)(nil)
+
+func newSandboxOrdersClient(cc grpc.ClientConnInterface) *sandboxOrdersClient {
+	return &sandboxOrdersClient{
+		OrdersServiceClient: investapi.NewOrdersServiceClient(cc),
+		sandbox:             investapi.NewSandboxServiceClient(cc),
+	}
+}
+
+func (s *sandboxOrdersClient) PostOrder(ctx context.Context, in *investapi.PostOrderRequest, opts ...grpc.CallOption) (*investapi.PostOrderResponse, error) {
+	return s.sandbox.PostSandboxOrder(ctx, in, opts...)
+}
+
+func (s *sandboxOrdersClient) PostOrderAsync(ctx context.Context, in *investapi.PostOrderAsyncRequest, opts ...grpc.CallOption) (*investapi.PostOrderAsyncResponse, error) {
+	return s.sandbox.PostSandboxOrderAsync(ctx, in, opts...)
+}
+
+func (s *sandboxOrdersClient) CancelOrder(ctx context.Context, in *investapi.CancelOrderRequest, opts ...grpc.CallOption) (*investapi.CancelOrderResponse, error) {
+	return s.sandbox.CancelSandboxOrder(ctx, in, opts...)
+}
+
+func (s *sandboxOrdersClient) GetOrderState(ctx context.Context, in *investapi.GetOrderStateRequest, opts ...grpc.CallOption) (*investapi.OrderState, error) {
+	return s.sandbox.GetSandboxOrderState(ctx, in, opts...)
+}
+
+func (s *sandboxOrdersClient) GetOrders(ctx context.Context, in *investapi.GetOrdersRequest, opts ...grpc.CallOption) (*investapi.GetOrdersResponse, error) {
+	return s.sandbox.GetSandboxOrders(ctx, in, opts...)
+}
+
+func (s *sandboxOrdersClient) ReplaceOrder(ctx context.Context, in *investapi.ReplaceOrderRequest, opts ...grpc.CallOption) (*investapi.PostOrderResponse, error) {
+	return s.sandbox.ReplaceSandboxOrder(ctx, in, opts...)
+}
+
+func (s *sandboxOrdersClient) GetMaxLots(ctx context.Context, in *investapi.GetMaxLotsRequest, opts ...grpc.CallOption) (*investapi.GetMaxLotsResponse, error) {
+	return s.sandbox.GetSandboxMaxLots(ctx, in, opts...)
+}
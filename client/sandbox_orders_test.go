@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// sandboxServer records the sandbox order calls it receives
+type sandboxServer struct {
+	investapi.UnimplementedSandboxServiceServer
+
+	mu    sync.Mutex
+	calls []string
+}
+
+func (s *sandboxServer) called(method string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls = append(s.calls, method)
+}
+
+func (s *sandboxServer) PostSandboxOrder(_ context.Context, req *investapi.PostOrderRequest) (*investapi.PostOrderResponse, error) {
+	s.called("PostSandboxOrder")
+	return &investapi.PostOrderResponse{OrderId: req.OrderId}, nil
+}
+
+func (s *sandboxServer) CancelSandboxOrder(context.Context, *investapi.CancelOrderRequest) (*investapi.CancelOrderResponse, error) {
+	s.called("CancelSandboxOrder")
+	return &investapi.CancelOrderResponse{}, nil
+}
+
+func (s *sandboxServer) GetSandboxOrders(context.Context, *investapi.GetOrdersRequest) (*investapi.GetOrdersResponse, error) {
+	s.called("GetSandboxOrders")
+	return &investapi.GetOrdersResponse{}, nil
+}
+
+// ordersServer only answers GetOrderPrice, which has no sandbox counterpart
+type ordersServer struct {
+	investapi.UnimplementedOrdersServiceServer
+}
+
+func (ordersServer) GetOrderPrice(context.Context, *investapi.GetOrderPriceRequest) (*investapi.GetOrderPriceResponse, error) {
+	return &investapi.GetOrderPriceResponse{}, nil
+}
+
+func TestSandboxOrdersRouting(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		call     func(c *RealClient) error
+		wantCall string
+	}{
+		{
+			name: "PostOrder",
+			call: func(c *RealClient) error {
+				_, err := c.PostOrder(ctx, &investapi.PostOrderRequest{AccountId: "acc", InstrumentId: "BBG1", Quantity: 1})
+				return err
+			},
+			wantCall: "PostSandboxOrder",
+		},
+		{
+			name:     "CancelOrder",
+			call:     func(c *RealClient) error { _, err := c.CancelOrder(ctx, "acc", "order"); return err },
+			wantCall: "CancelSandboxOrder",
+		},
+		{
+			name:     "GetOrders",
+			call:     func(c *RealClient) error { _, err := c.GetOrders(ctx, "acc"); return err },
+			wantCall: "GetSandboxOrders",
+		},
+		{
+			name: "GetOrderPrice stays on OrdersService",
+			call: func(c *RealClient) error {
+				_, _, _, err := c.EstimateOrderCost(ctx, "acc", "BBG1", investapi.OrderDirection_ORDER_DIRECTION_BUY, 1, 100)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sandbox := &sandboxServer{}
+			c := newServerClient(t, nil, func(srv *grpc.Server) {
+				investapi.RegisterSandboxServiceServer(srv, sandbox)
+				investapi.RegisterOrdersServiceServer(srv, ordersServer{})
+			})
+			c.ordersClient = newSandboxOrdersClient(c.conn)
+
+			if err := tt.call(c); err != nil {
+				t.Fatalf("call: %v", err)
+			}
+
+			sandbox.mu.Lock()
+			defer sandbox.mu.Unlock()
+			if tt.wantCall == "" {
+				if len(sandbox.calls) != 0 {
+					t.Fatalf("sandbox calls = %v, want none", sandbox.calls)
+				}
+				return
+			}
+			if len(sandbox.calls) != 1 || sandbox.calls[0] != tt.wantCall {
+				t.Fatalf("sandbox calls = %v, want [%s]", sandbox.calls, tt.wantCall)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/buurzx/tinkoff-go/internal"
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// IsMarketOpen reports whether at falls within the main or evening trading session
+// of the exchange schedule. Pre-market and auctions are treated as closed, since
+// continuous trading is not available during them.
+func IsMarketOpen(schedule *investapi.TradingSchedule, at time.Time) bool {
+	day := tradingDayAt(schedule, at)
+	if day == nil || !day.IsTradingDay {
+		return false
+	}
+
+	return withinInterval(at, day.StartTime, day.EndTime) ||
+		withinInterval(at, day.EveningStartTime, day.EveningEndTime)
+}
+
+// tradingDayAt returns the schedule day matching the Moscow calendar date of at
+func tradingDayAt(schedule *investapi.TradingSchedule, at time.Time) *investapi.TradingDay {
+	if schedule == nil {
+		return nil
+	}
+
+	year, month, day := at.In(internal.MoscowTZ).Date()
+	for _, tradingDay := range schedule.Days {
+		if tradingDay.Date == nil {
+			continue
+		}
+		y, m, d := tradingDay.Date.AsTime().In(internal.MoscowTZ).Date()
+		if y == year && m == month && d == day {
+			return tradingDay
+		}
+	}
+
+	return nil
+}
+
+// withinInterval reports whether at is in [start, end). Missing bounds mean no interval.
+func withinInterval(at time.Time, start, end *timestamppb.Timestamp) bool {
+	if start == nil || end == nil {
+		return false
+	}
+	return !at.Before(start.AsTime()) && at.Before(end.AsTime())
+}
+
+// tradingSchedulesMaxRange is the longest period requested from
+// TradingSchedules at once
+const tradingSchedulesMaxRange = 14 * 24 * time.Hour
+
+// TradingDaysInRange returns the trading days of the exchange between from and
+// to as Moscow midnights in ascending order. Weekends and holidays are left
+// out, so callers can skip requests for days without trading.
+func (c *RealClient) TradingDaysInRange(ctx context.Context, exchange string, from, to time.Time) ([]time.Time, error) {
+	if exchange == "" {
+		return nil, fmt.Errorf("exchange is required")
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("invalid range: %s is before %s", to.Format(time.RFC3339), from.Format(time.RFC3339))
+	}
+
+	var days []time.Time
+	seen := make(map[time.Time]struct{})
+
+	for start := from; !start.After(to); start = start.Add(tradingSchedulesMaxRange) {
+		end := start.Add(tradingSchedulesMaxRange)
+		if end.After(to) {
+			end = to
+		}
+
+		resp, err := c.GetTradingSchedules(ctx, exchange, start, end)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, schedule := range resp.Exchanges {
+			for _, day := range TradingDays(schedule) {
+				if _, ok := seen[day]; ok || !withinDays(day, from, to) {
+					continue
+				}
+				seen[day] = struct{}{}
+				days = append(days, day)
+			}
+		}
+
+		if !end.Before(to) {
+			break
+		}
+	}
+
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days, nil
+}
+
+// TradingDays returns the days of the schedule marked as trading days as
+// Moscow midnights
+func TradingDays(schedule *investapi.TradingSchedule) []time.Time {
+	if schedule == nil {
+		return nil
+	}
+
+	var days []time.Time
+	for _, day := range schedule.Days {
+		if day.Date == nil || !day.IsTradingDay {
+			continue
+		}
+		days = append(days, moscowDate(day.Date.AsTime()))
+	}
+	return days
+}
+
+// moscowDate returns midnight of the Moscow calendar date of t
+func moscowDate(t time.Time) time.Time {
+	year, month, day := t.In(internal.MoscowTZ).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, internal.MoscowTZ)
+}
+
+// withinDays reports whether day falls on a Moscow calendar date between from and to
+func withinDays(day, from, to time.Time) bool {
+	return !day.Before(moscowDate(from)) && !day.After(moscowDate(to))
+}
+
+// TradingStatusAllowsLimit reports whether limit orders are accepted in the
+// trading status. Auctions accept limit orders, which are matched at the
+// auction price; breaks and closed sessions do not.
+func TradingStatusAllowsLimit(status investapi.SecurityTradingStatus) bool {
+	switch status {
+	case investapi.SecurityTradingStatus_SECURITY_TRADING_STATUS_NORMAL_TRADING,
+		investapi.SecurityTradingStatus_SECURITY_TRADING_STATUS_DEALER_NORMAL_TRADING,
+		investapi.SecurityTradingStatus_SECURITY_TRADING_STATUS_SESSION_OPEN,
+		investapi.SecurityTradingStatus_SECURITY_TRADING_STATUS_OPENING_AUCTION_PERIOD,
+		investapi.SecurityTradingStatus_SECURITY_TRADING_STATUS_CLOSING_AUCTION,
+		investapi.SecurityTradingStatus_SECURITY_TRADING_STATUS_DISCRETE_AUCTION,
+		investapi.SecurityTradingStatus_SECURITY_TRADING_STATUS_TRADING_AT_CLOSING_AUCTION_PRICE:
+		return true
+	default:
+		return false
+	}
+}
+
+// TradingStatusAllowsMarket reports whether market orders are accepted in the
+// trading status. They are only accepted during continuous trading.
+func TradingStatusAllowsMarket(status investapi.SecurityTradingStatus) bool {
+	switch status {
+	case investapi.SecurityTradingStatus_SECURITY_TRADING_STATUS_NORMAL_TRADING,
+		investapi.SecurityTradingStatus_SECURITY_TRADING_STATUS_DEALER_NORMAL_TRADING,
+		investapi.SecurityTradingStatus_SECURITY_TRADING_STATUS_SESSION_OPEN:
+		return true
+	default:
+		return false
+	}
+}
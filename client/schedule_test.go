@@ -0,0 +1,160 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/buurzx/tinkoff-go/internal"
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// moscow returns a timestamp of the given Moscow time on 2024-03-04, a Monday
+func moscow(hour, minute int) time.Time {
+	return time.Date(2024, 3, 4, hour, minute, 0, 0, internal.MoscowTZ)
+}
+
+func tradingDay(date time.Time, trading bool) *investapi.TradingDay {
+	day := &investapi.TradingDay{
+		Date:         timestamppb.New(date),
+		IsTradingDay: trading,
+	}
+	if trading {
+		day.PremarketStartTime = timestamppb.New(moscow(7, 0))
+		day.PremarketEndTime = timestamppb.New(moscow(9, 50))
+		day.StartTime = timestamppb.New(moscow(10, 0))
+		day.EndTime = timestamppb.New(moscow(18, 40))
+		day.EveningStartTime = timestamppb.New(moscow(19, 5))
+		day.EveningEndTime = timestamppb.New(moscow(23, 50))
+	}
+	return day
+}
+
+func TestIsMarketOpen(t *testing.T) {
+	schedule := &investapi.TradingSchedule{
+		Days: []*investapi.TradingDay{tradingDay(moscow(0, 0), true)},
+	}
+
+	tests := []struct {
+		name     string
+		schedule *investapi.TradingSchedule
+		at       time.Time
+		want     bool
+	}{
+		{name: "pre-market", schedule: schedule, at: moscow(8, 0), want: false},
+		{name: "opening", schedule: schedule, at: moscow(10, 0), want: true},
+		{name: "just before the close", schedule: schedule, at: moscow(18, 40).Add(-time.Second), want: true},
+		{name: "closing", schedule: schedule, at: moscow(18, 40), want: false},
+		{name: "between sessions", schedule: schedule, at: moscow(19, 0), want: false},
+		{name: "evening session", schedule: schedule, at: moscow(19, 5), want: true},
+		{name: "UTC time of the main session", schedule: schedule, at: moscow(12, 0).UTC(), want: true},
+		{name: "day missing from the schedule", schedule: schedule, at: moscow(12, 0).AddDate(0, 0, 1), want: false},
+		{
+			name:     "weekend",
+			schedule: &investapi.TradingSchedule{Days: []*investapi.TradingDay{tradingDay(moscow(0, 0), false)}},
+			at:       moscow(12, 0),
+			want:     false,
+		},
+		{name: "no schedule", at: moscow(12, 0), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsMarketOpen(tt.schedule, tt.at); got != tt.want {
+				t.Fatalf("IsMarketOpen(%s) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTradingDaysInRange(t *testing.T) {
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2024, month, day, 0, 0, 0, 0, internal.MoscowTZ)
+	}
+
+	tests := []struct {
+		name         string
+		from, to     time.Time
+		wantDays     int
+		wantFirst    time.Time
+		wantLast     time.Time
+		wantRequests int
+	}{
+		{
+			name:         "weekend skipped",
+			from:         date(3, 1),
+			to:           date(3, 4).Add(12 * time.Hour),
+			wantDays:     2,
+			wantFirst:    date(3, 1),
+			wantLast:     date(3, 4),
+			wantRequests: 1,
+		},
+		{
+			name:         "range split into 14 day windows",
+			from:         date(3, 1),
+			to:           date(3, 31),
+			wantDays:     21,
+			wantFirst:    date(3, 1),
+			wantLast:     date(3, 29),
+			wantRequests: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			instruments := &fakeInstrumentsClient{}
+			c.instrumentsClient = instruments
+
+			days, err := c.TradingDaysInRange(context.Background(), "MOEX", tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("TradingDaysInRange: %v", err)
+			}
+			if len(days) != tt.wantDays {
+				t.Fatalf("trading days = %d, want %d", len(days), tt.wantDays)
+			}
+			if !days[0].Equal(tt.wantFirst) || !days[len(days)-1].Equal(tt.wantLast) {
+				t.Fatalf("days = %s..%s, want %s..%s", days[0], days[len(days)-1], tt.wantFirst, tt.wantLast)
+			}
+			for i := 1; i < len(days); i++ {
+				if !days[i].After(days[i-1]) {
+					t.Fatalf("days not ascending and unique at %s", days[i])
+				}
+			}
+			if got := len(instruments.recorded()); got != tt.wantRequests {
+				t.Fatalf("schedule requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestTradingStatusAllows(t *testing.T) {
+	tests := []struct {
+		status     investapi.SecurityTradingStatus
+		wantLimit  bool
+		wantMarket bool
+	}{
+		{status: investapi.SecurityTradingStatus_SECURITY_TRADING_STATUS_NORMAL_TRADING, wantLimit: true, wantMarket: true},
+		{status: investapi.SecurityTradingStatus_SECURITY_TRADING_STATUS_DEALER_NORMAL_TRADING, wantLimit: true, wantMarket: true},
+		{status: investapi.SecurityTradingStatus_SECURITY_TRADING_STATUS_OPENING_AUCTION_PERIOD, wantLimit: true},
+		{status: investapi.SecurityTradingStatus_SECURITY_TRADING_STATUS_CLOSING_AUCTION, wantLimit: true},
+		{status: investapi.SecurityTradingStatus_SECURITY_TRADING_STATUS_DISCRETE_AUCTION, wantLimit: true},
+		{status: investapi.SecurityTradingStatus_SECURITY_TRADING_STATUS_BREAK_IN_TRADING},
+		{status: investapi.SecurityTradingStatus_SECURITY_TRADING_STATUS_DEALER_BREAK_IN_TRADING},
+		{status: investapi.SecurityTradingStatus_SECURITY_TRADING_STATUS_NOT_AVAILABLE_FOR_TRADING},
+		{status: investapi.SecurityTradingStatus_SECURITY_TRADING_STATUS_UNSPECIFIED},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status.String(), func(t *testing.T) {
+			if got := TradingStatusAllowsLimit(tt.status); got != tt.wantLimit {
+				t.Fatalf("TradingStatusAllowsLimit = %v, want %v", got, tt.wantLimit)
+			}
+			if got := TradingStatusAllowsMarket(tt.status); got != tt.wantMarket {
+				t.Fatalf("TradingStatusAllowsMarket = %v, want %v", got, tt.wantMarket)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package client
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/metadata"
+)
+
+// Rate limit headers sent by the API with every unary response
+const (
+	rateLimitLimitHeader     = "x-ratelimit-limit"
+	rateLimitRemainingHeader = "x-ratelimit-remaining"
+	rateLimitResetHeader     = "x-ratelimit-reset"
+)
+
+// RateLimit is the request quota reported with the last unary response
+type RateLimit struct {
+	// Method is the full gRPC method of the response carrying the quota
+	Method string
+	// Limit and Remaining are requests per window of the method's service
+	Limit     int
+	Remaining int
+	// Reset is the time left until the window restarts
+	Reset time.Duration
+	// UpdatedAt is when the response was received
+	UpdatedAt time.Time
+}
+
+// ClientStatus is a snapshot of the client health, see RealClient.Status
+type ClientStatus struct {
+	// State is the connectivity state of the main connection
+	State     connectivity.State
+	Connected bool
+
+	// RateLimit is zero until a response with rate limit headers arrives
+	RateLimit RateLimit
+
+	LastTrackingID string
+	// LastSuccess is the time of the last successful unary call
+	LastSuccess time.Time
+	// LastError is the last unary call failure and LastErrorAt its time
+	LastError   error
+	LastErrorAt time.Time
+}
+
+// callStats collects the per-call data reported by Status
+type callStats struct {
+	mu          sync.Mutex
+	rateLimit   RateLimit
+	lastSuccess time.Time
+	lastError   error
+	lastErrorAt time.Time
+}
+
+// record stores the outcome of a unary call and the quota from its headers
+func (s *callStats) record(method string, err error, header metadata.MD) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.lastError = err
+		s.lastErrorAt = now
+	} else {
+		s.lastSuccess = now
+	}
+
+	limit, okLimit := leadingInt(firstMetadataValue(header, rateLimitLimitHeader))
+	remaining, okRemaining := leadingInt(firstMetadataValue(header, rateLimitRemainingHeader))
+	if !okLimit || !okRemaining {
+		return
+	}
+	reset, _ := leadingInt(firstMetadataValue(header, rateLimitResetHeader))
+
+	s.rateLimit = RateLimit{
+		Method:    method,
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     time.Duration(reset) * time.Second,
+		UpdatedAt: now,
+	}
+}
+
+// leadingInt parses the number at the start of a header value such as
+// "200, 200;w=60"
+func leadingInt(value string) (int, bool) {
+	value = strings.TrimSpace(value)
+	end := strings.IndexFunc(value, func(r rune) bool { return r < '0' || r > '9' })
+	if end >= 0 {
+		value = value[:end]
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// Status returns the connection state, the last reported rate limit and the
+// outcome of the latest unary calls in a single snapshot
+func (c *RealClient) Status() ClientStatus {
+	c.mu.RLock()
+	status := ClientStatus{
+		State:     connectivity.Shutdown,
+		Connected: c.connected,
+	}
+	if c.conn != nil {
+		status.State = c.conn.GetState()
+	}
+	c.mu.RUnlock()
+
+	status.LastTrackingID = c.LastTrackingID()
+
+	c.callStats.mu.Lock()
+	status.RateLimit = c.callStats.rateLimit
+	status.LastSuccess = c.callStats.lastSuccess
+	status.LastError = c.callStats.lastError
+	status.LastErrorAt = c.callStats.lastErrorAt
+	c.callStats.mu.Unlock()
+
+	return status
+}
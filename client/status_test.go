@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestClientStatus(t *testing.T) {
+	tests := []struct {
+		name          string
+		header        metadata.MD
+		err           error
+		wantRateLimit RateLimit
+		wantSuccess   bool
+	}{
+		{
+			name:          "successful call with rate limit headers",
+			header:        metadata.Pairs(rateLimitLimitHeader, "200, 200;w=60", rateLimitRemainingHeader, "199", rateLimitResetHeader, "42"),
+			wantRateLimit: RateLimit{Method: investapi.UsersService_GetAccounts_FullMethodName, Limit: 200, Remaining: 199, Reset: 42 * time.Second},
+			wantSuccess:   true,
+		},
+		{name: "successful call without headers", wantSuccess: true},
+		{name: "failed call", err: status.Error(codes.NotFound, "not found")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &usersServer{}
+			users.accounts = func(ctx context.Context) (*investapi.GetAccountsResponse, error) {
+				if tt.header != nil {
+					grpc.SetHeader(ctx, tt.header)
+				}
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return &investapi.GetAccountsResponse{Accounts: []*investapi.Account{{Id: "acc"}}}, nil
+			}
+			c := newServerClient(t, nil, func(srv *grpc.Server) {
+				investapi.RegisterUsersServiceServer(srv, users)
+			})
+
+			_, callErr := c.GetAccounts(context.Background())
+			got := c.Status()
+
+			if !got.Connected {
+				t.Fatalf("Connected = false, want true")
+			}
+			if got.LastSuccess.IsZero() != !tt.wantSuccess {
+				t.Fatalf("LastSuccess = %v, want set: %v", got.LastSuccess, tt.wantSuccess)
+			}
+			if (got.LastError != nil) != (callErr != nil) || got.LastErrorAt.IsZero() != (callErr == nil) {
+				t.Fatalf("LastError = %v at %v, want error: %v", got.LastError, got.LastErrorAt, callErr != nil)
+			}
+
+			rateLimit := got.RateLimit
+			if tt.wantRateLimit.Method != "" && rateLimit.UpdatedAt.IsZero() {
+				t.Fatalf("RateLimit.UpdatedAt is zero")
+			}
+			rateLimit.UpdatedAt = tt.wantRateLimit.UpdatedAt
+			if rateLimit != tt.wantRateLimit {
+				t.Fatalf("RateLimit = %+v, want %+v", rateLimit, tt.wantRateLimit)
+			}
+		})
+	}
+}
+
+func TestClientStatusWithoutConnection(t *testing.T) {
+	c := newTestClient(nil)
+	c.connected = false
+
+	got := c.Status()
+	if got.Connected || got.State != connectivity.Shutdown {
+		t.Fatalf("Status = %+v, want disconnected and SHUTDOWN", got)
+	}
+}
@@ -0,0 +1,121 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// StopOrderBuilder assembles a PostStopOrderRequest step by step.
+// New builders create a good-till-cancel order executed at market price with
+// a fresh OrderId; call Buy or Sell, Lots and one of StopLoss, TakeProfit or
+// StopLimit before Build.
+type StopOrderBuilder struct {
+	req *investapi.PostStopOrderRequest
+	now func() time.Time
+}
+
+// NewStopOrderBuilder starts a stop order for the instrument (FIGI or UID) on the account
+func NewStopOrderBuilder(accountID, instrumentID string) *StopOrderBuilder {
+	return &StopOrderBuilder{
+		req: &investapi.PostStopOrderRequest{
+			AccountId:         accountID,
+			InstrumentId:      instrumentID,
+			ExpirationType:    investapi.StopOrderExpirationType_STOP_ORDER_EXPIRATION_TYPE_GOOD_TILL_CANCEL,
+			ExchangeOrderType: investapi.ExchangeOrderType_EXCHANGE_ORDER_TYPE_MARKET,
+			OrderId:           NewIdempotencyKey(),
+		},
+		now: time.Now,
+	}
+}
+
+// Buy sets the stop order direction to buy
+func (b *StopOrderBuilder) Buy() *StopOrderBuilder {
+	b.req.Direction = investapi.StopOrderDirection_STOP_ORDER_DIRECTION_BUY
+	return b
+}
+
+// Sell sets the stop order direction to sell
+func (b *StopOrderBuilder) Sell() *StopOrderBuilder {
+	b.req.Direction = investapi.StopOrderDirection_STOP_ORDER_DIRECTION_SELL
+	return b
+}
+
+// Lots sets the order quantity in lots
+func (b *StopOrderBuilder) Lots(lots int64) *StopOrderBuilder {
+	b.req.Quantity = lots
+	return b
+}
+
+// StopLoss triggers a market order when the price reaches stopPrice.
+// It replaces the type and prices set by an earlier StopLimit or TakeProfit.
+func (b *StopOrderBuilder) StopLoss(stopPrice float64) *StopOrderBuilder {
+	b.market(investapi.StopOrderType_STOP_ORDER_TYPE_STOP_LOSS, stopPrice)
+	return b
+}
+
+// TakeProfit triggers a market order when the price reaches stopPrice.
+// It replaces the type and prices set by an earlier StopLimit or StopLoss.
+func (b *StopOrderBuilder) TakeProfit(stopPrice float64) *StopOrderBuilder {
+	b.market(investapi.StopOrderType_STOP_ORDER_TYPE_TAKE_PROFIT, stopPrice)
+	return b
+}
+
+// market sets a stop order executed at market price, clearing a limit price
+func (b *StopOrderBuilder) market(stopOrderType investapi.StopOrderType, stopPrice float64) {
+	b.req.StopOrderType = stopOrderType
+	b.req.ExchangeOrderType = investapi.ExchangeOrderType_EXCHANGE_ORDER_TYPE_MARKET
+	b.req.StopPrice = NewQuotationRounded(stopPrice)
+	b.req.Price = nil
+}
+
+// StopLimit places a limit order at price when the price reaches stopPrice
+func (b *StopOrderBuilder) StopLimit(stopPrice, price float64) *StopOrderBuilder {
+	b.req.StopOrderType = investapi.StopOrderType_STOP_ORDER_TYPE_STOP_LIMIT
+	b.req.ExchangeOrderType = investapi.ExchangeOrderType_EXCHANGE_ORDER_TYPE_LIMIT
+	b.req.StopPrice = NewQuotationRounded(stopPrice)
+	b.req.Price = NewQuotationRounded(price)
+	return b
+}
+
+// ExpireAt makes the order good till t. Build fails unless t is in the future
+// at the time of Build; a later ExpireAt call replaces an earlier one.
+func (b *StopOrderBuilder) ExpireAt(t time.Time) *StopOrderBuilder {
+	b.req.ExpirationType = investapi.StopOrderExpirationType_STOP_ORDER_EXPIRATION_TYPE_GOOD_TILL_DATE
+	b.req.ExpireDate = timestamppb.New(t)
+	return b
+}
+
+// OrderID overrides the generated idempotency key
+func (b *StopOrderBuilder) OrderID(orderID string) *StopOrderBuilder {
+	b.req.OrderId = orderID
+	return b
+}
+
+// Build validates and returns the request. The builder must not be reused
+// after Build.
+func (b *StopOrderBuilder) Build() (*investapi.PostStopOrderRequest, error) {
+	if b.req.AccountId == "" {
+		return nil, fmt.Errorf("account ID is required")
+	}
+	if b.req.InstrumentId == "" {
+		return nil, fmt.Errorf("instrument ID is required")
+	}
+	if b.req.Direction == investapi.StopOrderDirection_STOP_ORDER_DIRECTION_UNSPECIFIED {
+		return nil, fmt.Errorf("stop order direction is required")
+	}
+	if b.req.Quantity <= 0 {
+		return nil, fmt.Errorf("order quantity must be a positive number of lots, got %d", b.req.Quantity)
+	}
+	if b.req.StopOrderType == investapi.StopOrderType_STOP_ORDER_TYPE_UNSPECIFIED || b.req.StopPrice == nil {
+		return nil, fmt.Errorf("stop order type and stop price are required")
+	}
+	if expireAt := b.req.ExpireDate; expireAt != nil && !expireAt.AsTime().After(b.now()) {
+		return nil, fmt.Errorf("stop order expiration %s is not in the future", expireAt.AsTime().Format(time.RFC3339))
+	}
+
+	return b.req, nil
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestStopOrderBuilderSetters(t *testing.T) {
+	tests := []struct {
+		name          string
+		build         func(b *StopOrderBuilder) *StopOrderBuilder
+		wantType      investapi.StopOrderType
+		wantExchange  investapi.ExchangeOrderType
+		wantStopPrice float64
+		wantPrice     float64
+	}{
+		{
+			name:          "stop limit",
+			build:         func(b *StopOrderBuilder) *StopOrderBuilder { return b.StopLimit(95, 94.5) },
+			wantType:      investapi.StopOrderType_STOP_ORDER_TYPE_STOP_LIMIT,
+			wantExchange:  investapi.ExchangeOrderType_EXCHANGE_ORDER_TYPE_LIMIT,
+			wantStopPrice: 95,
+			wantPrice:     94.5,
+		},
+		{
+			name:          "stop loss after stop limit",
+			build:         func(b *StopOrderBuilder) *StopOrderBuilder { return b.StopLimit(95, 94.5).StopLoss(90) },
+			wantType:      investapi.StopOrderType_STOP_ORDER_TYPE_STOP_LOSS,
+			wantExchange:  investapi.ExchangeOrderType_EXCHANGE_ORDER_TYPE_MARKET,
+			wantStopPrice: 90,
+		},
+		{
+			name:          "take profit after stop limit",
+			build:         func(b *StopOrderBuilder) *StopOrderBuilder { return b.StopLimit(95, 94.5).TakeProfit(110) },
+			wantType:      investapi.StopOrderType_STOP_ORDER_TYPE_TAKE_PROFIT,
+			wantExchange:  investapi.ExchangeOrderType_EXCHANGE_ORDER_TYPE_MARKET,
+			wantStopPrice: 110,
+		},
+		{
+			name:          "stop limit after stop loss",
+			build:         func(b *StopOrderBuilder) *StopOrderBuilder { return b.StopLoss(90).StopLimit(95, 94.5) },
+			wantType:      investapi.StopOrderType_STOP_ORDER_TYPE_STOP_LIMIT,
+			wantExchange:  investapi.ExchangeOrderType_EXCHANGE_ORDER_TYPE_LIMIT,
+			wantStopPrice: 95,
+			wantPrice:     94.5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewStopOrderBuilder("acc", "BBG1").Sell().Lots(1)
+			req, err := tt.build(b).Build()
+			if err != nil {
+				t.Fatalf("Build: %v", err)
+			}
+
+			if req.StopOrderType != tt.wantType {
+				t.Errorf("StopOrderType = %s, want %s", req.StopOrderType, tt.wantType)
+			}
+			if req.ExchangeOrderType != tt.wantExchange {
+				t.Errorf("ExchangeOrderType = %s, want %s", req.ExchangeOrderType, tt.wantExchange)
+			}
+			if got := quotationToFloat(req.StopPrice); got != tt.wantStopPrice {
+				t.Errorf("StopPrice = %v, want %v", got, tt.wantStopPrice)
+			}
+			if tt.wantPrice == 0 && req.Price != nil {
+				t.Errorf("Price = %v, want nil", req.Price)
+			}
+			if tt.wantPrice != 0 && quotationToFloat(req.Price) != tt.wantPrice {
+				t.Errorf("Price = %v, want %v", quotationToFloat(req.Price), tt.wantPrice)
+			}
+		})
+	}
+}
+
+func TestStopOrderBuilderExpireAt(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		expire  []time.Time
+		wantErr bool
+	}{
+		{name: "future", expire: []time.Time{future}},
+		{name: "past", expire: []time.Time{past}, wantErr: true},
+		{name: "now", expire: []time.Time{now}, wantErr: true},
+		{name: "past corrected to future", expire: []time.Time{past, future}},
+		{name: "future replaced by past", expire: []time.Time{future, past}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewStopOrderBuilder("acc", "BBG1").Sell().Lots(1).StopLoss(90)
+			b.now = func() time.Time { return now }
+			for _, expire := range tt.expire {
+				b.ExpireAt(expire)
+			}
+
+			req, err := b.Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build error = %v, want error: %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got, want := req.ExpireDate.AsTime(), tt.expire[len(tt.expire)-1]; !got.Equal(want) {
+				t.Fatalf("ExpireDate = %s, want %s", got, want)
+			}
+			if req.ExpirationType != investapi.StopOrderExpirationType_STOP_ORDER_EXPIRATION_TYPE_GOOD_TILL_DATE {
+				t.Fatalf("ExpirationType = %s, want good till date", req.ExpirationType)
+			}
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// IsStreamClosed reports whether a stream ended normally: the server closed
+// it (io.EOF) or it was cancelled on the client side
+func IsStreamClosed(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) {
+		return true
+	}
+	return status.Code(err) == codes.Canceled
+}
+
+// IsStreamRecoverable reports whether a stream failed with a transient error,
+// so opening a new stream and resubscribing is expected to work
+func IsStreamRecoverable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Aborted, codes.Internal, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
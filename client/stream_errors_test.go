@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStreamErrorClassification(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		wantClosed      bool
+		wantRecoverable bool
+	}{
+		{name: "EOF", err: io.EOF, wantClosed: true},
+		{name: "wrapped EOF", err: fmt.Errorf("stream ended: %w", io.EOF), wantClosed: true},
+		{name: "context canceled", err: context.Canceled, wantClosed: true},
+		{name: "status canceled", err: status.Error(codes.Canceled, "context canceled"), wantClosed: true},
+		{name: "unavailable", err: status.Error(codes.Unavailable, "connection reset"), wantRecoverable: true},
+		{name: "resource exhausted", err: status.Error(codes.ResourceExhausted, "stream limit"), wantRecoverable: true},
+		{name: "unauthenticated", err: status.Error(codes.Unauthenticated, "bad token")},
+		{name: "nil", err: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsStreamClosed(tt.err); got != tt.wantClosed {
+				t.Fatalf("IsStreamClosed = %v, want %v", got, tt.wantClosed)
+			}
+			if got := IsStreamRecoverable(tt.err); got != tt.wantRecoverable {
+				t.Fatalf("IsStreamRecoverable = %v, want %v", got, tt.wantRecoverable)
+			}
+		})
+	}
+}
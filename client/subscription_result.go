@@ -0,0 +1,114 @@
+package client
+
+import (
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// InstrumentSubscriptionStatus is the subscription outcome for one instrument
+type InstrumentSubscriptionStatus struct {
+	Figi          string
+	InstrumentUID string
+	Status        investapi.SubscriptionStatus
+}
+
+// OK reports whether the instrument was subscribed successfully
+func (s InstrumentSubscriptionStatus) OK() bool {
+	return s.Status == investapi.SubscriptionStatus_SUBSCRIPTION_STATUS_SUCCESS
+}
+
+// SubscriptionResult is a parsed subscribe or unsubscribe confirmation
+type SubscriptionResult struct {
+	Type       SubscriptionType
+	TrackingID string
+	Statuses   []InstrumentSubscriptionStatus
+}
+
+// Failed returns the instruments whose subscription did not succeed
+func (r *SubscriptionResult) Failed() []InstrumentSubscriptionStatus {
+	var failed []InstrumentSubscriptionStatus
+	for _, status := range r.Statuses {
+		if !status.OK() {
+			failed = append(failed, status)
+		}
+	}
+	return failed
+}
+
+// Err returns a *SubscriptionError when any instrument failed, nil otherwise
+func (r *SubscriptionResult) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	return &SubscriptionError{
+		Type:       r.Type,
+		TrackingID: r.TrackingID,
+		Failed:     failed,
+	}
+}
+
+// ParseSubscriptionResult extracts per-instrument statuses from a subscription
+// confirmation received on a market data stream. It returns false for any other
+// message. Confirmations arrive on the stream like market data, so check every
+// received message to learn about instruments the server silently dropped.
+func ParseSubscriptionResult(resp *investapi.MarketDataResponse) (*SubscriptionResult, bool) {
+	switch payload := resp.GetPayload().(type) {
+	case *investapi.MarketDataResponse_SubscribeCandlesResponse:
+		result := &SubscriptionResult{
+			Type:       SubscriptionCandles,
+			TrackingID: payload.SubscribeCandlesResponse.TrackingId,
+		}
+		for _, sub := range payload.SubscribeCandlesResponse.CandlesSubscriptions {
+			result.Statuses = append(result.Statuses, InstrumentSubscriptionStatus{
+				Figi:          sub.Figi,
+				InstrumentUID: sub.InstrumentUid,
+				Status:        sub.SubscriptionStatus,
+			})
+		}
+		return result, true
+
+	case *investapi.MarketDataResponse_SubscribeOrderBookResponse:
+		result := &SubscriptionResult{
+			Type:       SubscriptionOrderBook,
+			TrackingID: payload.SubscribeOrderBookResponse.TrackingId,
+		}
+		for _, sub := range payload.SubscribeOrderBookResponse.OrderBookSubscriptions {
+			result.Statuses = append(result.Statuses, InstrumentSubscriptionStatus{
+				Figi:          sub.Figi,
+				InstrumentUID: sub.InstrumentUid,
+				Status:        sub.SubscriptionStatus,
+			})
+		}
+		return result, true
+
+	case *investapi.MarketDataResponse_SubscribeTradesResponse:
+		result := &SubscriptionResult{
+			Type:       SubscriptionTrades,
+			TrackingID: payload.SubscribeTradesResponse.TrackingId,
+		}
+		for _, sub := range payload.SubscribeTradesResponse.TradeSubscriptions {
+			result.Statuses = append(result.Statuses, InstrumentSubscriptionStatus{
+				Figi:          sub.Figi,
+				InstrumentUID: sub.InstrumentUid,
+				Status:        sub.SubscriptionStatus,
+			})
+		}
+		return result, true
+
+	case *investapi.MarketDataResponse_SubscribeLastPriceResponse:
+		result := &SubscriptionResult{
+			Type:       SubscriptionLastPrices,
+			TrackingID: payload.SubscribeLastPriceResponse.TrackingId,
+		}
+		for _, sub := range payload.SubscribeLastPriceResponse.LastPriceSubscriptions {
+			result.Statuses = append(result.Statuses, InstrumentSubscriptionStatus{
+				Figi:          sub.Figi,
+				InstrumentUID: sub.InstrumentUid,
+				Status:        sub.SubscriptionStatus,
+			})
+		}
+		return result, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestParseSubscriptionResult(t *testing.T) {
+	const (
+		success  = investapi.SubscriptionStatus_SUBSCRIPTION_STATUS_SUCCESS
+		notFound = investapi.SubscriptionStatus_SUBSCRIPTION_STATUS_INSTRUMENT_NOT_FOUND
+		limit    = investapi.SubscriptionStatus_SUBSCRIPTION_STATUS_LIMIT_IS_EXCEEDED
+	)
+
+	tests := []struct {
+		name       string
+		resp       *investapi.MarketDataResponse
+		wantOK     bool
+		wantType   SubscriptionType
+		wantFailed []string
+	}{
+		{
+			name: "all candles subscribed",
+			resp: &investapi.MarketDataResponse{Payload: &investapi.MarketDataResponse_SubscribeCandlesResponse{
+				SubscribeCandlesResponse: &investapi.SubscribeCandlesResponse{
+					TrackingId:           "track-1",
+					CandlesSubscriptions: []*investapi.CandleSubscription{{Figi: "BBG1", SubscriptionStatus: success}},
+				},
+			}},
+			wantOK:   true,
+			wantType: SubscriptionCandles,
+		},
+		{
+			name: "order book instrument not found",
+			resp: &investapi.MarketDataResponse{Payload: &investapi.MarketDataResponse_SubscribeOrderBookResponse{
+				SubscribeOrderBookResponse: &investapi.SubscribeOrderBookResponse{
+					TrackingId: "track-2",
+					OrderBookSubscriptions: []*investapi.OrderBookSubscription{
+						{Figi: "BBG1", SubscriptionStatus: success},
+						{Figi: "MISSING", SubscriptionStatus: notFound},
+					},
+				},
+			}},
+			wantOK:     true,
+			wantType:   SubscriptionOrderBook,
+			wantFailed: []string{"MISSING"},
+		},
+		{
+			name: "trades failure reported by UID",
+			resp: &investapi.MarketDataResponse{Payload: &investapi.MarketDataResponse_SubscribeTradesResponse{
+				SubscribeTradesResponse: &investapi.SubscribeTradesResponse{
+					TrackingId:         "track-3",
+					TradeSubscriptions: []*investapi.TradeSubscription{{InstrumentUid: "uid-1", SubscriptionStatus: limit}},
+				},
+			}},
+			wantOK:     true,
+			wantType:   SubscriptionTrades,
+			wantFailed: []string{"uid-1"},
+		},
+		{
+			name: "last prices",
+			resp: &investapi.MarketDataResponse{Payload: &investapi.MarketDataResponse_SubscribeLastPriceResponse{
+				SubscribeLastPriceResponse: &investapi.SubscribeLastPriceResponse{
+					LastPriceSubscriptions: []*investapi.LastPriceSubscription{{Figi: "BBG1", SubscriptionStatus: success}},
+				},
+			}},
+			wantOK:   true,
+			wantType: SubscriptionLastPrices,
+		},
+		{
+			name: "market data is not a confirmation",
+			resp: &investapi.MarketDataResponse{Payload: &investapi.MarketDataResponse_LastPrice{
+				LastPrice: &investapi.LastPrice{Figi: "BBG1"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := ParseSubscriptionResult(tt.resp)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if result.Type != tt.wantType {
+				t.Fatalf("type = %v, want %v", result.Type, tt.wantType)
+			}
+
+			err := result.Err()
+			if len(tt.wantFailed) == 0 {
+				if err != nil {
+					t.Fatalf("Err = %v, want nil", err)
+				}
+				return
+			}
+
+			var subErr *SubscriptionError
+			if !errors.As(err, &subErr) {
+				t.Fatalf("Err = %v, want *SubscriptionError", err)
+			}
+			if len(subErr.Failed) != len(tt.wantFailed) || subErr.TrackingID != result.TrackingID {
+				t.Fatalf("SubscriptionError = %+v, want %v failed", subErr, tt.wantFailed)
+			}
+			for _, id := range tt.wantFailed {
+				if !strings.Contains(err.Error(), id) {
+					t.Fatalf("error %q does not mention %s", err, id)
+				}
+			}
+		})
+	}
+}
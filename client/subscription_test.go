@@ -0,0 +1,114 @@
+package client
+
+import (
+	"testing"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestMarketDataSubscriptionToRequest(t *testing.T) {
+	subscribe := investapi.SubscriptionAction_SUBSCRIPTION_ACTION_SUBSCRIBE
+	instruments := []string{"BBG1", "BBG2"}
+
+	tests := []struct {
+		name   string
+		spec   MarketDataSubscription
+		verify func(t *testing.T, req *investapi.MarketDataRequest)
+	}{
+		{
+			name: "candles",
+			spec: MarketDataSubscription{Type: SubscriptionCandles, InstrumentIDs: instruments, Interval: oneMinute},
+			verify: func(t *testing.T, req *investapi.MarketDataRequest) {
+				candles := req.GetSubscribeCandlesRequest()
+				if candles == nil || candles.SubscriptionAction != subscribe || len(candles.Instruments) != 2 {
+					t.Fatalf("request = %v, want a candles subscription for two instruments", req)
+				}
+				for _, instrument := range candles.Instruments {
+					if instrument.Interval != oneMinute {
+						t.Fatalf("interval = %s, want one minute", instrument.Interval)
+					}
+				}
+			},
+		},
+		{
+			name: "order book",
+			spec: MarketDataSubscription{Type: SubscriptionOrderBook, InstrumentIDs: instruments, Depth: 20},
+			verify: func(t *testing.T, req *investapi.MarketDataRequest) {
+				books := req.GetSubscribeOrderBookRequest()
+				if books == nil || books.SubscriptionAction != subscribe || len(books.Instruments) != 2 {
+					t.Fatalf("request = %v, want an order book subscription for two instruments", req)
+				}
+				if books.Instruments[0].Depth != 20 {
+					t.Fatalf("depth = %d, want 20", books.Instruments[0].Depth)
+				}
+			},
+		},
+		{
+			name: "trades",
+			spec: MarketDataSubscription{Type: SubscriptionTrades, InstrumentIDs: instruments},
+			verify: func(t *testing.T, req *investapi.MarketDataRequest) {
+				trades := req.GetSubscribeTradesRequest()
+				if trades == nil || trades.SubscriptionAction != subscribe || len(trades.Instruments) != 2 {
+					t.Fatalf("request = %v, want a trades subscription for two instruments", req)
+				}
+			},
+		},
+		{
+			name: "last prices",
+			spec: MarketDataSubscription{Type: SubscriptionLastPrices, InstrumentIDs: instruments},
+			verify: func(t *testing.T, req *investapi.MarketDataRequest) {
+				prices := req.GetSubscribeLastPriceRequest()
+				if prices == nil || prices.SubscriptionAction != subscribe || len(prices.Instruments) != 2 {
+					t.Fatalf("request = %v, want a last price subscription for two instruments", req)
+				}
+				if prices.Instruments[1].InstrumentId != "BBG2" {
+					t.Fatalf("second instrument = %q, want BBG2", prices.Instruments[1].InstrumentId)
+				}
+			},
+		},
+		{
+			name: "no instruments",
+			spec: MarketDataSubscription{Type: SubscriptionTrades},
+			verify: func(t *testing.T, req *investapi.MarketDataRequest) {
+				if req != nil {
+					t.Fatalf("request = %v, want nil", req)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.verify(t, tt.spec.ToRequest())
+		})
+	}
+}
+
+func TestSubscribeCandlesWaitingClose(t *testing.T) {
+	tests := []struct {
+		name         string
+		waitingClose bool
+	}{
+		{name: "on close", waitingClose: CandlesOnClose},
+		{name: "on every trade", waitingClose: CandlesOnEveryTrade},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			stream := newFakeStream[investapi.MarketDataRequest, investapi.MarketDataResponse](nil)
+
+			if err := c.SubscribeCandles(stream, []string{"BBG1"}, oneMinute, tt.waitingClose); err != nil {
+				t.Fatalf("SubscribeCandles: %v", err)
+			}
+
+			requests := stream.requests()
+			if len(requests) != 1 {
+				t.Fatalf("requests = %d, want 1", len(requests))
+			}
+			if got := requests[0].GetSubscribeCandlesRequest().GetWaitingClose(); got != tt.waitingClose {
+				t.Fatalf("WaitingClose = %v, want %v", got, tt.waitingClose)
+			}
+		})
+	}
+}
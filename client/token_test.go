@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestUpdateToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		token    string
+		wantErr  bool
+		wantAuth string
+	}{
+		{name: "new token", token: "rotated", wantAuth: "Bearer rotated"},
+		{name: "empty token keeps the old one", wantErr: true, wantAuth: "Bearer test"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &usersServer{}
+			c := newServerClient(t, nil, func(srv *grpc.Server) {
+				investapi.RegisterUsersServiceServer(srv, users)
+			})
+
+			if _, err := c.GetAccounts(context.Background()); err != nil {
+				t.Fatalf("GetAccounts: %v", err)
+			}
+			if got := firstMetadataValue(users.lastMetadata(), "authorization"); got != "Bearer test" {
+				t.Fatalf("authorization before update = %q, want %q", got, "Bearer test")
+			}
+
+			if err := c.UpdateToken(tt.token); (err != nil) != tt.wantErr {
+				t.Fatalf("UpdateToken err = %v, want error: %v", err, tt.wantErr)
+			}
+			if _, err := c.GetAccounts(context.Background()); err != nil {
+				t.Fatalf("GetAccounts: %v", err)
+			}
+			if got := firstMetadataValue(users.lastMetadata(), "authorization"); got != tt.wantAuth {
+				t.Fatalf("authorization after update = %q, want %q", got, tt.wantAuth)
+			}
+		})
+	}
+}
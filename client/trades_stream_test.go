@@ -0,0 +1,59 @@
+package client
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestStreamTradesFunc(t *testing.T) {
+	tests := []struct {
+		name    string
+		end     error
+		wantErr bool
+	}{
+		{name: "stream closed", end: io.EOF},
+		{name: "stream failed", end: status.Error(codes.Internal, "boom"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(nil)
+			stream := newFakeStream[investapi.TradesStreamRequest, investapi.TradesStreamResponse](nil)
+			orders := &fakeOrdersStreamClient{trades: stream}
+			c.ordersStreamClient = orders
+
+			stream.recv <- &investapi.TradesStreamResponse{
+				Payload: &investapi.TradesStreamResponse_Ping{Ping: &investapi.Ping{}},
+			}
+			stream.recv <- &investapi.TradesStreamResponse{
+				Payload: &investapi.TradesStreamResponse_OrderTrades{OrderTrades: &investapi.OrderTrades{
+					OrderId: "order",
+					Trades:  []*investapi.OrderTrade{{Quantity: 1, TradeId: "trade"}},
+				}},
+			}
+			stream.fail(tt.end)
+
+			handled := make(chan *investapi.OrderTrades, 2)
+			err := c.StreamTradesFunc(context.Background(), []string{"acc"}, func(trades *investapi.OrderTrades) {
+				handled <- trades
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("StreamTradesFunc error = %v, want error: %v", err, tt.wantErr)
+			}
+
+			got := <-handled
+			if got.OrderId != "order" || len(got.Trades) != 1 {
+				t.Fatalf("handled %v, want the trade of order", got)
+			}
+			if accounts := orders.tradesRequest.GetAccounts(); len(accounts) != 1 || accounts[0] != "acc" {
+				t.Fatalf("requested accounts = %v, want [acc]", accounts)
+			}
+		})
+	}
+}
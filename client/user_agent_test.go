@@ -0,0 +1,41 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+
+	"github.com/buurzx/tinkoff-go/config"
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestUserAgent(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		wantAgent string
+	}{
+		{name: "configured", userAgent: "my-bot/1.2", wantAgent: "my-bot/1.2 "},
+		{name: "default", wantAgent: "grpc-go/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &usersServer{}
+			c := newServerClient(t, &config.Config{UserAgent: tt.userAgent}, func(srv *grpc.Server) {
+				investapi.RegisterUsersServiceServer(srv, users)
+			})
+
+			if _, err := c.RefreshAccounts(context.Background()); err != nil {
+				t.Fatalf("RefreshAccounts: %v", err)
+			}
+
+			agent := firstMetadataValue(users.lastMetadata(), "user-agent")
+			if !strings.HasPrefix(agent, tt.wantAgent) {
+				t.Fatalf("user-agent = %q, want prefix %q", agent, tt.wantAgent)
+			}
+		})
+	}
+}
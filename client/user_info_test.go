@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestCanTradeInstrumentType(t *testing.T) {
+	tests := []struct {
+		name     string
+		category string
+		want     bool
+	}{
+		{name: "qualified", category: "derivative", want: true},
+		{name: "qualified in another case", category: "Foreign_Shares", want: true},
+		{name: "unqualified", category: "structured_income_bonds", want: false},
+	}
+
+	c := newTestClient(nil)
+	users := &fakeUsersClient{info: &investapi.GetInfoResponse{
+		QualifiedForWorkWith: []string{"derivative", "foreign_shares"},
+	}}
+	c.usersClient = users
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.CanTradeInstrumentType(context.Background(), tt.category)
+			if err != nil {
+				t.Fatalf("CanTradeInstrumentType: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("CanTradeInstrumentType(%q) = %v, want %v", tt.category, got, tt.want)
+			}
+		})
+	}
+
+	if users.infoCalls != 1 {
+		t.Fatalf("GetInfo calls = %d, want 1 thanks to the cache", users.infoCalls)
+	}
+}
+
+func TestUnaryLimitsByMethod(t *testing.T) {
+	tariff := &investapi.GetUserTariffResponse{UnaryLimits: []*investapi.UnaryLimit{
+		{
+			LimitPerMinute: 200,
+			Methods:        []string{"tinkoff.public.invest.api.contract.v1.UsersService/GetAccounts", "tinkoff.public.invest.api.contract.v1.UsersService/GetInfo"},
+		},
+		{
+			LimitPerMinute: 300,
+			Methods:        []string{investapi.OrdersService_PostOrder_FullMethodName},
+		},
+	}}
+
+	c := newTestClient(nil)
+	c.usersClient = &fakeUsersClient{tariff: tariff}
+	got, err := c.GetUserTariff(context.Background())
+	if err != nil {
+		t.Fatalf("GetUserTariff: %v", err)
+	}
+	limits := UnaryLimitsByMethod(got)
+
+	tests := []struct {
+		method string
+		want   int32
+	}{
+		{method: investapi.UsersService_GetAccounts_FullMethodName, want: 200},
+		{method: investapi.UsersService_GetInfo_FullMethodName, want: 200},
+		{method: investapi.OrdersService_PostOrder_FullMethodName, want: 300},
+		{method: investapi.OrdersService_CancelOrder_FullMethodName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			if got := limits[tt.method]; got != tt.want {
+				t.Fatalf("limit = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	if len(limits) != 3 {
+		t.Fatalf("limits = %v, want 3 methods", limits)
+	}
+	if empty := UnaryLimitsByMethod(nil); len(empty) != 0 {
+		t.Fatalf("limits of a nil tariff = %v, want none", empty)
+	}
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"fmt"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// ValidateOrderAgainstInstrument checks an order against instrument trading rules:
+// the quantity must be a positive number of lots, point pricing is only used
+// for futures and bonds and a limit price must be a multiple of the
+// instrument's minimum price increment.
+func ValidateOrderAgainstInstrument(req *investapi.PostOrderRequest, instrument *investapi.Instrument) error {
+	if req == nil {
+		return fmt.Errorf("order request is required")
+	}
+	if instrument == nil {
+		return fmt.Errorf("instrument is required")
+	}
+
+	if req.Quantity <= 0 {
+		return fmt.Errorf("order quantity must be a positive number of lots, got %d", req.Quantity)
+	}
+
+	if req.PriceType == investapi.PriceType_PRICE_TYPE_POINT && !supportsPointPrice(instrument) {
+		return fmt.Errorf("price in points is not supported for %s %s", instrument.InstrumentType, instrument.Ticker)
+	}
+
+	if req.OrderType != investapi.OrderType_ORDER_TYPE_LIMIT {
+		return nil
+	}
+
+	if req.Price == nil {
+		return fmt.Errorf("limit order requires a price")
+	}
+	if err := ValidateQuotation(req.Price); err != nil {
+		return fmt.Errorf("invalid order price: %w", err)
+	}
+
+	step := quotationToNanos(instrument.MinPriceIncrement)
+	if step <= 0 {
+		return nil
+	}
+
+	if quotationToNanos(req.Price)%step != 0 {
+		return fmt.Errorf("price %g is not a multiple of min price increment %g for %s",
+			quotationToFloat(req.Price), quotationToFloat(instrument.MinPriceIncrement), instrument.Ticker)
+	}
+
+	return nil
+}
+
+// supportsPointPrice reports whether orders for the instrument may be priced
+// in points, which the API allows only for futures and bonds
+func supportsPointPrice(instrument *investapi.Instrument) bool {
+	switch instrument.InstrumentKind {
+	case investapi.InstrumentType_INSTRUMENT_TYPE_FUTURES, investapi.InstrumentType_INSTRUMENT_TYPE_BOND:
+		return true
+	}
+	return instrument.InstrumentType == "futures" || instrument.InstrumentType == "bond"
+}
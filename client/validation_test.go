@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func limitOrder(lots int64, price *investapi.Quotation) *investapi.PostOrderRequest {
+	return &investapi.PostOrderRequest{
+		Quantity:  lots,
+		OrderType: investapi.OrderType_ORDER_TYPE_LIMIT,
+		Price:     price,
+	}
+}
+
+func TestValidateOrderAgainstInstrument(t *testing.T) {
+	share := &investapi.Instrument{
+		Ticker:            "SBER",
+		InstrumentType:    "share",
+		Lot:               10,
+		MinPriceIncrement: &investapi.Quotation{Nano: 10000000}, // 0.01
+	}
+
+	tests := []struct {
+		name    string
+		req     *investapi.PostOrderRequest
+		wantErr bool
+	}{
+		{name: "aligned price", req: limitOrder(1, &investapi.Quotation{Units: 250, Nano: 120000000})},
+		{name: "misaligned price", req: limitOrder(1, &investapi.Quotation{Units: 250, Nano: 125000000}), wantErr: true},
+		{name: "zero lots", req: limitOrder(0, &investapi.Quotation{Units: 250}), wantErr: true},
+		{name: "limit without price", req: limitOrder(1, nil), wantErr: true},
+		{
+			name: "market order ignores the step",
+			req:  &investapi.PostOrderRequest{Quantity: 1, OrderType: investapi.OrderType_ORDER_TYPE_MARKET},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateOrderAgainstInstrument(tt.req, share)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateOrderAgainstInstrument error = %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPostOrderWithInstrumentRejectsInvalidOrders(t *testing.T) {
+	c := newTestClient(nil)
+	orders := &fakeOrdersClient{}
+	c.ordersClient = orders
+
+	instrument := &investapi.Instrument{Ticker: "SBER", MinPriceIncrement: &investapi.Quotation{Nano: 10000000}}
+	_, err := c.PostOrderWithInstrument(context.Background(), limitOrder(1, &investapi.Quotation{Units: 1, Nano: 5000000}), instrument)
+	if err == nil {
+		t.Fatal("expected a misaligned price to be rejected")
+	}
+	if got := len(orders.posted()); got != 0 {
+		t.Fatalf("orders sent = %d, want 0", got)
+	}
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/buurzx/tinkoff-go/config"
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func account(id string, accountType investapi.AccountType) *investapi.Account {
+	return &investapi.Account{Id: id, Type: accountType}
+}
+
+func TestVerifyEnvironment(t *testing.T) {
+	const (
+		unspecified = investapi.AccountType_ACCOUNT_TYPE_UNSPECIFIED
+		brokerage   = investapi.AccountType_ACCOUNT_TYPE_TINKOFF
+		iis         = investapi.AccountType_ACCOUNT_TYPE_TINKOFF_IIS
+	)
+
+	tests := []struct {
+		name         string
+		isDemo       bool
+		users        *fakeUsersClient
+		sandbox      []*investapi.Account
+		wantMismatch bool
+	}{
+		{
+			name:  "production accounts",
+			users: &fakeUsersClient{accounts: []*investapi.Account{account("1", brokerage), account("2", iis)}},
+		},
+		{
+			name:         "sandbox accounts in production",
+			users:        &fakeUsersClient{accounts: []*investapi.Account{account("1", unspecified)}},
+			wantMismatch: true,
+		},
+		{
+			name:         "token rejected",
+			users:        &fakeUsersClient{err: status.Error(codes.Unauthenticated, "bad token")},
+			wantMismatch: true,
+		},
+		{
+			name:    "sandbox accounts in demo",
+			isDemo:  true,
+			users:   &fakeUsersClient{},
+			sandbox: []*investapi.Account{account("sb", brokerage)},
+		},
+		{
+			name:         "production accounts in demo",
+			isDemo:       true,
+			users:        &fakeUsersClient{},
+			sandbox:      []*investapi.Account{account("sb", brokerage), account("iis", iis)},
+			wantMismatch: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(&config.Config{IsDemo: tt.isDemo})
+			c.usersClient = tt.users
+			c.sandboxClient = &fakeSandboxClient{accounts: tt.sandbox}
+
+			err := c.VerifyEnvironment(context.Background())
+
+			var mismatch *EnvironmentMismatchError
+			if got := errors.As(err, &mismatch); got != tt.wantMismatch {
+				t.Fatalf("err = %v, want mismatch: %v", err, tt.wantMismatch)
+			}
+			if !tt.wantMismatch && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.accounts != nil {
+				t.Fatalf("VerifyEnvironment filled the accounts cache: %v", c.accounts)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"math/big"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// VWAP returns the volume-weighted average of the candles' typical price
+// (High+Low+Close)/3. It returns nil when the total volume is zero.
+// The sum is accumulated in nano units with arbitrary precision, so only the
+// final division is rounded.
+func VWAP(candles []*investapi.HistoricCandle) *investapi.Quotation {
+	sum := new(big.Int)
+	volume := new(big.Int)
+
+	for _, candle := range candles {
+		if candle == nil || candle.Volume == 0 {
+			continue
+		}
+		typical := quotationToNanos(candle.High) + quotationToNanos(candle.Low) + quotationToNanos(candle.Close)
+		sum.Add(sum, new(big.Int).Mul(big.NewInt(typical), big.NewInt(candle.Volume)))
+		volume.Add(volume, big.NewInt(candle.Volume))
+	}
+
+	if volume.Sign() == 0 {
+		return nil
+	}
+
+	// Divide by 3 together with the volume to keep the typical price exact
+	return nanosToQuotation(roundedQuo(sum, volume.Mul(volume, big.NewInt(3))).Int64())
+}
+
+// VWAPFromTrades returns the average trade price weighted by trade quantity.
+// It returns nil when the total quantity is zero.
+func VWAPFromTrades(trades []*investapi.Trade) *investapi.Quotation {
+	sum := new(big.Int)
+	quantity := new(big.Int)
+
+	for _, trade := range trades {
+		if trade == nil || trade.Quantity == 0 {
+			continue
+		}
+		sum.Add(sum, new(big.Int).Mul(big.NewInt(quotationToNanos(trade.Price)), big.NewInt(trade.Quantity)))
+		quantity.Add(quantity, big.NewInt(trade.Quantity))
+	}
+
+	if quantity.Sign() == 0 {
+		return nil
+	}
+
+	return nanosToQuotation(roundedQuo(sum, quantity).Int64())
+}
+
+// roundedQuo divides num by a positive den, rounding half away from zero
+func roundedQuo(num, den *big.Int) *big.Int {
+	half := new(big.Int).Rsh(den, 1)
+	if num.Sign() < 0 {
+		half.Neg(half)
+	}
+	return new(big.Int).Quo(new(big.Int).Add(num, half), den)
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"testing"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func hlc(high, low, close float64, volume int64) *investapi.HistoricCandle {
+	return &investapi.HistoricCandle{
+		High:   NewQuotationRounded(high),
+		Low:    NewQuotationRounded(low),
+		Close:  NewQuotationRounded(close),
+		Volume: volume,
+	}
+}
+
+func TestVWAP(t *testing.T) {
+	tests := []struct {
+		name    string
+		candles []*investapi.HistoricCandle
+		want    *investapi.Quotation
+	}{
+		{
+			name:    "weighted by volume",
+			candles: []*investapi.HistoricCandle{hlc(12, 8, 10, 100), hlc(22, 18, 20, 300)},
+			want:    &investapi.Quotation{Units: 17, Nano: 500000000},
+		},
+		{
+			name:    "typical price rounded once",
+			candles: []*investapi.HistoricCandle{hlc(1, 0, 0, 1)},
+			want:    &investapi.Quotation{Units: 0, Nano: 333333333},
+		},
+		{
+			name:    "zero volume candles skipped",
+			candles: []*investapi.HistoricCandle{hlc(100, 100, 100, 0), nil, hlc(5, 5, 5, 10)},
+			want:    &investapi.Quotation{Units: 5},
+		},
+		{
+			name:    "no volume",
+			candles: []*investapi.HistoricCandle{hlc(100, 100, 100, 0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := VWAP(tt.candles)
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("VWAP = %v, want nil", got)
+				}
+				return
+			}
+			if !QuotationEqual(got, tt.want) {
+				t.Fatalf("VWAP = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVWAPFromTrades(t *testing.T) {
+	trade := func(price float64, qty int64) *investapi.Trade {
+		return &investapi.Trade{Price: NewQuotationRounded(price), Quantity: qty}
+	}
+
+	tests := []struct {
+		name   string
+		trades []*investapi.Trade
+		want   *investapi.Quotation
+	}{
+		{
+			name:   "weighted by quantity",
+			trades: []*investapi.Trade{trade(100, 2), trade(103, 1)},
+			want:   &investapi.Quotation{Units: 101},
+		},
+		{
+			name:   "rounded half away from zero",
+			trades: []*investapi.Trade{trade(0.000000001, 1), trade(0, 1)},
+			want:   &investapi.Quotation{Nano: 1},
+		},
+		{
+			name:   "no quantity",
+			trades: []*investapi.Trade{trade(100, 0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := VWAPFromTrades(tt.trades)
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("VWAPFromTrades = %v, want nil", got)
+				}
+				return
+			}
+			if !QuotationEqual(got, tt.want) {
+				t.Fatalf("VWAPFromTrades = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
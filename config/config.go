@@ -1,8 +1,11 @@
 package config
 
 import (
+	"crypto/x509"
 	"errors"
+	"fmt"
 	"os"
+	"time"
 )
 
 // Config holds the configuration for Tinkoff client
@@ -10,12 +13,143 @@ type Config struct {
 	Token     string
 	IsDemo    bool
 	ServerURL string
+	// RESTURL is the base URL used by the REST transport
+	RESTURL string
+	// Timeout limits each REST transport request, including reading the
+	// response; zero uses DefaultRESTTimeout
+	Timeout time.Duration
+
+	// BlockingConnect makes client constructors wait until the connection
+	// is established instead of failing on the first call
+	BlockingConnect bool
+	// DialTimeout limits how long a blocking connect may take
+	DialTimeout time.Duration
+
+	// ConnectionPoolSize opens this many connections and spreads unary calls
+	// across them round-robin; zero or one uses a single connection
+	ConnectionPoolSize int
+
+	// MaxRetries is how many times read-only calls are repeated after a
+	// transient failure (Unavailable, DeadlineExceeded); zero disables retries
+	MaxRetries int
+
+	// MethodTimeouts sets a timeout per full gRPC method name, e.g.
+	// investapi.MarketDataService_GetCandles_FullMethodName. It applies to
+	// unary calls made with a context that has no deadline.
+	MethodTimeouts map[string]time.Duration
+
+	// UserAgent is sent with every request when set
+	UserAgent string
+	// Debug enables verbose gRPC logging and per-call latency logs
+	Debug bool
+
+	// AutoReconnect starts a background watcher per pooled connection that
+	// reconnects the channel after a connection loss and replays market data
+	// sessions. Other streams are not replayed, see RealClient.OnReconnect.
+	AutoReconnect bool
+	// StreamRetry controls how often and how fast streams are reopened after a
+	// connection loss, independently of MaxRetries for unary calls
+	StreamRetry StreamRetryConfig
+
+	// DryRun makes order methods log the request and return a synthetic
+	// response instead of sending it to the API. Dry runs work without a
+	// connection, so they also succeed after Close.
+	DryRun bool
+
+	// OrderBreakerThreshold opens the order circuit breaker after this many
+	// consecutive PostOrder failures; zero disables the breaker. Order
+	// rejections by the API count as failures, the caller's cancellations and
+	// expired deadlines do not.
+	OrderBreakerThreshold int
+	// OrderBreakerCooldown is how long the breaker rejects orders before
+	// letting a trial order through
+	OrderBreakerCooldown time.Duration
+
+	// StreamBufferSize is the number of messages buffered between a stream
+	// and its handler in the Stream*Func helpers; zero uses the default
+	StreamBufferSize int
+
+	// SubscriptionBatchSize limits the number of instruments sent in a single
+	// subscribe or unsubscribe request; zero uses the default
+	SubscriptionBatchSize int
+
+	// MaxStreamSubscriptions caps the subscriptions of a market data session,
+	// so subscribing past the API limit fails instead of silently dropping
+	// data; zero uses the API limit of 300
+	MaxStreamSubscriptions int
+
+	// RootCAs pins the certificates trusted for the server connection.
+	// When nil the system root store is used.
+	RootCAs *x509.CertPool
+	// RootCAsPEM is a PEM encoded alternative to RootCAs
+	RootCAsPEM []byte
+
+	// AccountsCacheTTL is how long GetAccounts serves the cached account
+	// list; zero disables the cache
+	AccountsCacheTTL time.Duration
+
+	// MaxLotsCacheTTL is how long GetMaxLots reuses a result for the same
+	// account, instrument and price; zero disables the cache
+	MaxLotsCacheTTL time.Duration
+
+	// RecordMode records unary calls to RecordDir or replays them from it
+	// without touching the network
+	RecordMode RecordMode
+	RecordDir  string
 }
 
+// RecordMode selects how unary calls are recorded or replayed
+type RecordMode int
+
+const (
+	// RecordOff sends calls to the API as usual
+	RecordOff RecordMode = iota
+	// RecordCalls sends calls to the API and saves every response to RecordDir
+	RecordCalls
+	// ReplayCalls serves responses from RecordDir instead of the API
+	ReplayCalls
+)
+
+// DefaultDialTimeout is used for blocking connects when DialTimeout is not set
+const DefaultDialTimeout = 10 * time.Second
+
+// DefaultRESTTimeout is used for REST requests when Timeout is not set
+const DefaultRESTTimeout = 30 * time.Second
+
+// StreamRetryConfig is the backoff policy for reopening streams
+type StreamRetryConfig struct {
+	// MaxAttempts limits how many times a stream is reopened in a row
+	MaxAttempts int
+	// BaseDelay is the wait before the second attempt; it doubles with every
+	// further attempt up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// DefaultStreamRetryConfig returns the stream backoff policy set by New.
+// Streams wait longer than unary retries because they are restored in the background.
+func DefaultStreamRetryConfig() StreamRetryConfig {
+	return StreamRetryConfig{
+		MaxAttempts: 10,
+		BaseDelay:   time.Second,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// DefaultMaxRetries is the number of retries of read-only calls set by New
+const DefaultMaxRetries = 3
+
+// DefaultAccountsCacheTTL is a suggested accounts cache lifetime. New leaves
+// the cache disabled, so GetAccounts always fetches unless AccountsCacheTTL is set.
+const DefaultAccountsCacheTTL = time.Minute
+
 // Default server URLs
 const (
 	ProductionServer = "invest-public-api.tinkoff.ru:443"
 	DemoServer       = "sandbox-invest-public-api.tinkoff.ru:443"
+
+	ProductionRESTServer = "https://invest-public-api.tinkoff.ru/rest"
+	DemoRESTServer       = "https://sandbox-invest-public-api.tinkoff.ru/rest"
 )
 
 // New creates a new configuration
@@ -25,15 +159,115 @@ func New(token string, isDemo bool) (*Config, error) {
 	}
 
 	serverURL := ProductionServer
+	restURL := ProductionRESTServer
 	if isDemo {
 		serverURL = DemoServer
+		restURL = DemoRESTServer
+	}
+
+	cfg := &Config{
+		Token:       token,
+		IsDemo:      isDemo,
+		ServerURL:   serverURL,
+		RESTURL:     restURL,
+		DialTimeout: DefaultDialTimeout,
+		Timeout:     DefaultRESTTimeout,
+		MaxRetries:  DefaultMaxRetries,
+		StreamRetry: DefaultStreamRetryConfig(),
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Validate checks the configuration for missing values and contradictory options
+func (c *Config) Validate() error {
+	if c.Token == "" {
+		return errors.New("token is required")
+	}
+	if c.ServerURL == "" {
+		return errors.New("server URL is required")
+	}
+
+	if c.IsDemo && (c.ServerURL == ProductionServer || c.RESTURL == ProductionRESTServer) {
+		return errors.New("demo mode cannot be used with the production server")
+	}
+	if !c.IsDemo && (c.ServerURL == DemoServer || c.RESTURL == DemoRESTServer) {
+		return errors.New("production mode cannot be used with the sandbox server, set IsDemo")
+	}
+
+	if c.DialTimeout < 0 {
+		return errors.New("dial timeout cannot be negative")
+	}
+	if c.Timeout < 0 {
+		return errors.New("timeout cannot be negative")
+	}
+	if c.StreamRetry.MaxAttempts < 0 || c.StreamRetry.BaseDelay < 0 || c.StreamRetry.MaxDelay < 0 {
+		return errors.New("stream retry settings cannot be negative")
+	}
+	if c.MaxRetries < 0 {
+		return errors.New("max retries cannot be negative")
+	}
+	for method, timeout := range c.MethodTimeouts {
+		if timeout < 0 {
+			return fmt.Errorf("timeout for %s cannot be negative", method)
+		}
+	}
+	if c.ConnectionPoolSize < 0 {
+		return errors.New("connection pool size cannot be negative")
+	}
+	if c.OrderBreakerThreshold < 0 {
+		return errors.New("order breaker threshold cannot be negative")
+	}
+	if c.OrderBreakerCooldown < 0 {
+		return errors.New("order breaker cooldown cannot be negative")
+	}
+	if c.AccountsCacheTTL < 0 {
+		return errors.New("accounts cache TTL cannot be negative")
+	}
+	if c.MaxLotsCacheTTL < 0 {
+		return errors.New("max lots cache TTL cannot be negative")
+	}
+	if c.StreamBufferSize < 0 {
+		return errors.New("stream buffer size cannot be negative")
+	}
+	if c.SubscriptionBatchSize < 0 {
+		return errors.New("subscription batch size cannot be negative")
+	}
+	if c.MaxStreamSubscriptions < 0 {
+		return errors.New("max stream subscriptions cannot be negative")
+	}
+	if c.RootCAs != nil && len(c.RootCAsPEM) > 0 {
+		return errors.New("RootCAs and RootCAsPEM cannot be used together")
+	}
+	if c.RecordMode != RecordOff && c.RecordDir == "" {
+		return errors.New("record directory is required when recording or replaying calls")
+	}
+	if c.RecordMode == ReplayCalls && c.BlockingConnect {
+		return errors.New("blocking connect cannot be used when replaying calls")
+	}
+
+	return nil
+}
+
+// CertPool returns the pinned certificate pool, or nil to use the system roots
+func (c *Config) CertPool() (*x509.CertPool, error) {
+	if c.RootCAs != nil {
+		return c.RootCAs, nil
+	}
+	if len(c.RootCAsPEM) == 0 {
+		return nil, nil
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(c.RootCAsPEM) {
+		return nil, errors.New("no valid certificates found in RootCAsPEM")
 	}
 
-	return &Config{
-		Token:     token,
-		IsDemo:    isDemo,
-		ServerURL: serverURL,
-	}, nil
+	return pool, nil
 }
 
 // NewFromEnv creates configuration from environment variables
@@ -0,0 +1,101 @@
+package config
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+)
+
+func TestNewDefaults(t *testing.T) {
+	cfg, err := New("token", false)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{name: "accounts cache disabled", got: cfg.AccountsCacheTTL, want: time.Duration(0)},
+		{name: "subscription limit uses the API limit", got: cfg.MaxStreamSubscriptions, want: 0},
+		{name: "order breaker disabled", got: cfg.OrderBreakerThreshold, want: 0},
+		{name: "read-only retries", got: cfg.MaxRetries, want: DefaultMaxRetries},
+		{name: "stream retry", got: cfg.StreamRetry, want: DefaultStreamRetryConfig()},
+		{name: "dial timeout", got: cfg.DialTimeout, want: DefaultDialTimeout},
+		{name: "REST timeout", got: cfg.Timeout, want: DefaultRESTTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Fatalf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() *Config {
+		return &Config{Token: "token", ServerURL: ProductionServer}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(c *Config) {}},
+		{name: "missing token", modify: func(c *Config) { c.Token = "" }, wantErr: true},
+		{name: "missing server", modify: func(c *Config) { c.ServerURL = "" }, wantErr: true},
+		{name: "demo against production", modify: func(c *Config) { c.IsDemo = true }, wantErr: true},
+		{name: "demo against production REST", modify: func(c *Config) {
+			c.IsDemo = true
+			c.ServerURL = DemoServer
+			c.RESTURL = ProductionRESTServer
+		}, wantErr: true},
+		{name: "production against sandbox", modify: func(c *Config) { c.ServerURL = DemoServer }, wantErr: true},
+		{name: "production against sandbox REST", modify: func(c *Config) { c.RESTURL = DemoRESTServer }, wantErr: true},
+		{name: "demo against sandbox", modify: func(c *Config) {
+			c.IsDemo = true
+			c.ServerURL = DemoServer
+			c.RESTURL = DemoRESTServer
+		}},
+		{name: "negative dial timeout", modify: func(c *Config) { c.DialTimeout = -time.Second }, wantErr: true},
+		{name: "negative timeout", modify: func(c *Config) { c.Timeout = -time.Second }, wantErr: true},
+		{name: "negative stream retry", modify: func(c *Config) { c.StreamRetry.BaseDelay = -time.Second }, wantErr: true},
+		{name: "negative max retries", modify: func(c *Config) { c.MaxRetries = -1 }, wantErr: true},
+		{name: "negative method timeout", modify: func(c *Config) {
+			c.MethodTimeouts = map[string]time.Duration{"GetCandles": -time.Second}
+		}, wantErr: true},
+		{name: "negative pool size", modify: func(c *Config) { c.ConnectionPoolSize = -1 }, wantErr: true},
+		{name: "negative breaker threshold", modify: func(c *Config) { c.OrderBreakerThreshold = -1 }, wantErr: true},
+		{name: "negative breaker cooldown", modify: func(c *Config) { c.OrderBreakerCooldown = -time.Second }, wantErr: true},
+		{name: "negative accounts cache TTL", modify: func(c *Config) { c.AccountsCacheTTL = -time.Second }, wantErr: true},
+		{name: "negative max lots cache TTL", modify: func(c *Config) { c.MaxLotsCacheTTL = -time.Second }, wantErr: true},
+		{name: "negative stream buffer", modify: func(c *Config) { c.StreamBufferSize = -1 }, wantErr: true},
+		{name: "negative batch size", modify: func(c *Config) { c.SubscriptionBatchSize = -1 }, wantErr: true},
+		{name: "negative subscription limit", modify: func(c *Config) { c.MaxStreamSubscriptions = -1 }, wantErr: true},
+		{name: "two root CA sources", modify: func(c *Config) {
+			c.RootCAs = x509.NewCertPool()
+			c.RootCAsPEM = []byte("pem")
+		}, wantErr: true},
+		{name: "recording without directory", modify: func(c *Config) { c.RecordMode = RecordCalls }, wantErr: true},
+		{name: "replay with blocking connect", modify: func(c *Config) {
+			c.RecordMode = ReplayCalls
+			c.RecordDir = "testdata"
+			c.BlockingConnect = true
+		}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(cfg)
+
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Package decimal converts Tinkoff API money types to and from
+// github.com/shopspring/decimal. It lives in its own package so the decimal
+// dependency is only pulled in by programs that import it.
+package decimal
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+// nanoExp is the exponent of the Nano field of Quotation and MoneyValue
+const nanoExp = -9
+
+// QuotationToDecimal converts a quotation to a decimal without precision loss.
+// A nil quotation converts to zero.
+func QuotationToDecimal(q *investapi.Quotation) decimal.Decimal {
+	if q == nil {
+		return decimal.Zero
+	}
+	return fromParts(q.Units, q.Nano)
+}
+
+// QuotationFromDecimal converts a decimal to a quotation. Digits beyond the
+// ninth fractional digit are rounded; an error is returned when the integer
+// part does not fit into int64.
+func QuotationFromDecimal(d decimal.Decimal) (*investapi.Quotation, error) {
+	units, nano, err := toParts(d)
+	if err != nil {
+		return nil, err
+	}
+	return &investapi.Quotation{Units: units, Nano: nano}, nil
+}
+
+// MoneyValueToDecimal converts the amount of a money value to a decimal
+// without precision loss. A nil value converts to zero.
+func MoneyValueToDecimal(m *investapi.MoneyValue) decimal.Decimal {
+	if m == nil {
+		return decimal.Zero
+	}
+	return fromParts(m.Units, m.Nano)
+}
+
+// MoneyValueFromDecimal converts a decimal amount to a money value in the given
+// currency, with the same rounding and range rules as QuotationFromDecimal
+func MoneyValueFromDecimal(d decimal.Decimal, currency string) (*investapi.MoneyValue, error) {
+	units, nano, err := toParts(d)
+	if err != nil {
+		return nil, err
+	}
+	return &investapi.MoneyValue{Currency: currency, Units: units, Nano: nano}, nil
+}
+
+func fromParts(units int64, nano int32) decimal.Decimal {
+	return decimal.New(units, 0).Add(decimal.New(int64(nano), nanoExp))
+}
+
+func toParts(d decimal.Decimal) (int64, int32, error) {
+	d = d.Round(-nanoExp)
+
+	whole := d.Truncate(0)
+	if !whole.BigInt().IsInt64() {
+		return 0, 0, fmt.Errorf("value %s is out of range", d)
+	}
+
+	// Units and nano share the sign of the value
+	nano := d.Sub(whole).Shift(-nanoExp).IntPart()
+
+	return whole.IntPart(), int32(nano), nil
+}
@@ -0,0 +1,60 @@
+package decimal
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+
+	investapi "github.com/buurzx/tinkoff-go/proto"
+)
+
+func TestQuotationRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  *investapi.Quotation
+	}{
+		{name: "fraction", value: "123.45", want: &investapi.Quotation{Units: 123, Nano: 450000000}},
+		{name: "smallest nano", value: "0.000000001", want: &investapi.Quotation{Nano: 1}},
+		{name: "negative", value: "-1.1", want: &investapi.Quotation{Units: -1, Nano: -100000000}},
+		{name: "rounded to nine digits", value: "0.0000000015", want: &investapi.Quotation{Nano: 2}},
+		{name: "large units", value: "9223372036854775807.5", want: &investapi.Quotation{Units: 9223372036854775807, Nano: 500000000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := QuotationFromDecimal(decimal.RequireFromString(tt.value))
+			if err != nil {
+				t.Fatalf("QuotationFromDecimal: %v", err)
+			}
+			if q.Units != tt.want.Units || q.Nano != tt.want.Nano {
+				t.Fatalf("QuotationFromDecimal(%s) = %d.%09d, want %d.%09d", tt.value, q.Units, q.Nano, tt.want.Units, tt.want.Nano)
+			}
+
+			back := QuotationToDecimal(q)
+			if !back.Equal(decimal.RequireFromString(tt.value).Round(9)) {
+				t.Fatalf("QuotationToDecimal = %s, want %s", back, tt.value)
+			}
+
+			m, err := MoneyValueFromDecimal(back, "rub")
+			if err != nil {
+				t.Fatalf("MoneyValueFromDecimal: %v", err)
+			}
+			if m.Currency != "rub" || !MoneyValueToDecimal(m).Equal(back) {
+				t.Fatalf("money round trip = %s %s, want %s rub", MoneyValueToDecimal(m), m.Currency, back)
+			}
+		})
+	}
+}
+
+func TestFromDecimalOutOfRange(t *testing.T) {
+	if _, err := QuotationFromDecimal(decimal.RequireFromString("9223372036854775808")); err == nil {
+		t.Fatal("QuotationFromDecimal accepted a value beyond int64")
+	}
+}
+
+func TestToDecimalNil(t *testing.T) {
+	if !QuotationToDecimal(nil).IsZero() || !MoneyValueToDecimal(nil).IsZero() {
+		t.Fatal("nil values must convert to zero")
+	}
+}
@@ -6,8 +6,6 @@ import (
 	"os"
 	"time"
 
-	"github.com/google/uuid"
-
 	"github.com/buurzx/tinkoff-go/client"
 	investapi "github.com/buurzx/tinkoff-go/proto"
 )
@@ -57,29 +55,29 @@ func main() {
 	demonstrateAdvancedOrders(ctx, realClient, selectedAccount.Id, sberInstrument.Figi)
 }
 
-func demonstrateAdvancedOrders(ctx context.Context, client *client.RealClient, accountID, instrumentID string) {
+func demonstrateAdvancedOrders(ctx context.Context, realClient *client.RealClient, accountID, instrumentID string) {
 	log.Println("\n📊 Advanced Order Features Demo")
 	log.Println("===============================")
 
 	// 1. Get maximum available lots
 	log.Println("\n1️⃣ Getting maximum available lots...")
 	price := 250.0 // Example price for SBER
-	maxLots, err := client.GetMaxLots(ctx, accountID, instrumentID, &price)
+	maxLotsResp, err := realClient.GetMaxLots(ctx, accountID, instrumentID, &price)
 	if err != nil {
 		log.Printf("❌ Failed to get max lots: %v", err)
 	} else {
+		maxLots := client.MaxLotsFromResponse(maxLotsResp)
 		log.Printf("   💰 Buy limits:")
-		log.Printf("     Available money: %.2f %s",
-			quotationToFloat(maxLots.BuyLimits.BuyMoneyAmount), maxLots.Currency)
-		log.Printf("     Max lots: %d", maxLots.BuyLimits.BuyMaxLots)
-		log.Printf("     Max market lots: %d", maxLots.BuyLimits.BuyMaxMarketLots)
+		log.Printf("     Available money: %.2f %s", maxLots.BuyMoney, maxLots.Currency)
+		log.Printf("     Max lots: %d", maxLots.BuyLots)
+		log.Printf("     Max market lots: %d", maxLots.BuyMarketLots)
 		log.Printf("   📈 Sell limits:")
-		log.Printf("     Max lots: %d", maxLots.SellLimits.SellMaxLots)
+		log.Printf("     Max lots: %d", maxLots.SellLots)
 	}
 
 	// 2. Get order price estimation
 	log.Println("\n2️⃣ Getting order price estimation...")
-	orderPrice, err := client.GetOrderPrice(ctx, accountID, instrumentID, price, investapi.OrderDirection_ORDER_DIRECTION_BUY, 1)
+	orderPrice, err := realClient.GetOrderPrice(ctx, accountID, instrumentID, price, investapi.OrderDirection_ORDER_DIRECTION_BUY, 1)
 	if err != nil {
 		log.Printf("❌ Failed to get order price: %v", err)
 	} else {
@@ -105,7 +103,7 @@ func demonstrateAdvancedOrders(ctx context.Context, client *client.RealClient, a
 		Direction:    investapi.OrderDirection_ORDER_DIRECTION_BUY,
 		OrderType:    investapi.OrderType_ORDER_TYPE_MARKET,
 		AccountId:    accountID,
-		OrderId:      uuid.New().String(),
+		OrderId:      client.NewIdempotencyKey(),
 	}
 	log.Printf("   Order: BUY 1 lot of %s at MARKET price", instrumentID)
 	log.Printf("   Request: %+v", marketOrderReq)
@@ -119,7 +117,7 @@ func demonstrateAdvancedOrders(ctx context.Context, client *client.RealClient, a
 		Direction:    investapi.OrderDirection_ORDER_DIRECTION_BUY,
 		OrderType:    investapi.OrderType_ORDER_TYPE_LIMIT,
 		AccountId:    accountID,
-		OrderId:      uuid.New().String(),
+		OrderId:      client.NewIdempotencyKey(),
 		TimeInForce:  investapi.TimeInForceType_TIME_IN_FORCE_DAY,
 	}
 	log.Printf("   Order: BUY 2 lots of %s at 245.00 LIMIT (Day order)", instrumentID)
@@ -134,7 +132,7 @@ func demonstrateAdvancedOrders(ctx context.Context, client *client.RealClient, a
 		Direction:    investapi.OrderDirection_ORDER_DIRECTION_BUY,
 		OrderType:    investapi.OrderType_ORDER_TYPE_LIMIT,
 		AccountId:    accountID,
-		OrderId:      uuid.New().String(),
+		OrderId:      client.NewIdempotencyKey(),
 		TimeInForce:  investapi.TimeInForceType_TIME_IN_FORCE_FILL_OR_KILL,
 	}
 	log.Printf("   Order: BUY 1 lot of %s at 248.00 LIMIT (Fill or Kill)", instrumentID)
@@ -154,7 +152,7 @@ func demonstrateAdvancedOrders(ctx context.Context, client *client.RealClient, a
 		StopOrderType:     investapi.StopOrderType_STOP_ORDER_TYPE_STOP_LOSS,
 		ExpirationType:    investapi.StopOrderExpirationType_STOP_ORDER_EXPIRATION_TYPE_GOOD_TILL_CANCEL,
 		AccountId:         accountID,
-		OrderId:           uuid.New().String(),
+		OrderId:           client.NewIdempotencyKey(),
 		ExchangeOrderType: investapi.ExchangeOrderType_EXCHANGE_ORDER_TYPE_MARKET,
 	}
 	log.Printf("   Order: SELL 1 lot of %s when price drops to 240.00 (Stop Loss)", instrumentID)
@@ -170,7 +168,7 @@ func demonstrateAdvancedOrders(ctx context.Context, client *client.RealClient, a
 		StopOrderType:     investapi.StopOrderType_STOP_ORDER_TYPE_TAKE_PROFIT,
 		ExpirationType:    investapi.StopOrderExpirationType_STOP_ORDER_EXPIRATION_TYPE_GOOD_TILL_CANCEL,
 		AccountId:         accountID,
-		OrderId:           uuid.New().String(),
+		OrderId:           client.NewIdempotencyKey(),
 		ExchangeOrderType: investapi.ExchangeOrderType_EXCHANGE_ORDER_TYPE_MARKET,
 		TakeProfitType:    investapi.TakeProfitType_TAKE_PROFIT_TYPE_REGULAR,
 	}
@@ -188,7 +186,7 @@ func demonstrateAdvancedOrders(ctx context.Context, client *client.RealClient, a
 		StopOrderType:     investapi.StopOrderType_STOP_ORDER_TYPE_STOP_LIMIT,
 		ExpirationType:    investapi.StopOrderExpirationType_STOP_ORDER_EXPIRATION_TYPE_GOOD_TILL_CANCEL,
 		AccountId:         accountID,
-		OrderId:           uuid.New().String(),
+		OrderId:           client.NewIdempotencyKey(),
 		ExchangeOrderType: investapi.ExchangeOrderType_EXCHANGE_ORDER_TYPE_LIMIT,
 	}
 	log.Printf("   Order: SELL 1 lot of %s at 238.00 LIMIT when price drops to 240.00 (Stop Limit)", instrumentID)
@@ -204,7 +202,7 @@ func demonstrateAdvancedOrders(ctx context.Context, client *client.RealClient, a
 		StopOrderType:     investapi.StopOrderType_STOP_ORDER_TYPE_TAKE_PROFIT,
 		ExpirationType:    investapi.StopOrderExpirationType_STOP_ORDER_EXPIRATION_TYPE_GOOD_TILL_CANCEL,
 		AccountId:         accountID,
-		OrderId:           uuid.New().String(),
+		OrderId:           client.NewIdempotencyKey(),
 		ExchangeOrderType: investapi.ExchangeOrderType_EXCHANGE_ORDER_TYPE_MARKET,
 		TakeProfitType:    investapi.TakeProfitType_TAKE_PROFIT_TYPE_TRAILING,
 		TrailingData: &investapi.PostStopOrderRequest_TrailingData{
@@ -219,7 +217,7 @@ func demonstrateAdvancedOrders(ctx context.Context, client *client.RealClient, a
 
 	// 5. Get existing stop orders
 	log.Println("\n5️⃣ Getting existing stop orders...")
-	stopOrders, err := client.GetStopOrders(ctx, accountID, investapi.StopOrderStatusOption_STOP_ORDER_STATUS_ACTIVE)
+	stopOrders, err := realClient.GetStopOrders(ctx, accountID, investapi.StopOrderStatusOption_STOP_ORDER_STATUS_ACTIVE)
 	if err != nil {
 		log.Printf("❌ Failed to get stop orders: %v", err)
 	} else {
@@ -256,13 +254,7 @@ func demonstrateAdvancedOrders(ctx context.Context, client *client.RealClient, a
 
 // Helper functions
 func floatToQuotation(value float64) *investapi.Quotation {
-	units := int64(value)
-	nano := int32((value - float64(units)) * 1e9)
-
-	return &investapi.Quotation{
-		Units: units,
-		Nano:  nano,
-	}
+	return client.NewQuotationRounded(value)
 }
 
 func moneyValueToFloat(m *investapi.MoneyValue) float64 {
@@ -271,10 +263,3 @@ func moneyValueToFloat(m *investapi.MoneyValue) float64 {
 	}
 	return float64(m.Units) + float64(m.Nano)/1e9
 }
-
-func quotationToFloat(q *investapi.Quotation) float64 {
-	if q == nil {
-		return 0.0
-	}
-	return float64(q.Units) + float64(q.Nano)/1e9
-}
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"io"
 	"log"
 	"os"
 	"os/signal"
@@ -73,7 +72,7 @@ func main() {
 		marketDataStream,
 		instruments,
 		investapi.SubscriptionInterval_SUBSCRIPTION_INTERVAL_ONE_MINUTE,
-		false,
+		client.CandlesOnEveryTrade,
 	)
 	if err != nil {
 		log.Printf("Failed to subscribe to candles: %v", err)
@@ -151,6 +150,8 @@ func main() {
 	case <-time.After(3 * time.Second):
 		log.Println("⚠️ Shutdown timeout - forcing exit")
 	}
+
+	streamLog.Summary()
 }
 
 // handleMarketDataStream processes real-time market data
@@ -184,8 +185,8 @@ func handleMarketDataStream(ctx context.Context, stream investapi.MarketDataStre
 			log.Println("📡 Market data stream handler cancelled")
 			return
 		case err := <-errChan:
-			if err == io.EOF {
-				log.Println("📡 Market data stream closed by server")
+			if client.IsStreamClosed(err) {
+				log.Println("📡 Market data stream closed")
 				return
 			}
 			log.Printf("❌ Market data stream error: %v", err)
@@ -196,12 +197,16 @@ func handleMarketDataStream(ctx context.Context, stream investapi.MarketDataStre
 	}
 }
 
+// streamLog writes at most one line per instrument and message type every
+// few seconds, so busy instruments do not flood the output
+var streamLog = client.NewSampledLogger(5 * time.Second)
+
 // processMarketDataResponse processes individual market data messages
 func processMarketDataResponse(resp *investapi.MarketDataResponse) {
 	switch payload := resp.Payload.(type) {
 	case *investapi.MarketDataResponse_Candle:
 		candle := payload.Candle
-		log.Printf("📊 CANDLE %s [%s]: O=%.4f H=%.4f L=%.4f C=%.4f V=%d",
+		streamLog.Printf("candle", candle.Figi, "📊 CANDLE %s [%s]: O=%.4f H=%.4f L=%.4f C=%.4f V=%d",
 			getInstrumentName(candle.Figi),
 			candle.Time.AsTime().Format("15:04:05"),
 			quotationToFloat(candle.Open),
@@ -225,7 +230,7 @@ func processMarketDataResponse(resp *investapi.MarketDataResponse) {
 			size = "large"
 		}
 
-		log.Printf("💰 TRADE %s [%s]: %s %.4f x%d (%s)",
+		streamLog.Printf("trade", trade.Figi, "💰 TRADE %s [%s]: %s %.4f x%d (%s)",
 			getInstrumentName(trade.Figi),
 			trade.Time.AsTime().Format("15:04:05"),
 			direction,
@@ -251,7 +256,7 @@ func processMarketDataResponse(resp *investapi.MarketDataResponse) {
 			spreadPercent = (spread / bestBid) * 100
 		}
 
-		log.Printf("📖 ORDER BOOK %s: Bid=%.4f Ask=%.4f Spread=%.4f (%.3f%%) Depth=%d/%d",
+		streamLog.Printf("orderbook", orderBook.Figi, "📖 ORDER BOOK %s: Bid=%.4f Ask=%.4f Spread=%.4f (%.3f%%) Depth=%d/%d",
 			getInstrumentName(orderBook.Figi),
 			bestBid,
 			bestAsk,
@@ -262,7 +267,7 @@ func processMarketDataResponse(resp *investapi.MarketDataResponse) {
 
 	case *investapi.MarketDataResponse_LastPrice:
 		lastPrice := payload.LastPrice
-		log.Printf("💲 LAST PRICE %s: %.4f [%s]",
+		streamLog.Printf("last price", lastPrice.Figi, "💲 LAST PRICE %s: %.4f [%s]",
 			getInstrumentName(lastPrice.Figi),
 			quotationToFloat(lastPrice.Price),
 			lastPrice.Time.AsTime().Format("15:04:05"))
@@ -276,17 +281,16 @@ func processMarketDataResponse(resp *investapi.MarketDataResponse) {
 	case *investapi.MarketDataResponse_Ping:
 		log.Printf("🏓 Market data ping received")
 
-	case *investapi.MarketDataResponse_SubscribeCandlesResponse:
-		log.Printf("✅ Candles subscription confirmed: %s", payload.SubscribeCandlesResponse.TrackingId)
-
-	case *investapi.MarketDataResponse_SubscribeOrderBookResponse:
-		log.Printf("✅ Order book subscription confirmed: %s", payload.SubscribeOrderBookResponse.TrackingId)
-
-	case *investapi.MarketDataResponse_SubscribeTradesResponse:
-		log.Printf("✅ Trades subscription confirmed: %s", payload.SubscribeTradesResponse.TrackingId)
-
-	case *investapi.MarketDataResponse_SubscribeLastPriceResponse:
-		log.Printf("✅ Last price subscription confirmed: %s", payload.SubscribeLastPriceResponse.TrackingId)
+	case *investapi.MarketDataResponse_SubscribeCandlesResponse,
+		*investapi.MarketDataResponse_SubscribeOrderBookResponse,
+		*investapi.MarketDataResponse_SubscribeTradesResponse,
+		*investapi.MarketDataResponse_SubscribeLastPriceResponse:
+		result, _ := client.ParseSubscriptionResult(resp)
+		if err := result.Err(); err != nil {
+			log.Printf("❌ %v", err)
+		} else {
+			log.Printf("✅ %s subscription confirmed: %s", result.Type, result.TrackingID)
+		}
 
 	default:
 		log.Printf("🤷 Unknown market data response type: %T", payload)
@@ -328,8 +332,8 @@ func handleOrderStream(
 			log.Println("📡 Order stream handler cancelled")
 			return
 		case err := <-errChan:
-			if err == io.EOF {
-				log.Println("📡 Order stream closed by server")
+			if client.IsStreamClosed(err) {
+				log.Println("📡 Order stream closed")
 				return
 			}
 			log.Printf("❌ Order stream error: %v", err)
@@ -11,6 +11,9 @@ import (
 	investapi "github.com/buurzx/tinkoff-go/proto"
 )
 
+// maxResults keeps the output readable for broad queries
+const maxResults = 20
+
 func main() {
 	if len(os.Args) < 2 {
 		fmt.Println("Usage: go run main.go <search_query> [instrument_type]")
@@ -79,7 +82,11 @@ func main() {
 	}
 	fmt.Println(strings.Repeat("-", 80))
 
-	instruments, err := realClient.FindInstrument(ctx, query, instrumentType, true) // Only tradeable instruments
+	instruments, err := realClient.FindInstrumentWithOptions(ctx, query, client.FindInstrumentOptions{
+		InstrumentType:        instrumentType,
+		APITradeAvailableOnly: true, // Only tradeable instruments
+		Limit:                 maxResults,
+	})
 	if err != nil {
 		log.Fatalf("Failed to search instruments: %v", err)
 	}
@@ -94,6 +101,9 @@ func main() {
 	}
 
 	fmt.Printf("Found %d instrument(s):\n\n", len(instruments))
+	if len(instruments) == maxResults {
+		fmt.Printf("Showing the first %d matches, refine the query to see others.\n\n", maxResults)
+	}
 
 	for i, inst := range instruments {
 		fmt.Printf("%d. %s (%s)\n", i+1, inst.Name, inst.InstrumentType)
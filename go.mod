@@ -6,6 +6,7 @@ toolchain go1.23.5
 
 require (
 	github.com/google/uuid v1.6.0
+	github.com/shopspring/decimal v1.4.0
 	google.golang.org/genproto/googleapis/api v0.0.0-20250324211829-b45e905df463
 	google.golang.org/grpc v1.73.0
 	google.golang.org/protobuf v1.36.6
@@ -2,6 +2,8 @@ package internal
 
 import (
 	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // TimeZone constants
@@ -29,6 +31,29 @@ func MoscowToUTC(t time.Time) time.Time {
 	return t.UTC()
 }
 
+// MoscowNow returns the current time in Moscow timezone
+func MoscowNow() time.Time {
+	return time.Now().In(MoscowTZ)
+}
+
+// ProtoToMoscow converts proto timestamp to Moscow time.
+// A nil timestamp yields the zero time.
+func ProtoToMoscow(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime().In(MoscowTZ)
+}
+
+// FormatMoscow formats proto timestamp in Moscow time using layout.
+// A nil timestamp yields an empty string.
+func FormatMoscow(ts *timestamppb.Timestamp, layout string) string {
+	if ts == nil {
+		return ""
+	}
+	return ProtoToMoscow(ts).Format(layout)
+}
+
 // FormatPrice formats price with appropriate decimal places
 func FormatPrice(price float64, decimals int) string {
 	format := "%." + string(rune('0'+decimals)) + "f"
@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestProtoToMoscow(t *testing.T) {
+	tests := []struct {
+		name string
+		ts   *timestamppb.Timestamp
+		want string
+	}{
+		{name: "morning UTC", ts: timestamppb.New(time.Date(2024, 3, 4, 7, 0, 0, 0, time.UTC)), want: "2024-03-04 10:00"},
+		{name: "crosses midnight", ts: timestamppb.New(time.Date(2024, 3, 4, 22, 30, 0, 0, time.UTC)), want: "2024-03-05 01:30"},
+		{name: "nil", ts: nil, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatMoscow(tt.ts, "2006-01-02 15:04"); got != tt.want {
+				t.Fatalf("FormatMoscow = %q, want %q", got, tt.want)
+			}
+
+			got := ProtoToMoscow(tt.ts)
+			if tt.ts == nil {
+				if !got.IsZero() {
+					t.Fatalf("ProtoToMoscow(nil) = %s, want the zero time", got)
+				}
+				return
+			}
+			if got.Location() != MoscowTZ {
+				t.Fatalf("location = %s, want Europe/Moscow", got.Location())
+			}
+			if _, offset := got.Zone(); offset != 3*60*60 {
+				t.Fatalf("offset = %d, want UTC+3", offset)
+			}
+			if !got.Equal(tt.ts.AsTime()) {
+				t.Fatalf("ProtoToMoscow moved the instant: %s != %s", got, tt.ts.AsTime())
+			}
+		})
+	}
+}